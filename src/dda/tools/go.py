@@ -11,9 +11,33 @@ from dda.tools.base import ExecutionContext, Tool
 from dda.utils.fs import Path
 
 if TYPE_CHECKING:
-    from collections.abc import Generator, Iterable
+    import subprocess
+    from collections.abc import Callable, Generator, Iterable, Iterator, Mapping
     from os import PathLike
-    from typing import Any
+    from subprocess import CompletedProcess
+    from threading import Event, Lock
+    from typing import IO, Any, NoReturn
+
+    from dda.cli.application import Application
+
+    from dda.utils.go.bench import BenchmarkResult
+    from dda.utils.go.build import BuildResult, MatrixReport, ReproducibilityReport, RetryPolicy, Target
+    from dda.utils.go.cache import CacheStats
+    from dda.utils.go.constraints import BuildContext
+    from dda.utils.go.diagnostics import Diagnostic
+    from dda.utils.go.download import DownloadReport
+    from dda.utils.go.formatting import FormatResult
+    from dda.utils.go.generate import Generator
+    from dda.utils.go.graph import ModuleGraph
+    from dda.utils.go.install import ToolResult
+    from dda.utils.go.moddiff import ModuleDiffReport
+    from dda.utils.go.observer import Observer
+    from dda.utils.go.packages import PackageImports
+    from dda.utils.go.plan import CommandSpec, Plan
+    from dda.utils.go.testing import TestRun, TestStream
+    from dda.utils.go.toolchain import Toolchain
+    from dda.utils.go.updates import ModuleUpdate
+    from dda.utils.go.version import Version
 
 
 class Go(Tool):
@@ -25,6 +49,10 @@ class Go(Tool):
     - `go.mod`
     - `go.work`
 
+    Methods that build, test or list packages given as paths, such as `../other/cmd/...`, operate in the
+    [workspace][dda.utils.go.modules.detect_workspace] containing them by setting the `GOWORK` environment
+    variable, even if the working directory is outside of it.
+
     Example usage:
 
     ```python
@@ -32,6 +60,11 @@ class Go(Tool):
     ```
     """
 
+    def __init__(self, app: Application) -> None:
+        super().__init__(app)
+
+        self.__observers: tuple[Observer, ...] = ()
+
     @contextmanager
     def execution_context(self, command: list[str]) -> Generator[ExecutionContext, None, None]:
         yield ExecutionContext(
@@ -39,11 +72,80 @@ class Go(Tool):
             env_vars={"GOTOOLCHAIN": f"go{self.version}"} if self.version else {},
         )
 
+    @contextmanager
+    def observe(self, observer: Observer) -> Generator[Observer, None, None]:
+        """
+        Notify an [`Observer`][dda.utils.go.observer.Observer] of every run of the `go` command for the duration
+        of the context, with the full command, the environment variables that differ from those of the current
+        process, the working directory, the duration and the exit code. Nothing is recorded while no observer is
+        registered. Queries of the version and environment of the [toolchain][dda.tools.go.Go.toolchain], which
+        are cached, are not observed.
+
+        Example usage:
+
+        ```python
+        from dda.utils.go.observer import JSONObserver
+
+        with open("go-commands.jsonl", "a", encoding="utf-8") as f, app.tools.go.observe(JSONObserver(f)):
+            app.tools.go.build(".", output="bin/agent")
+        ```
+
+        Args:
+            observer: The observer to register.
+
+        Yields:
+            The observer.
+        """
+        self.__observers = (*self.__observers, observer)
+        try:
+            yield observer
+        finally:
+            observers = list(self.__observers)
+            observers.remove(observer)
+            self.__observers = tuple(observers)
+
+    def run(self, command: list[str], **kwargs: Any) -> int:
+        return self._observed(super().run, command, kwargs)
+
+    def capture(self, command: list[str], **kwargs: Any) -> str:
+        return self._observed(super().capture, command, kwargs)
+
+    def wait(self, command: list[str], **kwargs: Any) -> int:
+        return self._observed(super().wait, command, kwargs)
+
+    def exit_with(self, command: list[str], **kwargs: Any) -> NoReturn:
+        self._observed(super().exit_with, command, kwargs)
+
+    def attach(self, command: list[str], **kwargs: Any) -> CompletedProcess:
+        return self._observed(super().attach, command, kwargs)
+
+    def redirect(self, command: list[str], **kwargs: Any) -> CompletedProcess:
+        return self._observed(super().redirect, command, kwargs)
+
     @cached_property
     def path(self) -> str:
-        import shutil
+        """
+        The path to the `go` binary, as determined by [`find_binary`][dda.utils.go.toolchain.find_binary].
+
+        /// note
+        When the `GOROOT` environment variable is set, the binary of that installation is used even if a different
+        `go` binary comes first on `PATH`, which used to be the only location searched. Unset `GOROOT` or point it
+        to the installation on `PATH` to keep using the same binary.
+        ///
+        """
+        from dda.utils.go.toolchain import find_binary
+
+        return find_binary() or "go"
+
+    @cached_property
+    def toolchain(self) -> Toolchain:
+        """
+        Information about the `go` binary, such as its version and environment, which is cached for the lifetime
+        of the process.
+        """
+        from dda.utils.go.toolchain import Toolchain
 
-        return shutil.which("go") or "go"
+        return Toolchain(self.path)
 
     @cached_property
     def version(self) -> str | None:
@@ -65,9 +167,40 @@ class Go(Tool):
 
         return None
 
+    def ensure_required_version(self, root: str | PathLike | None = None) -> None:
+        """
+        Abort if the installed toolchain is older than the version required by the `go.mod` file of a module, as
+        determined by [`required_version`][dda.utils.go.modules.required_version].
+
+        Args:
+            root: The directory of the module, defaulting to the root of the module containing the current
+                working directory.
+        """
+        from dda.utils.go.modules import NoModuleError, required_version
+
+        try:
+            required = required_version(root)
+        except (NoModuleError, ValueError) as e:
+            self.app.abort(str(e))
+
+        installed = self.toolchain.version()
+        if installed < required:
+            self.app.abort(f"go.mod requires {required} but {installed} is installed")
+
     def _build(self, args: list[str], **kwargs: Any) -> str:
         """Run a raw go build command."""
-        return self.capture(["build", *args], check=True, **kwargs)
+        from dda.utils.go.build import BuildError
+        from dda.utils.process import EnvVars
+
+        # Extra environment variables are added to those of the current process rather than replacing them
+        if (env := kwargs.pop("env", None)) is not None:
+            kwargs["env"] = EnvVars(env)
+
+        process = self.attach(["build", *args], check=False, capture_output=True, encoding="utf-8", **kwargs)
+        if process.returncode:
+            raise BuildError(process.returncode, process.stderr, directory=kwargs.get("cwd"))
+
+        return process.stdout
 
     def build(
         self,
@@ -76,10 +209,20 @@ class Go(Tool):
         build_tags: set[str] | None = None,
         gcflags: Iterable[str] | None = None,
         ldflags: Iterable[str] | None = None,
+        ldflags_vars: dict[str, str] | None = None,
         env_vars: dict[str, str] | None = None,
         force_rebuild: bool = False,
+        toolchain: str | None = None,
+        mod: str = "readonly",
+        overlay: Mapping[str | PathLike, str | bytes | PathLike | None] | None = None,
+        race: bool | None = None,
+        trimpath: bool = True,
+        extra_args: Iterable[str] | None = None,
+        files: Iterable[str | PathLike] | None = None,
+        pgo: str = "",
+        dry_run: bool = False,
         **kwargs: Any,
-    ) -> str:
+    ) -> str | Plan:
         """
         Run an instrumented Go build command.
 
@@ -90,42 +233,2574 @@ class Go(Tool):
             build_tags: Build tags to include when compiling. Empty by default.
             gcflags: The gcflags (go compiler flags) to use, passed as a list of strings. Empty by default.
             ldflags: The ldflags (go linker flags) to use, passed as a list of strings. Empty by default.
+            ldflags_vars: String variables to set at link time with the `-X` linker flag, such as
+                `{"main.Version": "1.0.0"}`. These are merged with `ldflags`. Empty by default.
             env_vars: Extra environment variables to set for the build command. Empty by default.
             force_rebuild: Whether to force a rebuild of the package and bypass the build cache.
+            toolchain: The [toolchain](https://go.dev/doc/toolchain) to use, such as `go1.22.3`, which is downloaded
+                if necessary. The value `local` forces the use of the installed toolchain and prevents downloads.
+                Defaults to the version detected from files in the current directory.
+            mod: The [module download mode](https://go.dev/ref/mod#build-commands), one of `readonly`, `vendor`,
+                `mod` or `auto`, passed to the `-mod` flag. The value `auto` lets the `go` command choose between
+                `vendor` and `readonly` based on the presence of a `vendor` directory.
+            overlay: Files to replace while building without modifying them on disk, using the `-overlay` flag.
+                Keys are the paths to the original files, which need not exist. Values are either the contents
+                of the replacement, as a string or bytes, the path to a replacement file, or `None` to treat
+                the file as deleted. Relative paths are resolved against the working directory of the command.
+                Empty by default.
+            race: Whether to enable the race detector, using the `-race` flag, which requires cgo. By default,
+                it is enabled only if the race detector supports the target.
+            trimpath: Whether to remove file system paths from the binary, using the `-trimpath` flag, so that
+                its contents do not depend on where the sources are located. Debuggers may be unable to find the
+                sources of binaries built this way.
+            extra_args: Flags to pass verbatim after the managed flags, for those that have no dedicated
+                parameter. Conflicts with the managed flags are the responsibility of the caller, but
+                [arguments][dda.utils.go.build.validate_extra_args] that are not flags are rejected.
+            files: Go source files to build as a single package instead of `packages`, such as
+                `["main.go", "prod.go"]`. The files must all be in the same directory and are
+                [compiled][dda.utils.go.build.resolve_build_files] regardless of their build constraints.
+            pgo: The [profile](https://go.dev/doc/pgo) to use for profile-guided optimization, passed to the `-pgo`
+                flag, which requires Go 1.21 or later. This is either `off`, `auto` or a path relative to the
+                working directory of the command. By default, the flag is not set and the `go` command uses the
+                `default.pgo` file in the directory of the main package if there is one.
+            dry_run: Whether to return the [plan][dda.utils.go.plan.Plan] of the command rather than running it.
+                Warnings about conflicting flags are then part of the plan rather than displayed. This cannot be
+                combined with `overlay`.
             **kwargs: Additional arguments to pass to the go build command.
+
+        Returns:
+            The standard output of the command, or its plan if `dry_run` is enabled.
+
+        Raises:
+            BuildError: If the build fails, with the compiler errors available as
+                [diagnostics][dda.utils.go.build.BuildError.diagnostics].
+            RaceUnsupportedError: If `race` is enabled but the target does not support the race detector.
         """
-        from platform import machine as architecture
+        if dry_run and overlay:
+            self.app.abort(_DRY_RUN_OVERLAY_ERROR)
 
-        from dda.config.constants import Verbosity
-        from dda.utils.platform import PLATFORM_ID
+        extra_args = self._extra_args(extra_args)
+        if files is not None:
+            from dda.utils.go.build import resolve_build_files
 
-        command_parts = [
-            "-trimpath",  # Always use trimmed paths instead of absolute file system paths # NOTE: This might not work with delve
-            "-mod=readonly",  # Always use readonly mode, we never use anything else
-            f"-o={output}",
-        ]
+            if packages:
+                self.app.abort("Packages and files cannot be built together")
 
-        if force_rebuild:
-            command_parts.append("-a")
+            try:
+                packages = tuple(resolve_build_files(files, kwargs.get("cwd")))
+            except ValueError as e:
+                self.app.abort(str(e))
+
+        if race is None:
+            race = _race_enabled(race, self._environment_target(env_vars))
+        if race:
+            env_vars = self._race_env_vars(env_vars)
+
+        command_parts = self._build_flags(
+            output=output,
+            build_tags=build_tags,
+            gcflags=gcflags,
+            ldflags=ldflags,
+            ldflags_vars=ldflags_vars,
+            force_rebuild=force_rebuild,
+            race=race,
+            mod=mod,
+            trimpath=trimpath,
+            pgo=self._pgo_profile(pgo, toolchain, kwargs.get("cwd")),
+        )
+
+        if toolchain is not None:
+            env_vars = {**(env_vars or {}), "GOTOOLCHAIN": self._validate_toolchain(toolchain)}
+
+        with self._overlay_file(overlay, kwargs.get("cwd")) as overlay_file:
+            if overlay_file is not None:
+                command_parts.append(f"-overlay={overlay_file}")
+            command_parts.extend(extra_args)
+            command_parts.extend(str(package) for package in packages)
+
+            # TODO: Debug log the command parts ?
+            env_vars = self._workspace_env_vars(env_vars, packages, kwargs.get("cwd"))
+            warnings = self._goflags_warnings(command_parts, env_vars)
+            if dry_run:
+                from dda.utils.go.plan import Plan
+
+                command = self._command_spec(["build", *command_parts], env_vars, kwargs.get("cwd"))
+                return Plan(commands=(command,), warnings=tuple(warnings))
+
+            for warning in warnings:
+                self.app.display_warning(warning)
+
+            return self._build(command_parts, env=env_vars, **kwargs)
+
+    def verify_reproducible(self, *packages: str | PathLike, **kwargs: Any) -> ReproducibilityReport:
+        """
+        Build the given packages twice into separate directories and compare the binaries. Known sources of
+        nondeterminism are controlled for: both builds use the `-trimpath` flag, bypass the build cache, ignore
+        the `GOFLAGS` environment variable and are pinned to the same toolchain.
+
+        Example usage:
+
+        ```python
+        report = app.tools.go.verify_reproducible("./cmd/agent", ldflags_vars=version_stamp(commit, date))
+        if not report.reproducible:
+            app.abort(f"The binaries differ at: {report.differences}")
+        ```
+
+        Args:
+            packages: The go packages to build, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to building the current directory.
+            **kwargs: Additional arguments to pass to [`build`][dda.tools.go.Go.build]. The `toolchain` defaults
+                to the version detected from files in the current directory, or to the installed toolchain.
+
+        Returns:
+            The digests of the binaries and, if they differ, where.
+
+        Raises:
+            BuildError: If either build fails.
+        """
+        from dda.utils.fs import temp_directory
+        from dda.utils.go.build import ReproducibilityReport, diff_binaries
+
+        for name in ("output", "trimpath", "force_rebuild"):
+            if name in kwargs:
+                self.app.abort(f"The `{name}` argument cannot be set when verifying reproducibility")
+
+        kwargs["env_vars"] = {**(kwargs.get("env_vars") or {}), "GOFLAGS": ""}
+        if kwargs.get("toolchain") is None:
+            kwargs["toolchain"] = f"go{self.version}" if self.version else "local"
+
+        with temp_directory() as temp_dir:
+            outputs = (temp_dir / "first" / "binary", temp_dir / "second" / "binary")
+            for output in outputs:
+                output.parent.ensure_dir()
+                self.build(*packages, output=output, trimpath=True, force_rebuild=True, **kwargs)
+
+            digests = (outputs[0].hexdigest(), outputs[1].hexdigest())
+            differences = () if digests[0] == digests[1] else tuple(diff_binaries(*outputs))
+
+        return ReproducibilityReport(digests=digests, differences=differences)
+
+    def build_targets(
+        self,
+        *packages: str | PathLike,
+        targets: Iterable[Target],
+        output: str,
+        build_tags: set[str] | None = None,
+        gcflags: Iterable[str] | None = None,
+        ldflags: Iterable[str] | None = None,
+        ldflags_vars: dict[str, str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        force_rebuild: bool = False,
+        cgo: bool | None = None,
+        incremental: bool = False,
+        toolchain: str | None = None,
+        output_stream: IO[str] | None = None,
+        prefix_output: bool = False,
+        timeout: float | None = None,
+        cancel: Event | None = None,
+        trace: bool = False,
+        trace_stream: IO[str] | None = None,
+        retry: RetryPolicy | None = None,
+        mod: str = "readonly",
+        overlay: Mapping[str | PathLike, str | bytes | PathLike | None] | None = None,
+        parallelism: int = 1,
+        lock: bool = False,
+        try_lock: bool = False,
+        lock_dir: str | PathLike | None = None,
+        race: bool | None = False,
+        trimpath: bool = True,
+        extra_args: Iterable[str] | None = None,
+        pgo: str = "",
+        size_report: bool = False,
+        dry_run: bool = False,
+    ) -> list[BuildResult] | Plan:
+        """
+        Build the given packages for multiple targets, producing one binary per target. A failure for one target
+        does not prevent the others from being built.
+
+        Example usage:
+
+        ```python
+        results = app.tools.go.build_targets(
+            ".",
+            targets=[Target("linux", "amd64"), Target("darwin", "arm64")],
+            output="dist/{goos}_{goarch}/agent",
+        )
+        ```
+
+        Args:
+            packages: The go packages to build, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to building the current directory.
+            targets: The targets for which to build.
+            output: The path to the output binary, which may contain the `{goos}` and `{goarch}` placeholders.
+            build_tags: Build tags to include when compiling. Empty by default.
+            gcflags: The gcflags (go compiler flags) to use, passed as a list of strings. Empty by default.
+            ldflags: The ldflags (go linker flags) to use, passed as a list of strings. Empty by default.
+            ldflags_vars: String variables to set at link time with the `-X` linker flag, such as
+                `{"main.Version": "1.0.0"}`. These are merged with `ldflags`. Empty by default.
+            env_vars: Extra environment variables to set for the build command. Empty by default.
+            force_rebuild: Whether to force a rebuild of the package and bypass the build cache.
+            cgo: Whether to enable cgo. By default, cgo is disabled for targets other than the host.
+            incremental: Whether to skip targets whose inputs have not changed since their output was built, as
+                determined by a [digest][dda.utils.go.build.input_digest] stored next to the output with the
+                `.inputs` suffix. This is ignored when `force_rebuild` is enabled.
+            toolchain: The [toolchain](https://go.dev/doc/toolchain) to use, such as `go1.22.3`, which is downloaded
+                if necessary. The value `local` forces the use of the installed toolchain and prevents downloads.
+                Defaults to the version detected from files in the current directory.
+            output_stream: A stream to which the output of the builds is written as it is produced, rather than
+                only being captured in the results. Lines are always written in their entirety.
+            prefix_output: Whether to prefix each line written to `output_stream` with its target, such as
+                `[linux/amd64] `.
+            timeout: The maximum number of seconds to spend building all targets. The builds that are running
+                when the time runs out are stopped, along with every process they spawned, and the remaining
+                targets are not built.
+            cancel: An event that, once set, stops the running build in the same way as `timeout`.
+            trace: Whether to record the commands run by each build, using the `-x` flag, as the
+                [`actions`][dda.utils.go.build.BuildResult.actions] of the results.
+            trace_stream: A stream to which the raw output of each traced build is written.
+            retry: How to retry builds that fail with [transient errors][dda.utils.go.build.is_transient_error],
+                such as network errors while downloading modules. Each retry is reported to `output_stream`, or
+                displayed as a warning if there is none. Builds are not retried by default.
+            mod: The [module download mode](https://go.dev/ref/mod#build-commands), as described for
+                [`build`][dda.tools.go.Go.build].
+            overlay: Files to replace while building, as described for [`build`][dda.tools.go.Go.build]. The
+                replacements are taken into account by `incremental` builds.
+            parallelism: The maximum number of targets to build concurrently. Targets are built one at a time by
+                default.
+            lock: Whether to hold a lock on the output directory of each target while building, so that concurrent
+                invocations writing to the same directory wait for each other rather than interfere. Waiting for
+                the lock is subject to `timeout` and `cancel`.
+            try_lock: Whether to fail immediately rather than wait if the lock is held by another build, which
+                implies `lock`.
+            lock_dir: The directory in which to create lock files, defaulting to the `go/locks` directory within
+                the [cache directory][dda.config.model.storage.StorageDirs.cache].
+            race: Whether to enable the race detector, using the `-race` flag. Targets that do not support it fail
+                without being built. As the race detector requires cgo, it is enabled for every target. The value
+                `None` enables it only for the targets that support it, like the default of
+                [`build`][dda.tools.go.Go.build], and only those are then built with cgo. It is disabled by default
+                since cgo requires a C toolchain for each target.
+            trimpath: Whether to remove file system paths from the binaries, as described for
+                [`build`][dda.tools.go.Go.build].
+            extra_args: Flags to pass verbatim after the managed flags, as described for
+                [`build`][dda.tools.go.Go.build]. They are taken into account by `incremental` builds.
+            pgo: The profile to use for profile-guided optimization, as described for
+                [`build`][dda.tools.go.Go.build]. The contents of the profile are taken into account by
+                `incremental` builds. Whether a profile was used can be
+                [determined][dda.utils.go.trace.pgo_profile] from the `actions` of traced builds.
+            size_report: Whether to break down the size of the binaries by kind of symbol, as the
+                [`sections`][dda.utils.go.build.BuildResult.sections] of the results, using `go tool nm`. The total
+                [`size`][dda.utils.go.build.BuildResult.size] is always reported.
+            dry_run: Whether to return the [plan][dda.utils.go.plan.Plan] of the builds rather than running them.
+                Targets that would be skipped, such as unsupported targets and those whose `incremental` builds
+                are up to date, have no command. Outputs are neither locked nor written. This cannot be combined
+                with `overlay`.
+
+        Returns:
+            The result of each build, in the same order as the targets, or their plan if `dry_run` is enabled.
+        """
+        import threading
+        import time
+
+        from dda.utils.fs import Path
+        from dda.utils.go.build import RACE_TARGETS, BuildResult, RaceUnsupportedError, is_transient_error
+        from dda.utils.go.trace import parse_build_trace
+        from dda.utils.process import EnvVars
+        from dda.utils.retry import backoff_delays
+
+        if dry_run and overlay:
+            self.app.abort(_DRY_RUN_OVERLAY_ERROR)
+
+        extra_args = self._extra_args(extra_args)
+        pgo = self._pgo_profile(pgo, toolchain, None)
+        if toolchain is not None:
+            env_vars = {**(env_vars or {}), "GOTOOLCHAIN": self._validate_toolchain(toolchain)}
+
+        output_lock = threading.Lock()
+        deadline = None if timeout is None else time.monotonic() + timeout
+        if lock_dir is None:
+            lock_dir = self.app.config.storage.join("go", "locks").cache
+        env_vars = self._workspace_env_vars(env_vars, packages, None)
+        race_targets = {target for target in targets if _race_enabled(race, target)}
+        if race_targets and cgo is False:
+            self.app.display_warning("Enabling cgo, which is required by the race detector")
+        # Resolve the supported targets once rather than in every thread
+        supported_targets = self.supported_targets
+        planned: list[CommandSpec] = []
+        planned_warnings: list[str] = []
+
+        def build_target(target: Target, overlay_file: Path | None) -> BuildResult:
+            output_path = Path(output.format(goos=target.goos, goarch=target.goarch))
+            if (reason := _stop_reason(target, timeout, deadline, cancel)) is not None:
+                return BuildResult(target=target, output=output_path, error=reason)
+
+            if target not in supported_targets:
+                return BuildResult(target=target, output=output_path, error=f"Unsupported target: {target}")
+
+            if race and target not in RACE_TARGETS:
+                return BuildResult(target=target, output=output_path, error=str(RaceUnsupportedError(target)))
+
+            with self._output_lock(
+                target,
+                output_path,
+                lock_dir=lock_dir if (lock or try_lock) and not dry_run else None,
+                blocking=not try_lock,
+                timeout=timeout,
+                deadline=deadline,
+                cancel=cancel,
+            ) as lock_error:
+                if lock_error is not None:
+                    return BuildResult(target=target, output=output_path, error=lock_error)
+
+                target_env_vars = dict(env_vars or {})
+                target_env_vars.update({"GOOS": target.goos, "GOARCH": target.goarch})
+                if target in race_targets:
+                    target_env_vars["CGO_ENABLED"] = "1"
+                elif cgo is not None:
+                    target_env_vars["CGO_ENABLED"] = "1" if cgo else "0"
+                elif target != self.host_target:
+                    target_env_vars["CGO_ENABLED"] = "0"
+
+                command_parts = self._build_flags(
+                    output=output_path,
+                    build_tags=build_tags,
+                    gcflags=gcflags,
+                    ldflags=ldflags,
+                    ldflags_vars=ldflags_vars,
+                    force_rebuild=force_rebuild,
+                    race=target in race_targets,
+                    trace=trace,
+                    mod=mod,
+                    trimpath=trimpath,
+                    pgo=pgo,
+                )
+                if overlay_file is not None:
+                    command_parts.append(f"-overlay={overlay_file}")
+                command_parts.extend(extra_args)
+                command_parts.extend(str(package) for package in packages)
+                warnings = tuple(self._goflags_warnings(command_parts, target_env_vars))
+
+                digest = None
+                digest_file = output_path.with_name(f"{output_path.name}.inputs")
+                if incremental:
+                    digest = self._input_digest(target, build_tags, command_parts, target_env_vars, overlay_file)
+                    if (
+                        not force_rebuild
+                        and digest is not None
+                        and output_path.is_file()
+                        and digest_file.is_file()
+                        and digest_file.read_text(encoding="utf-8").strip() == digest
+                    ):
+                        size, sections, size_warnings = self._output_size(output_path, size_report)
+                        return BuildResult(
+                            target=target,
+                            output=output_path,
+                            cached=True,
+                            warnings=(*warnings, *size_warnings),
+                            size=size,
+                            sections=sections,
+                        )
+
+                if dry_run:
+                    planned.append(self._command_spec(["build", *command_parts], target_env_vars, None))
+                    planned_warnings.extend(warnings)
+                    return BuildResult(target=target, output=output_path, warnings=warnings)
+
+                start = time.monotonic()
+                delays = None
+                attempts = 0
+                while True:
+                    attempts += 1
+                    error = None
+                    if output_stream is None and trace_stream is None and deadline is None and cancel is None:
+                        process = self.attach(
+                            ["build", *command_parts],
+                            check=False,
+                            capture_output=True,
+                            encoding="utf-8",
+                            env=EnvVars(target_env_vars),
+                        )
+                        exit_code, stderr = process.returncode, process.stderr
+                    else:
+                        exit_code, stderr, error = self._watch_build(
+                            target,
+                            command_parts,
+                            env_vars=target_env_vars,
+                            stream=output_stream,
+                            prefix=f"[{target}] " if prefix_output else "",
+                            lock=output_lock,
+                            raw_stream=trace_stream,
+                            timeout=timeout,
+                            deadline=deadline,
+                            cancel=cancel,
+                        )
+
+                    if not exit_code or error is not None or retry is None or not is_transient_error(stderr):
+                        break
+
+                    if delays is None:
+                        delays = backoff_delays(
+                            max_retries=retry.max_attempts - 1, min_delay=retry.min_delay, max_delay=retry.max_delay
+                        )
+
+                    delay = next(delays, None)
+                    # Never retry if the next attempt would start after the deadline
+                    if delay is None or (deadline is not None and time.monotonic() + delay >= deadline):
+                        break
+
+                    message = f"Build of {target} failed with a transient error, retrying in {delay:.1f} seconds"
+                    if output_stream is None:
+                        self.app.display_warning(message)
+                    else:
+                        with output_lock:
+                            output_stream.write(f"{f'[{target}] ' if prefix_output else ''}{message}\n")
+                            output_stream.flush()
+
+                    if cancel is None:
+                        time.sleep(delay)
+                    elif cancel.wait(delay):
+                        error = _stop_reason(target, timeout, deadline, cancel)
+                        break
+
+                if exit_code and error is None:
+                    error = f"Build failed with exit code {exit_code}"
+
+                if digest is not None and error is None:
+                    digest_file.write_text(digest, encoding="utf-8")
+
+                duration = time.monotonic() - start
+                size, sections, size_warnings = 0, {}, ()
+                if error is None:
+                    size, sections, size_warnings = self._output_size(output_path, size_report)
+
+                return BuildResult(
+                    target=target,
+                    output=output_path,
+                    duration=duration,
+                    stderr=stderr,
+                    error=error,
+                    actions=tuple(parse_build_trace(stderr.splitlines())) if trace else (),
+                    attempts=attempts,
+                    warnings=(*warnings, *size_warnings),
+                    size=size,
+                    sections=sections,
+                )
+
+        with self._overlay_file(overlay) as overlay_file:
+            if dry_run:
+                from dda.utils.go.plan import Plan
+
+                results = [build_target(target, overlay_file) for target in targets]
+                planned_warnings.extend(result.error for result in results if result.error is not None)
+                return Plan(commands=tuple(planned), warnings=tuple(dict.fromkeys(planned_warnings)))
+
+            if parallelism <= 1:
+                return [build_target(target, overlay_file) for target in targets]
+
+            from concurrent.futures import ThreadPoolExecutor
+
+            with ThreadPoolExecutor(max_workers=parallelism) as executor:
+                return list(executor.map(lambda target: build_target(target, overlay_file), targets))
+
+    def build_matrix(
+        self,
+        *packages: str | PathLike,
+        targets: Iterable[Target],
+        root: str | PathLike | None = None,
+        parallelism: int | None = None,
+        **kwargs: Any,
+    ) -> MatrixReport | Plan:
+        """
+        Validate that packages build cleanly for every target, discarding the binaries. Targets are built
+        concurrently with [`build_targets`][dda.tools.go.Go.build_targets]. The
+        [sizes][dda.utils.go.build.MatrixReport.sizes] of the binaries are measured before they are discarded.
+
+        Example usage:
+
+        ```python
+        report = app.tools.go.build_matrix("./cmd/...", targets=targets, parallelism=4)
+        for result in report.failures:
+            app.display_error(f"{result.target}: {result.error}\\n{result.stderr}")
 
-        # Enable data race detection on platforms that support it (all except windows arm64)
-        if not (PLATFORM_ID == "windows" and architecture() == "arm64"):
+        if not report.all_passed:
+            app.abort()
+        ```
+
+        Args:
+            packages: The go packages to build, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to building the current directory.
+            targets: The targets for which to build.
+            root: The directory in which to build, defaulting to the current working directory.
+            parallelism: The maximum number of targets to build concurrently, defaulting to the number of CPUs.
+            **kwargs: Additional arguments to pass to [`build_targets`][dda.tools.go.Go.build_targets].
+
+        Returns:
+            The result of each build, in the same order as the targets, or their plan if `dry_run` is enabled. The
+            outputs of the plan are in a temporary directory that no longer exists.
+        """
+        import os
+        import time
+        from contextlib import nullcontext
+
+        from dda.utils.fs import Path, temp_directory
+        from dda.utils.go.build import MatrixReport
+
+        targets = list(targets)
+        start = time.monotonic()
+        with temp_directory() as temp_dir, nullcontext() if root is None else Path(root).as_cwd():
+            # Binaries are written into existing directories so that any number of packages may be built
+            for target in targets:
+                (temp_dir / f"{target.goos}_{target.goarch}").ensure_dir()
+
+            results = self.build_targets(
+                *packages,
+                targets=targets,
+                output=str(temp_dir / "{goos}_{goarch}"),
+                parallelism=parallelism or os.cpu_count() or 1,
+                **kwargs,
+            )
+
+        if not isinstance(results, list):
+            return results
+
+        return MatrixReport(results=tuple(results), duration=time.monotonic() - start)
+
+    def _output_size(self, output: Path, sections: bool) -> tuple[int, dict[str, int], tuple[str, ...]]:
+        from dda.utils.go.build import output_size, parse_symbol_sizes
+
+        size = output_size(output)
+        if not sections:
+            return size, {}, ()
+
+        binaries = sorted(path for path in output.iterdir() if path.is_file()) if output.is_dir() else [output]
+        totals: dict[str, int] = {}
+        warnings: list[str] = []
+        for binary in binaries:
+            process = self.attach(
+                ["tool", "nm", "-size", str(binary)], check=False, capture_output=True, encoding="utf-8"
+            )
+            # Binaries that were stripped with `-ldflags=-s` have no symbols
+            if process.returncode:
+                warnings.append(f"Unable to break down the size of {binary}: {process.stderr.strip()}")
+                continue
+
+            for kind, kind_size in parse_symbol_sizes(process.stdout).items():
+                totals[kind] = totals.get(kind, 0) + kind_size
+
+        return size, totals, tuple(warnings)
+
+    @contextmanager
+    def test_stream(
+        self,
+        *packages: str | PathLike,
+        run: str | None = None,
+        tests: Iterable[str] | None = None,
+        subtests: Mapping[str, Iterable[str]] | None = None,
+        build_tags: set[str] | None = None,
+        timeout: float | None = None,
+        race: bool = False,
+        extra_args: Iterable[str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+    ) -> Generator[TestStream, None, None]:
+        """
+        Run `go test -json` and stream the decoded events as they are emitted. The process is killed if it is
+        still running when the context exits.
+
+        Example usage:
+
+        ```python
+        with app.tools.go.test_stream("./...") as stream:
+            for event in stream:
+                if event.action == TestAction.FAIL and event.test:
+                    app.display_error(f"{event.package}: {event.test}")
+
+        if not stream.passed:
+            app.abort()
+        ```
+
+        Args:
+            packages: The go packages to test, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to testing the current directory.
+            run: A regular expression selecting the tests to run, passed to the `-run` flag. Every test whose name
+                contains a match is selected.
+            tests: The names of the tests to run, which are [selected exactly][dda.utils.go.testing.run_pattern]
+                along with all of their subtests. This cannot be combined with `run`.
+            subtests: A mapping of tests to the names of the subtests of theirs to run, which are selected exactly.
+                This cannot be combined with `run`.
+            build_tags: Build tags to include when compiling. Empty by default.
+            timeout: The number of seconds after which the tests of a package are killed, passed to the `-timeout`
+                flag. The value 0 disables the timeout. Defaults to that of the `go` command, which is 10 minutes.
+            race: Whether to enable the race detector, using the `-race` flag, which requires cgo.
+            extra_args: Flags to pass verbatim after the managed flags, such as `-count=1` or `-shuffle=on`, as
+                described for [`build`][dda.tools.go.Go.build].
+            env_vars: Extra environment variables to set for the test command. Empty by default.
+            cwd: The working directory in which to run the command.
+
+        Raises:
+            RaceUnsupportedError: If `race` is enabled but the target does not support the race detector.
+        """
+        import subprocess
+
+        from dda.utils.go.testing import TestStream
+
+        command_parts, env_vars, warnings = self._test_command(
+            packages,
+            run=run,
+            tests=tests,
+            subtests=subtests,
+            build_tags=build_tags,
+            timeout=timeout,
+            race=race,
+            extra_args=extra_args,
+            env_vars=env_vars,
+            cwd=cwd,
+        )
+        with self._popen(
+            command_parts,
+            env_vars=env_vars,
+            cwd=cwd,
+            stdout=subprocess.PIPE,
+            stderr=subprocess.PIPE,
+            encoding="utf-8",
+            errors="replace",
+        ) as process:
+            yield TestStream(process, warnings=warnings)
+
+    def _test_command(
+        self,
+        packages: tuple[str | PathLike, ...],
+        *,
+        run: str | None,
+        tests: Iterable[str] | None,
+        subtests: Mapping[str, Iterable[str]] | None,
+        build_tags: set[str] | None,
+        timeout: float | None,
+        race: bool,
+        extra_args: Iterable[str] | None,
+        env_vars: dict[str, str] | None,
+        cwd: str | PathLike | None,
+    ) -> tuple[list[str], dict[str, str] | None, list[str]]:
+        extra_args = self._extra_args(extra_args)
+        if tests or subtests:
+            from dda.utils.go.testing import run_pattern
+
+            if run:
+                self.app.abort("The `run` argument cannot be combined with `tests` or `subtests`")
+
+            run = run_pattern(tests or (), subtests)
+
+        command_parts = ["test", "-json"]
+        if race:
+            env_vars = self._race_env_vars(env_vars)
             command_parts.append("-race")
+        if build_tags:
+            command_parts.extend(("-tags", f"{','.join(sorted(build_tags))}"))
+        if run:
+            command_parts.extend(("-run", run))
+        if timeout is not None:
+            command_parts.append(f"-timeout={timeout}s")
 
-        if self.app.config.terminal.verbosity >= Verbosity.VERBOSE:
-            command_parts.append("-v")
-        if self.app.config.terminal.verbosity >= Verbosity.DEBUG:
-            command_parts.append("-x")
+        command_parts.extend(extra_args)
+        command_parts.extend(str(package) for package in packages)
 
-        if gcflags:
-            command_parts.append(f"-gcflags={' '.join(gcflags)}")
-        if ldflags:
-            command_parts.append(f"-ldflags={' '.join(ldflags)}")
+        env_vars = self._workspace_env_vars(env_vars, packages, cwd)
+        warnings = self._goflags_warnings(command_parts[1:], env_vars)
+        return command_parts, env_vars, warnings
+
+    def run_tests(
+        self,
+        *packages: str | PathLike,
+        run: str | None = None,
+        tests: Iterable[str] | None = None,
+        subtests: Mapping[str, Iterable[str]] | None = None,
+        build_tags: set[str] | None = None,
+        timeout: float | None = None,
+        race: bool = False,
+        extra_args: Iterable[str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+        dry_run: bool = False,
+    ) -> TestRun | Plan:
+        """
+        Run tests with [`test_stream`][dda.tools.go.Go.test_stream] and collect every event.
+
+        Example usage:
+
+        ```python
+        from dda.utils.go.testing import TestTimeoutError
+
+        try:
+            run = app.tools.go.run_tests("./...", timeout=300)
+        except TestTimeoutError as e:
+            app.abort(f"Hanging tests: {', '.join(e.running_at_timeout)}")
+
+        if not run.passed:
+            app.abort(f"Failed tests: {', '.join(run.failures)}")
+        ```
+
+        Args:
+            packages: The go packages to test, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to testing the current directory.
+            run: A regular expression selecting the tests to run, passed to the `-run` flag.
+            tests: The names of the tests to run, as described for [`test_stream`][dda.tools.go.Go.test_stream].
+            subtests: A mapping of tests to the names of the subtests of theirs to run, as described for
+                [`test_stream`][dda.tools.go.Go.test_stream].
+            build_tags: Build tags to include when compiling. Empty by default.
+            timeout: The number of seconds after which the tests of a package are killed, passed to the `-timeout`
+                flag.
+            race: Whether to enable the race detector, using the `-race` flag, which requires cgo.
+            extra_args: Flags to pass verbatim after the managed flags, such as `-count=1` or `-shuffle=on`, as
+                described for [`build`][dda.tools.go.Go.build].
+            env_vars: Extra environment variables to set for the test command. Empty by default.
+            cwd: The working directory in which to run the command.
+            dry_run: Whether to return the [plan][dda.utils.go.plan.Plan] of the command rather than running it.
+
+        Returns:
+            The outcome of the tests, or the plan of the command if `dry_run` is enabled.
+
+        Raises:
+            TestTimeoutError: If the tests of any package timed out, with the tests that were still running.
+            RaceUnsupportedError: If `race` is enabled but the target does not support the race detector.
+        """
+        from dda.utils.go.testing import TestRun, TestTimeoutError
+
+        if dry_run:
+            from dda.utils.go.plan import Plan
 
+            command_parts, test_env_vars, warnings = self._test_command(
+                packages,
+                run=run,
+                tests=tests,
+                subtests=subtests,
+                build_tags=build_tags,
+                timeout=timeout,
+                race=race,
+                extra_args=extra_args,
+                env_vars=env_vars,
+                cwd=cwd,
+            )
+            return Plan(commands=(self._command_spec(command_parts, test_env_vars, cwd),), warnings=tuple(warnings))
+
+        with self.test_stream(
+            *packages,
+            run=run,
+            tests=tests,
+            subtests=subtests,
+            build_tags=build_tags,
+            timeout=timeout,
+            race=race,
+            extra_args=extra_args,
+            env_vars=env_vars,
+            cwd=cwd,
+        ) as stream:
+            events = tuple(stream)
+
+        result = TestRun(events=events, exit_code=stream.exit_code or 0, warnings=tuple(stream.warnings))
+        if timed_out := stream.timed_out:
+            running = [test for package in timed_out for test in stream.running(package)]
+            raise TestTimeoutError(running, timed_out, result)
+
+        return result
+
+    def benchmark(
+        self,
+        *packages: str | PathLike,
+        bench: str = ".",
+        count: int | None = None,
+        benchtime: str | None = None,
+        benchmem: bool = False,
+        build_tags: set[str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+    ) -> list[BenchmarkResult]:
+        """
+        Run benchmarks with `go test -bench` and [parse][dda.utils.go.bench.parse_benchmark_output] the results.
+        Tests are not run.
+
+        Example usage:
+
+        ```python
+        from dda.utils.go.bench import format_benchstat
+
+        results = app.tools.go.benchmark("./pkg/...", bench="Encode", count=10, benchmem=True)
+        Path("new.txt").write_text(format_benchstat(results))
+        ```
+
+        Args:
+            packages: The go packages to benchmark, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to benchmarking the current directory.
+            bench: A regular expression selecting the benchmarks to run, passed to the `-bench` flag. All
+                benchmarks are run by default.
+            count: The number of times to run each benchmark, passed to the `-count` flag. Every run produces a
+                separate result.
+            benchtime: The amount of time or iterations to run each benchmark for, such as `2s` or `1000x`, passed
+                to the `-benchtime` flag.
+            benchmem: Whether to report memory allocation statistics.
+            build_tags: Build tags to include when compiling. Empty by default.
+            env_vars: Extra environment variables to set for the test command. Empty by default.
+            cwd: The working directory in which to run the command.
+
+        Returns:
+            The result of every run, in the order they were reported.
+        """
+        from dda.utils.go.bench import parse_benchmark_output
+        from dda.utils.process import EnvVars
+
+        command_parts = ["test", "-run", "^$", "-bench", bench]
+        if count is not None:
+            command_parts.append(f"-count={count}")
+        if benchtime:
+            command_parts.append(f"-benchtime={benchtime}")
+        if benchmem:
+            command_parts.append("-benchmem")
+        if build_tags:
+            command_parts.extend(("-tags", f"{','.join(sorted(build_tags))}"))
+
+        command_parts.extend(str(package) for package in packages)
+
+        process = self.attach(
+            command_parts,
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env_vars),
+            cwd=cwd,
+        )
+        if process.returncode:
+            self.app.abort(
+                f"Command failed with exit code {process.returncode}: go test\n{process.stdout}{process.stderr}"
+            )
+
+        return parse_benchmark_output(process.stdout)
+
+    def vet(
+        self,
+        *packages: str | PathLike,
+        analyzers: Iterable[str] | None = None,
+        build_tags: set[str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+    ) -> list[Diagnostic]:
+        """
+        Run `go vet -json` and return the reported problems. The application aborts if the command fails to run,
+        for example due to a compilation error, which is distinct from the command reporting problems.
+
+        Example usage:
+
+        ```python
+        for diagnostic in app.tools.go.vet("./...", analyzers=["printf", "copylocks"]):
+            app.display_warning(f"{diagnostic} ({diagnostic.analyzer})")
+        ```
+
+        Args:
+            packages: The go packages to vet, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to vetting the current directory.
+            analyzers: The names of the analyzers to run, defaulting to those enabled by `go vet`.
+            build_tags: Build tags to include when compiling. Empty by default.
+            env_vars: Extra environment variables to set for the vet command. Empty by default.
+            cwd: The working directory in which to run the command.
+
+        Returns:
+            The diagnostics, in the order they were reported.
+        """
+        from dda.utils.go.diagnostics import parse_vet_output
+        from dda.utils.process import EnvVars
+
+        command_parts = ["vet", "-json"]
+        command_parts.extend(f"-{analyzer}" for analyzer in analyzers or ())
         if build_tags:
             command_parts.extend(("-tags", f"{','.join(sorted(build_tags))}"))
 
         command_parts.extend(str(package) for package in packages)
 
-        # TODO: Debug log the command parts ?
-        return self._build(command_parts, env=env_vars, **kwargs)
+        process = self.attach(
+            command_parts,
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env_vars),
+            cwd=cwd,
+        )
+        if process.returncode:
+            self.app.abort(f"Command failed with exit code {process.returncode}: go vet\n{process.stderr}")
+
+        # Older toolchains emit the results on standard error
+        try:
+            return parse_vet_output(f"{process.stdout}\n{process.stderr}")
+        except ValueError as e:
+            self.app.abort(str(e))
+
+    def format(
+        self,
+        *paths: str | PathLike,
+        root: str | PathLike | None = None,
+        write: bool = False,
+        diff: bool = False,
+        formatter: str = "gofmt",
+        exclude: Iterable[str] = ("vendor",),
+    ) -> FormatResult:
+        """
+        Check or fix the formatting of Go source files with `gofmt` or
+        [`gofumpt`](https://github.com/mvdan/gofumpt). Hidden directories are always skipped.
+
+        Example usage:
+
+        ```python
+        result = app.tools.go.format(diff=True)
+        for path, changes in result.diffs.items():
+            app.display(changes)
+
+        if not result.formatted:
+            app.abort(f"Run `gofmt -w` on: {', '.join(result.files)}")
+        ```
+
+        Args:
+            paths: The files and directories to format, relative to the root. Defaults to the root itself.
+            root: The directory in which to run the formatter, defaulting to the current working directory.
+            write: Whether to rewrite files in place, using the `-w` flag, rather than only listing them.
+            diff: Whether to collect the changes to each file as a unified diff, using the `-d` flag. This is
+                ignored when `write` is enabled.
+            formatter: Either `gofmt`, which is part of the toolchain, or `gofumpt`, which is used from `PATH` if
+                available and otherwise [installed][dda.tools.go.Go.install_tool] at a pinned version.
+            exclude: The [directories][dda.utils.go.formatting.find_go_files] to skip, by name or by path relative
+                to the root.
+
+        Returns:
+            The files whose formatting differs, along with their diffs if requested.
+        """
+        from dda.utils.fs import Path
+        from dda.utils.go.formatting import FORMATTERS, FormatResult, find_go_files, parse_format_diff
+
+        if formatter not in FORMATTERS:
+            self.app.abort(f"Invalid formatter `{formatter}`, expected one of: {', '.join(sorted(FORMATTERS))}")
+
+        root = Path.cwd() if root is None else Path(root)
+        files = find_go_files(root, paths or (".",), exclude)
+        binary = self._formatter_binary(formatter)
+
+        flags = ["-l", "-w"] if write else ["-d"] if diff else ["-l"]
+        output: list[str] = []
+        for chunk in _chunk_arguments(files):
+            process = self.app.subprocess.attach(
+                [binary, *flags, *chunk], check=False, capture_output=True, encoding="utf-8", cwd=root
+            )
+            # Differences are reported with an exit code of 1 when showing diffs
+            if process.returncode and not (flags == ["-d"] and process.returncode == 1 and not process.stderr):
+                self.app.abort(f"Command failed with exit code {process.returncode}: {formatter}\n{process.stderr}")
+
+            output.append(process.stdout)
+
+        if flags == ["-d"]:
+            diffs = parse_format_diff("".join(output))
+            return FormatResult(files=tuple(diffs), diffs=diffs)
+
+        return FormatResult(files=tuple(line for chunk in output for line in chunk.splitlines() if line))
+
+    def _formatter_binary(self, formatter: str) -> str:
+        from dda.utils.platform import PLATFORM_ID, which
+
+        if formatter == "gofumpt":
+            from dda.utils.go.formatting import GOFUMPT_VERSION
+
+            return which("gofumpt") or str(
+                self.install_tool(f"mvdan.cc/gofumpt@{GOFUMPT_VERSION}", require_version=True)
+            )
+
+        # The formatter of the toolchain that is used for builds takes precedence over any other on `PATH`
+        binary = Path(self.toolchain.env("GOROOT"), "bin", "gofmt.exe" if PLATFORM_ID == "windows" else "gofmt")
+        if binary.is_file():
+            return str(binary)
+
+        if (binary := which("gofmt")) is None:
+            self.app.abort("Unable to find the `gofmt` binary")
+
+        return binary
+
+    def generate(
+        self,
+        *packages: str | PathLike,
+        run: str | None = None,
+        build_tags: set[str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+        dry_run: bool = False,
+    ) -> list[Generator]:
+        """
+        Run the `//go:generate` directives of the given packages using `go generate`.
+
+        Example usage:
+
+        ```python
+        for generator in app.tools.go.generate("./pkg/...", run="mockgen"):
+            app.display_info(f"Generated mocks with: {generator.command}")
+        ```
+
+        Args:
+            packages: The go packages to process, passed as a list of strings or Paths.
+                Empty by default, which is equivalent to processing the current directory.
+            run: A regular expression selecting the directives to run, passed to the `-run` flag.
+            build_tags: Build tags to include when selecting files. Empty by default.
+            env_vars: Extra environment variables to set for the generate command. Empty by default.
+            cwd: The working directory in which to run the command.
+            dry_run: Whether to only display the commands that would be run, without running them.
+
+        Returns:
+            The generators that were run, or that would be run when `dry_run` is enabled.
+        """
+        from dda.utils.go.generate import parse_generate_plan
+        from dda.utils.process import EnvVars
+
+        command_parts = ["generate"]
+        if run:
+            command_parts.extend(("-run", run))
+        if build_tags:
+            command_parts.extend(("-tags", f"{','.join(sorted(build_tags))}"))
+
+        targets = [str(package) for package in packages]
+
+        # The `-n` flag prints the commands that would run without running them
+        process = self.attach(
+            [*command_parts, "-n", "-v", *targets],
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env_vars),
+            cwd=cwd,
+        )
+        if process.returncode:
+            self.app.abort(f"Command failed with exit code {process.returncode}: go generate\n{process.stderr}")
+
+        generators = parse_generate_plan(process.stderr, cwd)
+        if dry_run:
+            for generator in generators:
+                self.app.display(str(generator))
+        elif generators:
+            self.run([*command_parts, *targets], env=EnvVars(env_vars), cwd=cwd)
+
+        return generators
+
+    def check_generated(
+        self,
+        *packages: str,
+        run: str | None = None,
+        build_tags: set[str] | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+    ) -> list[str]:
+        """
+        Determine which files would be changed by [`generate`][dda.tools.go.Go.generate] without modifying the
+        working tree. The module containing the working directory is copied to a temporary directory in which
+        the generators run, after which the copy is [compared][dda.utils.go.generate.changed_files] with the
+        original.
+
+        Example usage:
+
+        ```python
+        if stale := app.tools.go.check_generated("./...", run="mockgen"):
+            app.abort(f"These generated files are out of date: {', '.join(stale)}")
+        ```
+
+        Args:
+            packages: The go packages to process, which must be relative to the working directory.
+                Empty by default, which is equivalent to processing the working directory.
+            run: A regular expression selecting the directives to run, passed to the `-run` flag.
+            build_tags: Build tags to include when selecting files. Empty by default.
+            env_vars: Extra environment variables to set for the generate command. Empty by default.
+            cwd: The working directory, defaulting to the current working directory.
+
+        Returns:
+            The paths of the files that would change, relative to the root of the module.
+        """
+        import shutil
+
+        from dda.utils.fs import Path, temp_directory
+        from dda.utils.go.generate import changed_files, snapshot_files
+        from dda.utils.go.modules import NoModuleError, detect_project_root
+
+        working_dir = (Path.cwd() if cwd is None else Path(cwd)).resolve()
+        try:
+            root = detect_project_root(working_dir)
+        except NoModuleError as e:
+            self.app.abort(str(e))
+
+        with temp_directory() as temp_dir:
+            copy = temp_dir / root.name
+            shutil.copytree(root, copy, symlinks=True, ignore=shutil.ignore_patterns(".git"))
+            snapshot = snapshot_files(copy)
+
+            self.generate(
+                *packages,
+                run=run,
+                build_tags=build_tags,
+                env_vars=env_vars,
+                cwd=copy / working_dir.relative_to(root),
+            )
+            return changed_files(root, copy, snapshot)
+
+    def verify_vendor(self, root: str | PathLike | None = None) -> list[str]:
+        """
+        Determine whether the `vendor` directory of a module is consistent with its `go.mod` file. A fresh copy of
+        the dependencies is vendored into a temporary directory with `go mod vendor -o`, which is then
+        [compared][dda.utils.go.generate.diff_directories] with the checked-in directory.
+
+        Example usage:
+
+        ```python
+        if drift := app.tools.go.verify_vendor():
+            app.abort(f"The vendor directory is out of date, run `go mod vendor`: {', '.join(drift)}")
+        ```
+
+        Args:
+            root: The directory of the module, defaulting to the root of the module containing the current
+                working directory.
+
+        Returns:
+            The paths of the files that differ, relative to the root of the module.
+        """
+        from dda.utils.fs import Path, temp_directory
+        from dda.utils.go.generate import diff_directories
+        from dda.utils.go.modules import NoModuleError, detect_project_root
+
+        if root is None:
+            try:
+                root = detect_project_root()
+            except NoModuleError as e:
+                self.app.abort(str(e))
+
+        root = Path(root)
+        with temp_directory() as temp_dir:
+            vendor_dir = temp_dir / "vendor"
+            process = self.attach(
+                ["mod", "vendor", "-o", str(vendor_dir)],
+                check=False,
+                capture_output=True,
+                encoding="utf-8",
+                cwd=root,
+            )
+            if process.returncode:
+                self.app.abort(f"Command failed with exit code {process.returncode}: go mod vendor\n{process.stderr}")
+
+            return [f"vendor/{name}" for name in diff_directories(root / "vendor", vendor_dir)]
+
+    def verify_modules(
+        self,
+        root: str | PathLike | None = None,
+        *,
+        check_sum: bool = False,
+        env_vars: dict[str, str] | None = None,
+    ) -> None:
+        """
+        Verify that the dependencies of a module in the module cache have not been modified since they were
+        downloaded, using `go mod verify`. Nothing is built and no file of the module is modified.
+
+        Example usage:
+
+        ```python
+        from dda.utils.go.download import VerificationError
+
+        try:
+            app.tools.go.verify_modules(check_sum=True)
+        except VerificationError as e:
+            app.abort(str(e))
+        ```
+
+        Args:
+            root: The directory of the module, defaulting to the current working directory.
+            check_sum: Whether to also check that `go.sum` has exactly the entries needed by the build list, as
+                [reported][dda.utils.go.download.parse_sum_diff] by `go mod tidy -diff`, which requires Go 1.23
+                or later. Modules that are missing from the module cache are downloaded to do so. Differences in
+                `go.mod` are ignored.
+            env_vars: Extra environment variables to set for the commands. Empty by default.
+
+        Raises:
+            VerificationError: If a module fails verification or, when checking `go.sum`, it has missing or extra
+                entries.
+        """
+        from dda.utils.go.download import VerificationError, parse_sum_diff, parse_verify_output
+        from dda.utils.process import EnvVars
+
+        if check_sum and (version := self._effective_version(None)) is not None and not version.at_least(1, 23):
+            self.app.abort(f"Checking go.sum requires Go 1.23 or later, found {version}")
+
+        process = self.attach(
+            ["mod", "verify"],
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env_vars or {}),
+            cwd=root,
+        )
+        failures = parse_verify_output(process.stderr)
+        if process.returncode and not failures:
+            self.app.abort(f"Command failed with exit code {process.returncode}: go mod verify\n{process.stderr}")
+
+        missing: list[str] = []
+        extra: list[str] = []
+        if check_sum:
+            process = self.attach(
+                ["mod", "tidy", "-diff"],
+                check=False,
+                capture_output=True,
+                encoding="utf-8",
+                env=EnvVars(env_vars or {}),
+                cwd=root,
+            )
+            # The exit code is 1 when changes are needed, which are written to standard output
+            if process.returncode and not process.stdout.startswith("diff "):
+                self.app.abort(f"Command failed with exit code {process.returncode}: go mod tidy\n{process.stderr}")
+
+            missing, extra = parse_sum_diff(process.stdout)
+
+        if failures or missing or extra:
+            raise VerificationError(failures, missing, extra)
+
+    def download(
+        self,
+        *modules: str,
+        root: str | PathLike | None = None,
+        context: BuildContext | None = None,
+        packages: Iterable[str] = ("./...",),
+        verbose: bool = False,
+        env_vars: dict[str, str] | None = None,
+    ) -> DownloadReport:
+        """
+        Populate the module cache with `go mod download` without building anything, so that dependencies can be
+        fetched in a separate step. Every module is verified against `go.sum` and the
+        [checksum database](https://go.dev/ref/mod#checksum-database).
+
+        Example usage:
+
+        ```python
+        from dda.utils.go.constraints import BuildContext
+
+        report = app.tools.go.download(context=BuildContext(goos="linux", goarch="arm64", tags=frozenset({"prod"})))
+        app.display(f"Downloaded {len(report.modules)} modules ({report.total_size} bytes)")
+        ```
+
+        Args:
+            modules: The modules to download, such as `golang.org/x/mod@v0.17.0`. Empty by default, which is
+                equivalent to every module in the build list of the main module unless `context` is set.
+            root: The directory of the module, defaulting to the current working directory.
+            context: A target configuration for which to download only the modules providing the dependencies of
+                `packages`, rather than every module in the build list, so that dependencies specific to other
+                platforms or build tags are not fetched.
+            packages: The package patterns whose dependencies are downloaded when `context` is set.
+            verbose: Whether to print the commands run by the `go` command, using the `-x` flag.
+            env_vars: Extra environment variables to set for the commands. Empty by default.
+
+        Returns:
+            The modules that were downloaded or already present in the module cache.
+
+        Raises:
+            ChecksumError: If a module does not match its expected checksum.
+        """
+        import time
+
+        from dda.config.constants import Verbosity
+        from dda.utils.go.download import DownloadReport, find_checksum_error, parse_download_output
+        from dda.utils.process import EnvVars
+
+        start = time.monotonic()
+        if context is not None:
+            dependencies = self._list_packages(
+                ["ImportPath", "Module"], packages, context=context, env_vars=env_vars, cwd=root, deps=True
+            )
+            needed = {f"{dep.module}@{dep.module_version}" for dep in dependencies if dep.module_version}
+            modules = (*modules, *sorted(needed))
+            # Without arguments every module in the build list would be downloaded
+            if not modules:
+                return DownloadReport(modules=(), duration=time.monotonic() - start)
+
+        command_parts = ["mod", "download", "-json"]
+        if verbose or self.app.config.terminal.verbosity >= Verbosity.DEBUG:
+            command_parts.append("-x")
+        command_parts.extend(modules)
+
+        process = self.attach(
+            command_parts,
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env_vars or {}),
+            cwd=root,
+        )
+        if verbose and process.stderr:
+            self.app.display(process.stderr.rstrip())
+
+        results = parse_download_output(process.stdout)
+        for output in (process.stderr, *(result.error for result in results)):
+            if (error := find_checksum_error(output)) is not None:
+                raise error
+
+        if process.returncode:
+            errors = "\n".join([*(result.error for result in results if result.error), process.stderr]).strip()
+            self.app.abort(f"Command failed with exit code {process.returncode}: go mod download\n{errors}")
+
+        return DownloadReport(modules=tuple(results), duration=time.monotonic() - start)
+
+    def install_tool(
+        self,
+        spec: str,
+        *,
+        bin_dir: str | PathLike | None = None,
+        require_version: bool = False,
+        env_vars: dict[str, str] | None = None,
+    ) -> Path:
+        """
+        Install a tool with `go install` into a dedicated directory rather than the global `GOBIN`. Tools installed
+        at a [pinned][dda.utils.go.install.ToolSpec.pinned] version are recorded in a manifest within the directory
+        so that subsequent calls for the same version do nothing.
+
+        Example usage:
+
+        ```python
+        mockgen = app.tools.go.install_tool("go.uber.org/mock/mockgen@v0.5.0", require_version=True)
+        app.subprocess.run([str(mockgen), "-version"])
+        ```
+
+        Args:
+            spec: The tool to install, in the `package@version` format.
+            bin_dir: The directory in which to install the tool, defaulting to the `go/bin` directory within the
+                [data directory][dda.config.model.storage.StorageDirs.data].
+            require_version: Whether to reject versions that are not pinned, such as `latest`, so that installs
+                cannot silently change.
+            env_vars: Extra environment variables to set for the install command. Empty by default.
+
+        Returns:
+            The path to the installed binary.
+        """
+        from dda.utils.go.install import ToolSpec, read_manifest, write_manifest
+        from dda.utils.process import EnvVars
+
+        try:
+            tool = ToolSpec.from_string(spec)
+        except ValueError as e:
+            self.app.abort(str(e))
+
+        if require_version and not tool.pinned:
+            self.app.abort(f"Tool `{tool.package}` must be pinned to a version, found `{tool.version}`")
+
+        directory = Path(bin_dir) if bin_dir is not None else self.app.config.storage.join("go", "bin").data
+        binary = directory / tool.binary_name(self.host_target.goos)
+        manifest = read_manifest(directory)
+        if tool.pinned and manifest.get(binary.name) == str(tool) and binary.is_file():
+            return binary
+
+        directory.ensure_dir()
+        self.run(["install", str(tool)], env=EnvVars({**(env_vars or {}), "GOBIN": str(directory)}))
+
+        # Queries may resolve to a different version each time so they are never considered installed
+        if tool.pinned:
+            manifest[binary.name] = str(tool)
+        else:
+            manifest.pop(binary.name, None)
+        write_manifest(directory, manifest)
+
+        return binary
+
+    def tool(
+        self,
+        name: str,
+        *args: str,
+        timeout: float | None = None,
+        cancel: Event | None = None,
+        check: bool = True,
+        toolchain: str | None = None,
+        cwd: str | PathLike | None = None,
+        env_vars: dict[str, str] | None = None,
+    ) -> ToolResult:
+        """
+        Run a tool of the toolchain, such as `objdump`, `pprof` or `compile`, with `go tool` and capture its
+        output. The tool is resolved in the same way as for builds, so it matches the selected toolchain.
+
+        Example usage:
+
+        ```python
+        result = app.tools.go.tool("objdump", "-s", "main.main", "bin/agent", timeout=60)
+        app.display(result.stdout.decode("utf-8"))
+        ```
+
+        Args:
+            name: The name of the tool.
+            *args: The arguments to pass to the tool.
+            timeout: The maximum number of seconds to let the tool run. The tool and all of its child processes
+                are then stopped.
+            cancel: An event that, once set, stops the tool in the same way as `timeout`.
+            check: Whether to abort if the tool fails or is stopped rather than returning the result.
+            toolchain: The [toolchain](https://go.dev/doc/toolchain) whose tool should run, defaulting to the
+                one selected for the current directory.
+            cwd: The working directory of the tool, defaulting to the current directory.
+            env_vars: Extra environment variables to set for the tool. Empty by default.
+
+        Returns:
+            The output and exit code of the tool.
+        """
+        import subprocess
+        import threading
+        import time
+
+        from dda.utils.go.install import ToolResult
+
+        if not name or name.startswith("-"):
+            self.app.abort(f"Invalid tool name `{name}`")
+
+        env_vars = dict(env_vars or {})
+        if toolchain is not None:
+            env_vars["GOTOOLCHAIN"] = self._validate_toolchain(toolchain)
+
+        output: dict[str, bytes] = {}
+
+        def read_output(key: str, stream: IO[bytes]) -> None:
+            output[key] = stream.read()
+
+        deadline = None if timeout is None else time.monotonic() + timeout
+        error = None
+        with self._popen(
+            ["tool", name, *args],
+            env_vars=env_vars,
+            cwd=cwd,
+            stdin=subprocess.DEVNULL,
+            stdout=subprocess.PIPE,
+            stderr=subprocess.PIPE,
+            **_process_group_kwargs(),
+        ) as process:
+            readers = [
+                threading.Thread(target=read_output, args=(key, stream), daemon=True)
+                for key, stream in (("stdout", process.stdout), ("stderr", process.stderr))
+            ]
+            for reader in readers:
+                reader.start()
+
+            while process.poll() is None:
+                if cancel is not None and cancel.is_set():
+                    error = f"Command was cancelled: go tool {name}"
+                elif deadline is not None and time.monotonic() >= deadline:
+                    error = f"Command timed out after {timeout} seconds: go tool {name}"
+                if error is not None:
+                    _terminate_process_group(process)
+                    break
+
+                wait_time = _POLL_INTERVAL if deadline is None else min(_POLL_INTERVAL, deadline - time.monotonic())
+                try:
+                    process.wait(timeout=max(wait_time, 0))
+                except subprocess.TimeoutExpired:
+                    pass
+
+            exit_code = process.wait()
+            for reader in readers:
+                reader.join()
+
+        result = ToolResult(
+            stdout=output.get("stdout", b""), stderr=output.get("stderr", b""), exit_code=exit_code, error=error
+        )
+        if check and not result.succeeded:
+            stderr = result.stderr.decode("utf-8", errors="replace")
+            self.app.abort(f"{error or f'Command failed with exit code {exit_code}: go tool {name}'}\n{stderr}")
+
+        return result
+
+    def imports(
+        self,
+        *patterns: str,
+        context: BuildContext | None = None,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+    ) -> dict[str, PackageImports]:
+        """
+        List the imports of packages with `go list`, without building them. The build context determines which
+        files are considered, so that imports only made by files such as `foo_linux.go` are reported for the
+        matching platforms.
+
+        Example usage:
+
+        ```python
+        from dda.utils.go.constraints import BuildContext
+
+        packages = app.tools.go.imports("./pkg/...", context=BuildContext(goos="windows", goarch="amd64"))
+        for package in packages.values():
+            app.display(f"{package.import_path}: {', '.join(package.imports)}")
+        ```
+
+        Args:
+            patterns: The package patterns to list. Empty by default, which is equivalent to listing the package
+                in the working directory.
+            context: The target configuration, defaulting to that of the environment.
+            env_vars: Extra environment variables to set for the list command. Empty by default.
+            cwd: The working directory in which to run the command.
+
+        Returns:
+            The dependencies of each matched package, keyed by import path.
+        """
+        packages = self._list_packages(
+            ["ImportPath", "Imports", "TestImports", "XTestImports", "Deps"],
+            patterns,
+            context=context,
+            env_vars=env_vars,
+            cwd=cwd,
+        )
+        return {package.import_path: package for package in packages}
+
+    def cgo_packages(
+        self,
+        *patterns: str,
+        context: BuildContext | None = None,
+        transitive: bool = True,
+        include_std: bool = False,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+    ) -> list[str]:
+        """
+        Find the packages that use cgo, which prevents building fully static binaries when cross-compiling. Packages
+        are listed with cgo enabled regardless of the build context, since files that import `C` are otherwise
+        excluded.
+
+        Example usage:
+
+        ```python
+        if cgo := app.tools.go.cgo_packages("./cmd/agent", context=BuildContext(goos="linux", goarch="arm64")):
+            app.abort(f"The agent must not require cgo, but it is used by: {', '.join(cgo)}")
+        ```
+
+        Args:
+            patterns: The package patterns to check. Empty by default, which is equivalent to checking the package
+                in the working directory.
+            context: The target configuration, defaulting to that of the environment.
+            transitive: Whether to also check every dependency of the matched packages, rather than only the
+                packages themselves.
+            include_std: Whether to report packages of the standard library, such as `net`, which only use cgo
+                when it is enabled and otherwise fall back to pure Go implementations.
+            env_vars: Extra environment variables to set for the list command. Empty by default.
+            cwd: The working directory in which to run the command.
+
+        Returns:
+            The import paths of the packages that use cgo, in the order they were listed, which is empty if cgo
+            is not required.
+        """
+        from dda.utils.go.constraints import BuildContext
+
+        if context is not None:
+            context = BuildContext(
+                goos=context.goos,
+                goarch=context.goarch,
+                tags=context.tags,
+                cgo=True,
+                compiler=context.compiler,
+                go_version=context.go_version,
+            )
+
+        packages = self._list_packages(
+            ["ImportPath", "CgoFiles", "Standard"],
+            patterns,
+            context=context,
+            env_vars={**(env_vars or {}), "CGO_ENABLED": "1"},
+            cwd=cwd,
+            deps=transitive,
+        )
+        return [
+            package.import_path for package in packages if package.cgo_files and (include_std or not package.standard)
+        ]
+
+    def affected_packages(
+        self,
+        changed_files: Iterable[str | PathLike],
+        root: str | PathLike | None = None,
+        *,
+        depth: int | None = None,
+        tests: bool = True,
+        context: BuildContext | None = None,
+        env_vars: dict[str, str] | None = None,
+    ) -> list[str]:
+        """
+        Determine which packages of a module to build and test after some files changed, using the
+        [import graph][dda.utils.go.packages.affected_packages] of every package in the module. This is a
+        heuristic: changes that affect packages other than through imports, such as to files read at runtime by
+        another package, are not detected.
+
+        Example usage:
+
+        ```python
+        changed = app.tools.git.capture(["diff", "--name-only", "origin/main..."]).splitlines()
+        if packages := app.tools.go.affected_packages(changed, depth=2):
+            app.tools.go.run_tests(*packages)
+        ```
+
+        Args:
+            changed_files: The changed files, relative to the root unless absolute, such as the output of
+                `git diff --name-only` when the module is at the root of the repository.
+            root: The directory of the module, defaulting to the root of the module containing the current
+                working directory.
+            depth: The maximum number of imports between a changed package and an affected package. By default,
+                every package that imports a changed package transitively is affected.
+            tests: Whether packages are also affected when only their tests import an affected package.
+            context: The target configuration that determines the imports, defaulting to that of the environment.
+            env_vars: Extra environment variables to set for the list command. Empty by default.
+
+        Returns:
+            The import paths of the affected packages, sorted, which may be passed as package patterns to other
+            methods. This is empty if no package is affected.
+        """
+        from dda.utils.go.modules import NoModuleError, detect_project_root
+        from dda.utils.go.packages import affected_packages
+
+        if root is None:
+            try:
+                root = detect_project_root()
+            except NoModuleError as e:
+                self.app.abort(str(e))
+
+        if depth is not None and depth < 0:
+            self.app.abort(f"The depth must not be negative: {depth}")
+
+        packages = self._list_packages(
+            ["ImportPath", "Dir", "Imports", "TestImports", "XTestImports"],
+            ["./..."],
+            context=context,
+            env_vars=env_vars,
+            cwd=root,
+        )
+        return affected_packages(packages, changed_files, root, depth=depth, tests=tests)
+
+    def _list_packages(
+        self,
+        fields: list[str],
+        patterns: Iterable[str],
+        *,
+        context: BuildContext | None,
+        env_vars: dict[str, str] | None,
+        cwd: str | PathLike | None,
+        deps: bool = False,
+    ) -> list[PackageImports]:
+        from dda.utils.go.packages import parse_package_list
+        from dda.utils.process import EnvVars
+
+        env = dict(self._workspace_env_vars(env_vars, patterns, cwd) or {})
+        command_parts = ["list", f"-json={','.join(fields)}"]
+        if deps:
+            command_parts.append("-deps")
+        if context is not None:
+            env.update({"GOOS": context.goos, "GOARCH": context.goarch, "CGO_ENABLED": "1" if context.cgo else "0"})
+            if context.tags:
+                command_parts.extend(("-tags", ",".join(sorted(context.tags))))
+            if context.compiler != "gc":
+                command_parts.extend(("-compiler", context.compiler))
+
+        command_parts.extend(patterns)
+
+        process = self.attach(
+            command_parts,
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env),
+            cwd=cwd,
+        )
+        if process.returncode:
+            self.app.abort(f"Command failed with exit code {process.returncode}: go list\n{process.stderr}")
+
+        try:
+            return parse_package_list(process.stdout)
+        except ValueError as e:
+            self.app.abort(f"Unable to parse the output of go list: {e}")
+
+    def outdated(
+        self,
+        root: str | PathLike | None = None,
+        *,
+        indirect: bool = False,
+        prereleases: bool = False,
+        env_vars: dict[str, str] | None = None,
+    ) -> list[ModuleUpdate]:
+        """
+        Find the dependencies of a module for which newer versions are available, using `go list -m -u all`. The
+        module proxy is queried for every dependency, so this requires network access unless `GOPROXY` points
+        to a local mirror.
+
+        Example usage:
+
+        ```python
+        for update in app.tools.go.outdated():
+            app.display(f"{update.path}: {update.current} -> {update.latest}")
+        ```
+
+        Args:
+            root: The directory of the module, defaulting to the current working directory.
+            indirect: Whether to include modules that are only indirect dependencies.
+            prereleases: Whether to suggest prereleases for modules whose current version is a release.
+            env_vars: Extra environment variables to set for the list command. Empty by default.
+
+        Returns:
+            The [available updates][dda.utils.go.updates.parse_module_updates].
+        """
+        from dda.utils.go.updates import parse_module_updates
+        from dda.utils.process import EnvVars
+
+        process = self.attach(
+            # Upgrades cannot be determined in vendor mode, which is the default when a `vendor` directory exists
+            ["list", "-m", "-u", "-json", "-mod=readonly", "all"],
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars(env_vars or {}),
+            cwd=root,
+        )
+        if process.returncode:
+            self.app.abort(f"Command failed with exit code {process.returncode}: go list\n{process.stderr}")
+
+        try:
+            return parse_module_updates(process.stdout, indirect=indirect, prereleases=prereleases)
+        except ValueError as e:
+            self.app.abort(f"Unable to parse the output of go list: {e}")
+
+    def cache_stats(self, toolchain: str | None = None) -> CacheStats:
+        """
+        Measure the disk usage of the [build cache](https://pkg.go.dev/cmd/go#hdr-Build_and_test_caching), as
+        located by `go env GOCACHE`.
+
+        Args:
+            toolchain: The [toolchain](https://go.dev/doc/toolchain) whose cache should be measured, defaulting to
+                the installed toolchain.
+
+        Returns:
+            The [statistics][dda.utils.go.cache.scan_cache] of the cache.
+        """
+        from dda.utils.go.cache import scan_cache
+
+        return scan_cache(self._cache_directory(toolchain))
+
+    def clean_cache(
+        self,
+        *,
+        cache: bool = False,
+        modcache: bool = False,
+        testcache: bool = False,
+        fuzzcache: bool = False,
+        max_size: int | None = None,
+        toolchain: str | None = None,
+    ) -> CacheStats | None:
+        """
+        Remove cached data with `go clean`. Cleaning the build cache entirely discards every incremental build,
+        so setting `max_size` is preferable to keep the most recently used entries.
+
+        Example usage:
+
+        ```python
+        removed = app.tools.go.clean_cache(testcache=True, max_size=10 * 1024**3)
+        app.display(f"Removed {removed.size} bytes from the build cache")
+        ```
+
+        Args:
+            cache: Whether to remove the entire build cache with `-cache`.
+            modcache: Whether to remove the module cache with `-modcache`.
+            testcache: Whether to expire every cached test result with `-testcache`.
+            fuzzcache: Whether to remove the files used for fuzz testing with `-fuzzcache`.
+            max_size: The maximum size of the build cache, in bytes. The
+                [least recently used][dda.utils.go.cache.trim_cache] entries are removed until it is reached.
+                This is ignored if `cache` is enabled.
+            toolchain: The [toolchain](https://go.dev/doc/toolchain) whose caches should be cleaned, defaulting to
+                the installed toolchain.
+
+        Returns:
+            The statistics of the entries removed from the build cache to satisfy `max_size`, or `None` if it
+            was not set.
+        """
+        flags = [
+            flag
+            for flag, enabled in (
+                ("-cache", cache),
+                ("-modcache", modcache),
+                ("-testcache", testcache),
+                ("-fuzzcache", fuzzcache),
+            )
+            if enabled
+        ]
+        if flags:
+            from dda.utils.process import EnvVars
+
+            env_vars = {"GOTOOLCHAIN": self._validate_toolchain(toolchain)} if toolchain is not None else {}
+            process = self.attach(
+                ["clean", *flags], check=False, capture_output=True, encoding="utf-8", env=EnvVars(env_vars)
+            )
+            if process.returncode:
+                self.app.abort(f"Command failed with exit code {process.returncode}: go clean\n{process.stderr}")
+
+        if max_size is None:
+            return None
+
+        from dda.utils.go.cache import CacheStats, trim_cache
+
+        directory = self._cache_directory(toolchain)
+        if cache:
+            return CacheStats(directory=directory)
+
+        return trim_cache(directory, max_size)
+
+    def effective_flags(self, toolchain: str | None = None) -> list[str]:
+        """
+        The flags that the `go` command applies by default, as set by the `GOFLAGS` environment variable or with
+        `go env -w`. Flags passed on the command line take precedence over them.
+
+        Args:
+            toolchain: The [toolchain](https://go.dev/doc/toolchain) whose configuration should be read, defaulting
+                to the installed toolchain.
+
+        Returns:
+            The [flags][dda.utils.go.toolchain.split_goflags], in the order they are applied.
+        """
+        from dda.utils.go.toolchain import split_goflags
+
+        try:
+            return split_goflags(self._go_env("GOFLAGS", toolchain))
+        except ValueError as e:
+            self.app.abort(str(e))
+
+    def _go_env(self, name: str, toolchain: str | None) -> str:
+        if toolchain is None:
+            return self.toolchain.env(name)
+
+        from dda.utils.process import EnvVars
+
+        env_vars = EnvVars({"GOTOOLCHAIN": self._validate_toolchain(toolchain)})
+        return self.capture(["env", name], env=env_vars).strip()
+
+    def _goflags_warnings(self, command_parts: list[str], env_vars: dict[str, str] | None) -> list[str]:
+        import os
+
+        from dda.utils.go.build import goflags_conflicts
+        from dda.utils.go.toolchain import ToolchainError, split_goflags
+
+        if env_vars and "GOFLAGS" in env_vars:
+            goflags = env_vars["GOFLAGS"]
+        elif (goflags := os.environ.get("GOFLAGS")) is None:
+            # Flags set with `go env -w` are only known to the `go` command, whose absence is reported when it runs
+            try:
+                goflags = self.toolchain.env("GOFLAGS") or ""
+            except ToolchainError:
+                return []
+
+        try:
+            flags = split_goflags(goflags)
+        except ValueError:
+            # The `go` command reports the malformed value itself
+            return []
+
+        return goflags_conflicts(flags, command_parts)
+
+    def _cache_directory(self, toolchain: str | None) -> str:
+        directory = self._go_env("GOCACHE", toolchain)
+        if not directory or directory == "off":
+            self.app.abort("The build cache is disabled")
+
+        return directory
+
+    def set_toolchain(self, toolchain: str | None, root: str | PathLike | None = None) -> None:
+        """
+        Set the `toolchain` directive of a module's `go.mod` file with `go mod edit`, which keeps the rest of
+        the file intact and inserts the directive after the `go` directive if there is none.
+
+        Example usage:
+
+        ```python
+        app.tools.go.set_toolchain("go1.22.3", root="path/to/module")
+        ```
+
+        Args:
+            toolchain: The toolchain name, such as `go1.22.3`, which is
+                [validated][dda.utils.go.modules.check_toolchain] against the `go` directive. The `go` prefix is
+                optional. The value `None` removes the directive.
+            root: The directory of the module, defaulting to the current working directory.
+        """
+        from dda.utils.go.modules import NoModuleError, check_toolchain
+
+        directory = Path.cwd() if root is None else Path(root)
+        if toolchain is None:
+            if not (directory / "go.mod").is_file():
+                self.app.abort(str(NoModuleError(directory)))
+
+            toolchain = "none"
+        else:
+            try:
+                toolchain = check_toolchain(directory, toolchain)
+            except (NoModuleError, ValueError) as e:
+                self.app.abort(str(e))
+
+        from dda.utils.process import EnvVars
+
+        # The installed toolchain must not switch to the one that the module currently requires
+        process = self.attach(
+            ["mod", "edit", f"-toolchain={toolchain}"],
+            check=False,
+            capture_output=True,
+            encoding="utf-8",
+            env=EnvVars({"GOTOOLCHAIN": "local"}),
+            cwd=directory,
+        )
+        if process.returncode:
+            self.app.abort(f"Command failed with exit code {process.returncode}: go mod edit\n{process.stderr}")
+
+    def module_graph(self, root: str | PathLike | None = None) -> ModuleGraph:
+        """
+        Build the module requirement graph using `go mod graph`, which respects the active `go.work` file. The
+        output is parsed as it is produced rather than buffered in its entirety.
+
+        Args:
+            root: The directory of the module, defaulting to the current working directory.
+        """
+        import subprocess
+        import tempfile
+
+        from dda.utils.go.graph import ModuleGraph
+
+        with (
+            tempfile.TemporaryFile(mode="w+", encoding="utf-8") as stderr,
+            self._popen(
+                ["mod", "graph"], cwd=root, stdout=subprocess.PIPE, stderr=stderr, encoding="utf-8"
+            ) as process,
+        ):
+            graph = ModuleGraph.from_lines(process.stdout)
+            if exit_code := process.wait():
+                stderr.seek(0)
+                self.app.abort(f"Command failed with exit code {exit_code}: go mod graph\n{stderr.read()}")
+
+        return graph
+
+    def module_diff(self, old: str | bytes, new: str | bytes) -> ModuleDiffReport:
+        """
+        Compare the requirements of two versions of a `go.mod` file, such as those of two commits. Each version is
+        parsed with `go mod edit -json` so that `require` blocks and `replace` directives are interpreted exactly as
+        the `go` command would. No network access is required and neither version must be part of a module on disk.
+
+        Example usage:
+
+        ```python
+        old = app.tools.git.capture(["show", "origin/main:go.mod"])
+        new = app.tools.git.capture(["show", "HEAD:go.mod"])
+        for change in app.tools.go.module_diff(old, new).major_bumps:
+            app.display_warning(f"{change.path}: {change.old_version} -> {change.new_version}")
+        ```
+
+        Args:
+            old: The contents of the previous `go.mod` file.
+            new: The contents of the new `go.mod` file.
+
+        Returns:
+            The [differences][dda.utils.go.moddiff.diff_requirements] between the two versions.
+        """
+        from dda.utils.fs import temp_directory
+        from dda.utils.go.moddiff import diff_requirements, parse_requirements
+        from dda.utils.process import EnvVars
+
+        requirements = []
+        with temp_directory() as temp_dir:
+            for name, contents in (("old", old), ("new", new)):
+                # The files are not named `go.mod` so that the directory is not a module whose `go` directive
+                # could require another toolchain
+                mod_file = temp_dir / f"{name}.mod"
+                if isinstance(contents, str):
+                    mod_file.write_text(contents, encoding="utf-8")
+                else:
+                    mod_file.write_bytes(contents)
+
+                process = self.attach(
+                    ["mod", "edit", "-json", mod_file.name],
+                    check=False,
+                    capture_output=True,
+                    encoding="utf-8",
+                    env=EnvVars({"GOTOOLCHAIN": "local"}),
+                    cwd=temp_dir,
+                )
+                if process.returncode:
+                    self.app.abort(f"Command failed with exit code {process.returncode}: go mod edit\n{process.stderr}")
+
+                requirements.append(parse_requirements(process.stdout))
+
+        return diff_requirements(*requirements)
+
+    @cached_property
+    def supported_targets(self) -> frozenset[Target]:
+        """
+        The targets supported by the toolchain, as reported by `go tool dist list`.
+        """
+        from dda.utils.go.build import Target
+
+        output = self.capture(["tool", "dist", "list"])
+        return frozenset(Target.from_string(line) for line in output.splitlines() if line.strip())
+
+    @cached_property
+    def host_target(self) -> Target:
+        """
+        The target matching the current machine.
+        """
+        from dda.utils.go.build import Target
+
+        return Target(goos=self.toolchain.env("GOHOSTOS"), goarch=self.toolchain.env("GOHOSTARCH"))
+
+    def _watch_build(
+        self,
+        target: Target,
+        command_parts: list[str],
+        *,
+        env_vars: dict[str, str],
+        stream: IO[str] | None,
+        prefix: str,
+        lock: Lock,
+        raw_stream: IO[str] | None,
+        timeout: float | None,
+        deadline: float | None,
+        cancel: Event | None,
+    ) -> tuple[int, str, str | None]:
+        import subprocess
+        import threading
+        import time
+
+        lines: list[str] = []
+
+        def read_output(output: IO[str]) -> None:
+            for line in output:
+                lines.append(line)
+                if raw_stream is not None:
+                    raw_stream.write(line)
+                if stream is not None:
+                    text = line.removesuffix("\n")
+                    # Writing each line at once while holding the lock prevents concurrent builds from interleaving
+                    with lock:
+                        stream.write(f"{prefix}{text}\n")
+                        stream.flush()
+
+        reason = None
+        with self._popen(
+            ["build", *command_parts],
+            env_vars=env_vars,
+            stdout=subprocess.PIPE,
+            stderr=subprocess.STDOUT,
+            encoding="utf-8",
+            errors="replace",
+            # The compiler and linker are child processes of `go build` so they must be stopped as a group
+            **_process_group_kwargs(),
+        ) as process:
+            reader = threading.Thread(target=read_output, args=(process.stdout,), daemon=True)
+            reader.start()
+            while process.poll() is None:
+                if (reason := _stop_reason(target, timeout, deadline, cancel)) is not None:
+                    _terminate_process_group(process)
+                    break
+
+                wait_time = _POLL_INTERVAL if deadline is None else min(_POLL_INTERVAL, deadline - time.monotonic())
+                try:
+                    process.wait(timeout=max(wait_time, 0))
+                except subprocess.TimeoutExpired:
+                    pass
+
+            exit_code = process.wait()
+            reader.join()
+
+        return exit_code, "".join(lines), reason
+
+    def _workspace_env_vars(
+        self,
+        env_vars: dict[str, str] | None,
+        packages: Iterable[str | PathLike],
+        cwd: str | PathLike | None,
+    ) -> dict[str, str] | None:
+        from dda.utils.go.modules import detect_workspace
+
+        if env_vars and "GOWORK" in env_vars:
+            return env_vars
+
+        # The `go` command only looks for a `go.work` file above the working directory, so packages given as paths
+        # into a workspace would otherwise be resolved outside of it
+        directory = Path.cwd() if cwd is None else Path(cwd)
+        for package in packages:
+            pattern = str(package).removesuffix("...").rstrip("/\\") or "."
+            if pattern.startswith(".") or Path(pattern).is_absolute():
+                start = directory / pattern
+                # Files that are built as a single package belong to the workspace of their directory
+                if start.is_file():
+                    start = start.parent
+                if start.is_dir() and (workspace := detect_workspace(start)) is not None:
+                    return {**(env_vars or {}), "GOWORK": str(workspace.file)}
+
+        return env_vars
+
+    def _environment_target(self, env_vars: dict[str, str] | None) -> Target:
+        from dda.utils.go.build import Target
+
+        # Without overrides, the target is that of the environment
+        env_vars = env_vars or {}
+        return Target(
+            goos=env_vars.get("GOOS") or self.toolchain.env("GOOS"),
+            goarch=env_vars.get("GOARCH") or self.toolchain.env("GOARCH"),
+        )
+
+    def _race_env_vars(self, env_vars: dict[str, str] | None) -> dict[str, str]:
+        import os
+
+        from dda.utils.go.build import RACE_TARGETS, RaceUnsupportedError
+
+        env_vars = dict(env_vars or {})
+        target = self._environment_target(env_vars)
+        if target not in RACE_TARGETS:
+            raise RaceUnsupportedError(target)
+
+        if env_vars.get("CGO_ENABLED", os.environ.get("CGO_ENABLED")) == "0":
+            self.app.display_warning("Enabling cgo, which is required by the race detector")
+        env_vars["CGO_ENABLED"] = "1"
+
+        return env_vars
+
+    def _extra_args(self, extra_args: Iterable[str] | None) -> list[str]:
+        from dda.utils.go.build import validate_extra_args
+
+        try:
+            return validate_extra_args(extra_args or ())
+        except ValueError as e:
+            self.app.abort(str(e))
+
+    def _validate_toolchain(self, toolchain: str) -> str:
+        if toolchain == "local":
+            return toolchain
+
+        from dda.utils.go.version import parse_version
+
+        # The suffixes control whether the toolchain may switch to an even newer version
+        version, _, suffix = toolchain.partition("+")
+        try:
+            if not version.startswith("go") or suffix not in {"", "auto", "path"}:
+                raise ValueError(toolchain)
+
+            parse_version(version)
+        except ValueError:
+            self.app.abort(f"Invalid toolchain `{toolchain}`, expected `local` or a version like `go1.22.3`")
+
+        installed = self.toolchain.version()
+        if not installed.at_least(1, 21):
+            self.app.abort(f"Selecting the toolchain `{toolchain}` requires Go 1.21 or later, found {installed}")
+
+        return toolchain
+
+    def _pgo_profile(self, pgo: str, toolchain: str | None, cwd: str | PathLike | None) -> str:
+        from dda.utils.go.build import resolve_pgo_profile
+
+        try:
+            pgo = resolve_pgo_profile(pgo, cwd)
+        except ValueError as e:
+            self.app.abort(str(e))
+
+        if not pgo:
+            return pgo
+
+        version = self._effective_version(toolchain)
+        if version is not None and not version.at_least(1, 21):
+            self.app.abort(f"Profile-guided optimization requires Go 1.21 or later, found {version}")
+
+        return pgo
+
+    def _effective_version(self, toolchain: str | None) -> Version | None:
+        from dda.utils.go.version import parse_version
+
+        # The toolchain that runs a command may not be the one that is installed
+        try:
+            if toolchain is not None and toolchain != "local":
+                return parse_version(toolchain.partition("+")[0])
+            if toolchain is None and self.version:
+                return parse_version(self.version)
+        except ValueError:
+            # Invalid toolchains are reported when they are selected
+            return None
+
+        return self.toolchain.version()
+
+    def _input_digest(
+        self,
+        target: Target,
+        build_tags: set[str] | None,
+        command_parts: list[str],
+        env_vars: dict[str, str],
+        overlay_file: Path | None = None,
+    ) -> str | None:
+        import os
+
+        from dda.utils.go.build import input_digest, overlay_config
+        from dda.utils.go.constraints import BuildConstraintError, BuildContext
+        from dda.utils.go.modules import NoModuleError, detect_project_root, detect_workspace
+
+        try:
+            root = detect_project_root()
+        except NoModuleError:
+            return None
+
+        workspace = detect_workspace(Path(env_vars["GOWORK"]).parent) if env_vars.get("GOWORK") else detect_workspace()
+
+        cgo = env_vars.get("CGO_ENABLED") or os.environ.get("CGO_ENABLED") or self.toolchain.env("CGO_ENABLED")
+        context = BuildContext(
+            goos=target.goos,
+            goarch=target.goarch,
+            tags=frozenset(build_tags or ()),
+            cgo=cgo == "1",
+            go_version=self._effective_version(env_vars.get("GOTOOLCHAIN")),
+        )
+        # The output path and the flags that only affect logging or caching do not change the binary
+        config = [
+            part
+            for part in command_parts
+            if not part.startswith(("-o=", "-overlay=", "-pgo=")) and part not in {"-a", "-v", "-x"}
+        ]
+        for part in command_parts:
+            # Profiles are identified by their contents as they may be outside of the module
+            if part.startswith("-pgo="):
+                pgo = part.removeprefix("-pgo=")
+                config.append(f"-pgo={pgo if pgo in {'auto', 'off'} else Path(pgo).hexdigest()}")
+        # The location of the workspace differs across machines, unlike its contents
+        config.extend(f"{key}={value}" for key, value in sorted(env_vars.items()) if key != "GOWORK")
+        if overlay_file is not None:
+            # The overlay file is temporary so only the replacements it refers to are relevant
+            config.extend(overlay_config(overlay_file))
+        config.append(f"go{self.version}" if self.version else str(self.toolchain.version()))
+
+        try:
+            if workspace is not None:
+                # Imports of other modules of the workspace resolve to their local copies
+                config.append(f"go.work={workspace.file.hexdigest()}")
+                config.extend(
+                    f"use:{Path(os.path.relpath(module, workspace.directory)).as_posix()}="
+                    f"{input_digest(module, context, [])}"
+                    for module in workspace.modules
+                    if module != root
+                )
+
+            return input_digest(root, context, config)
+        except BuildConstraintError:
+            # Let the compiler report the error
+            return None
+
+    @contextmanager
+    def _output_lock(
+        self,
+        target: Target,
+        output_path: Path,
+        *,
+        lock_dir: str | PathLike | None,
+        blocking: bool,
+        timeout: float | None,
+        deadline: float | None,
+        cancel: Event | None,
+    ) -> Generator[str | None, None, None]:
+        if lock_dir is None:
+            yield None
+            return
+
+        from filelock import FileLock, Timeout
+
+        from dda.utils.go.build import output_lock_file
+
+        # Binaries of multiple packages are written into the output path when it is a directory
+        output_dir = output_path if output_path.is_dir() else output_path.parent
+        lock_file = output_lock_file(lock_dir, output_dir)
+        lock_file.parent.ensure_dir()
+        file_lock = FileLock(lock_file)
+        # Acquire the lock in short increments so that the deadline and cancellation are honored
+        while True:
+            try:
+                file_lock.acquire(timeout=_POLL_INTERVAL, blocking=blocking)
+                break
+            except Timeout:
+                if not blocking:
+                    yield f"The output directory of {target} is locked by another build: {output_dir}"
+                    return
+
+            if (reason := _stop_reason(target, timeout, deadline, cancel)) is not None:
+                yield reason
+                return
+
+        try:
+            yield None
+        finally:
+            file_lock.release()
+
+    @contextmanager
+    def _overlay_file(
+        self, overlay: Mapping[str | PathLike, str | bytes | PathLike | None] | None, cwd: str | PathLike | None = None
+    ) -> Generator[Path | None, None, None]:
+        if not overlay:
+            yield None
+            return
+
+        from dda.utils.fs import temp_directory
+        from dda.utils.go.build import write_overlay
+
+        with temp_directory() as temp_dir:
+            yield write_overlay(overlay, temp_dir, cwd)
+
+    @contextmanager
+    def _popen(
+        self,
+        command: list[str],
+        *,
+        env_vars: dict[str, str] | None = None,
+        cwd: str | PathLike | None = None,
+        **kwargs: Any,
+    ) -> Generator[subprocess.Popen, None, None]:
+        import subprocess
+
+        from dda.utils.process import EnvVars
+
+        with self.execution_context(command) as context:
+            env = EnvVars(env_vars)
+            for key, value in context.env_vars.items():
+                env.setdefault(key, value)
+
+            with self._observation(context.command, env, {}, cwd) as record_exit_code:
+                try:
+                    process = subprocess.Popen(context.command, env=env, cwd=cwd, **kwargs)
+                except FileNotFoundError:
+                    self.app.abort(f"Executable `{context.command[0]}` not found: {context.command}")
+
+                with process:
+                    try:
+                        yield process
+                    finally:
+                        if process.poll() is None:
+                            process.kill()
+
+                record_exit_code(process.wait())
+
+    def _command_spec(
+        self, command: list[str], env_vars: dict[str, str] | None, cwd: str | PathLike | None
+    ) -> CommandSpec:
+        import os
+
+        from dda.utils.go.observer import env_var_delta
+        from dda.utils.go.plan import CommandSpec
+        from dda.utils.process import EnvVars
+
+        with self.execution_context(command) as context:
+            return CommandSpec(
+                command=context.command,
+                env_vars=env_var_delta(EnvVars(env_vars), context.env_vars, ()),
+                cwd=os.path.abspath(os.getcwd() if cwd is None else cwd),
+            )
+
+    def _observed(self, call: Callable[..., Any], command: list[str], kwargs: dict[str, Any]) -> Any:
+        if not self.__observers:
+            return call(command, **kwargs)
+
+        from subprocess import CompletedProcess
+
+        with self.execution_context(command) as context:
+            full_command, overrides = context.command, context.env_vars
+
+        with self._observation(full_command, kwargs.get("env"), overrides, kwargs.get("cwd")) as record_exit_code:
+            try:
+                result = call(command, **kwargs)
+            except SystemExit as e:
+                # Failures of commands that are checked abort with the exit code of the command
+                if isinstance(e.code, int):
+                    record_exit_code(e.code)
+                raise
+
+            if isinstance(result, CompletedProcess):
+                record_exit_code(result.returncode)
+            elif isinstance(result, int):
+                record_exit_code(result)
+            # Capturing output aborts on failure unless told otherwise
+            elif kwargs.get("check", True):
+                record_exit_code(0)
+
+            return result
+
+    @contextmanager
+    def _observation(
+        self,
+        command: list[str],
+        env: Mapping[str, str] | None,
+        overrides: Mapping[str, str],
+        cwd: str | PathLike | None,
+    ) -> Generator[Callable[[int], None], None, None]:
+        if not (observers := self.__observers):
+            yield lambda _: None
+            return
+
+        import os
+        import time
+
+        from dda.utils.go.observer import Invocation, env_var_delta
+
+        invocation = Invocation(
+            command=list(command),
+            env_vars=env_var_delta(env, overrides, observers),
+            cwd=os.path.abspath(os.getcwd() if cwd is None else cwd),
+        )
+        for observer in observers:
+            observer.started(invocation)
+
+        exit_code: int | None = None
+
+        def record_exit_code(code: int) -> None:
+            nonlocal exit_code
+            exit_code = code
+
+        start = time.monotonic()
+        try:
+            yield record_exit_code
+        finally:
+            finished = Invocation(
+                command=invocation.command,
+                env_vars=invocation.env_vars,
+                cwd=invocation.cwd,
+                duration=time.monotonic() - start,
+                exit_code=exit_code,
+            )
+            for observer in observers:
+                observer.finished(finished)
+
+    def _build_flags(
+        self,
+        *,
+        output: str | PathLike,
+        build_tags: set[str] | None,
+        gcflags: Iterable[str] | None,
+        ldflags: Iterable[str] | None,
+        ldflags_vars: dict[str, str] | None,
+        force_rebuild: bool,
+        race: bool,
+        trace: bool = False,
+        mod: str = "readonly",
+        trimpath: bool = True,
+        pgo: str = "",
+    ) -> list[str]:
+        from dda.config.constants import Verbosity
+        from dda.utils.go.build import MOD_MODES, render_ldflags
+
+        if mod not in MOD_MODES:
+            self.app.abort(f"Invalid module mode `{mod}`, expected one of: {', '.join(sorted(MOD_MODES))}")
+
+        command_parts = []
+        if trimpath:
+            # Use trimmed paths instead of absolute file system paths # NOTE: This might not work with delve
+            command_parts.append("-trimpath")
+        # The `go` command chooses between `mod` and `vendor` when the flag is not set
+        if mod != "auto":
+            command_parts.append(f"-mod={mod}")
+        command_parts.append(f"-o={output}")
+
+        if force_rebuild:
+            command_parts.append("-a")
+
+        if race:
+            command_parts.append("-race")
+
+        if pgo:
+            command_parts.append(f"-pgo={pgo}")
+
+        if self.app.config.terminal.verbosity >= Verbosity.VERBOSE:
+            command_parts.append("-v")
+        if trace or self.app.config.terminal.verbosity >= Verbosity.DEBUG:
+            command_parts.append("-x")
+
+        if gcflags:
+            command_parts.append(f"-gcflags={' '.join(gcflags)}")
+        if ldflags or ldflags_vars:
+            command_parts.append(f"-ldflags={render_ldflags(ldflags, ldflags_vars)}")
+
+        if build_tags:
+            command_parts.extend(("-tags", f"{','.join(sorted(build_tags))}"))
+
+        return command_parts
+
+
+def _stop_reason(target: Target, timeout: float | None, deadline: float | None, cancel: Event | None) -> str | None:
+    import time
+
+    if cancel is not None and cancel.is_set():
+        return f"Build of {target} was cancelled"
+
+    if deadline is not None and time.monotonic() >= deadline:
+        return f"Build of {target} timed out after {timeout} seconds"
+
+    return None
+
+
+def _process_group_kwargs() -> dict[str, Any]:
+    import subprocess
+
+    from dda.utils.platform import PLATFORM_ID
+
+    if PLATFORM_ID == "windows":
+        return {"creationflags": subprocess.CREATE_NEW_PROCESS_GROUP}
+
+    return {"start_new_session": True}
+
+
+def _race_enabled(race: bool | None, target: Target) -> bool:
+    from dda.utils.go.build import RACE_TARGETS
+
+    # By default, the race detector is only enabled for the targets that support it
+    return target in RACE_TARGETS if race is None else race
+
+
+def _terminate_process_group(process: subprocess.Popen, grace_period: float = 5) -> None:
+    import subprocess
+
+    from dda.utils.platform import PLATFORM_ID
+
+    if PLATFORM_ID == "windows":
+        import psutil
+
+        try:
+            processes = [psutil.Process(process.pid), *psutil.Process(process.pid).children(recursive=True)]
+        except psutil.NoSuchProcess:
+            return
+
+        for child in processes:
+            try:
+                child.terminate()
+            except psutil.NoSuchProcess:
+                pass
+
+        _, alive = psutil.wait_procs(processes, timeout=grace_period)
+        for child in alive:
+            try:
+                child.kill()
+            except psutil.NoSuchProcess:
+                pass
+
+        return
+
+    import os
+    import signal
+
+    try:
+        os.killpg(process.pid, signal.SIGTERM)
+    except ProcessLookupError:
+        return
+
+    try:
+        process.wait(timeout=grace_period)
+    except subprocess.TimeoutExpired:
+        pass
+
+    # Children may outlive the parent after it exits so the whole group is always killed
+    try:
+        os.killpg(process.pid, signal.SIGKILL)
+    except ProcessLookupError:
+        pass
+
+
+_POLL_INTERVAL = 0.1
+# The replacements are written to a temporary file that the planned command could not refer to once it is removed
+_DRY_RUN_OVERLAY_ERROR = "Dry runs cannot be combined with overlays, which only exist for the duration of a build"
+
+
+def _chunk_arguments(args: list[str], max_length: int = 16384) -> Iterator[list[str]]:
+    # Keep command lines well below the limit of every platform, the lowest being 32767 characters on Windows
+    chunk: list[str] = []
+    length = 0
+    for arg in args:
+        if chunk and length + len(arg) + 1 > max_length:
+            yield chunk
+            chunk, length = [], 0
+
+        chunk.append(arg)
+        length += len(arg) + 1
+
+    if chunk:
+        yield chunk
@@ -0,0 +1,129 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct, field
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+
+
+class BenchmarkResult(Struct, frozen=True):
+    """
+    A single run of a benchmark, as reported by `go test -bench`. Running benchmarks several times with the
+    `-count` flag produces one result per run.
+    """
+
+    name: str
+    """The name of the benchmark, including sub-benchmarks separated by `/`, e.g. `BenchmarkEncode/small`."""
+    iterations: int
+    """The number of iterations that were timed."""
+    ns_per_op: float
+    """The time spent per iteration, in nanoseconds."""
+    bytes_per_op: float | None = None
+    """The number of bytes allocated per iteration, if memory statistics were requested."""
+    allocs_per_op: float | None = None
+    """The number of allocations per iteration, if memory statistics were requested."""
+    procs: int = 1
+    """The value of `GOMAXPROCS` during the run."""
+    package: str = ""
+    """The import path of the package containing the benchmark, if known."""
+    metrics: dict[str, float] = field(default_factory=dict)
+    """Other measurements keyed by unit, such as `MB/s` or those reported with `B.ReportMetric`."""
+
+    @property
+    def full_name(self) -> str:
+        """
+        The name as printed by `go test`, which has a `-N` suffix when `procs` is not 1.
+        """
+        return self.name if self.procs == 1 else f"{self.name}-{self.procs}"
+
+
+def parse_benchmark_output(output: str) -> list[BenchmarkResult]:
+    """
+    Parse the output of `go test -bench`. Lines that do not report a benchmark result, such as logs, are ignored.
+
+    The `-N` suffix of a name is interpreted as the value of `GOMAXPROCS`, as is done by
+    [benchstat](https://pkg.go.dev/golang.org/x/perf/cmd/benchstat).
+
+    Returns:
+        The results, in the order they were reported.
+    """
+    results: list[BenchmarkResult] = []
+    package = ""
+    for line in output.splitlines():
+        if line.startswith("pkg:"):
+            package = line.removeprefix("pkg:").strip()
+            continue
+
+        if (match := _RESULT_PATTERN.match(line)) is None:
+            continue
+
+        full_name, iterations, measurements = match.groups()
+        values = measurements.split()
+        # Measurements are pairs of values and units
+        if len(values) % 2:
+            continue
+
+        try:
+            metrics = {unit: float(value) for value, unit in zip(values[::2], values[1::2], strict=True)}
+        except ValueError:
+            continue
+
+        if "ns/op" not in metrics:
+            continue
+
+        name, procs = full_name, 1
+        if (procs_match := _PROCS_PATTERN.search(full_name)) is not None:
+            name, procs = full_name[: procs_match.start()], int(procs_match.group(1))
+
+        results.append(
+            BenchmarkResult(
+                name=name,
+                iterations=int(iterations),
+                ns_per_op=metrics.pop("ns/op"),
+                bytes_per_op=metrics.pop("B/op", None),
+                allocs_per_op=metrics.pop("allocs/op", None),
+                procs=procs,
+                package=package,
+                metrics=metrics,
+            )
+        )
+
+    return results
+
+
+def format_benchstat(results: Iterable[BenchmarkResult]) -> str:
+    """
+    Render results in the text format of `go test -bench` that is read by
+    [benchstat](https://pkg.go.dev/golang.org/x/perf/cmd/benchstat), for example to compare them with a baseline.
+    """
+    lines: list[str] = []
+    package = None
+    for result in results:
+        if result.package != package and result.package:
+            lines.append(f"pkg: {result.package}")
+        package = result.package
+
+        columns = [result.full_name, str(result.iterations), f"{_format_value(result.ns_per_op)} ns/op"]
+        columns.extend(f"{_format_value(value)} {unit}" for unit, value in result.metrics.items())
+        if result.bytes_per_op is not None:
+            columns.append(f"{_format_value(result.bytes_per_op)} B/op")
+        if result.allocs_per_op is not None:
+            columns.append(f"{_format_value(result.allocs_per_op)} allocs/op")
+
+        lines.append("\t".join(columns))
+
+    return "".join(f"{line}\n" for line in lines)
+
+
+def _format_value(value: float) -> str:
+    return str(int(value)) if value.is_integer() else repr(value)
+
+
+_RESULT_PATTERN = re.compile(r"^(Benchmark\S*)\s+(\d+)\s+(.+)$")
+_PROCS_PATTERN = re.compile(r"-(\d+)$")
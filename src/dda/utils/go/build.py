@@ -0,0 +1,724 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct, field
+
+from dda.utils.fs import Path
+from dda.utils.go.trace import Action  # noqa: TC001 - needed outside of typecheck for msgspec decode
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable, Mapping, Sequence
+    from os import PathLike
+
+    from dda.utils.go.constraints import BuildContext
+    from dda.utils.go.diagnostics import Diagnostic
+
+MOD_MODES = frozenset({"auto", "mod", "readonly", "vendor"})
+
+
+class RaceUnsupportedError(Exception):
+    """
+    Raised when the race detector is requested for a target that does not support it.
+
+    Parameters:
+        target: The target.
+    """
+
+    def __init__(self, target: Target) -> None:
+        super().__init__(target)
+
+        self.__target = target
+
+    @property
+    def target(self) -> Target:
+        return self.__target
+
+    def __str__(self) -> str:
+        return f"The race detector is not supported on {self.__target}"
+
+
+class BuildError(Exception):
+    """
+    Raised when a `go build` command fails.
+
+    Parameters:
+        exit_code: The exit code of the command.
+        stderr: The standard error of the command, which contains the compiler errors.
+        directory: The working directory of the command, against which relative file paths are resolved.
+    """
+
+    def __init__(self, exit_code: int, stderr: str, directory: str | PathLike[str] | None = None) -> None:
+        super().__init__(exit_code, stderr)
+
+        self.__exit_code = exit_code
+        self.__stderr = stderr
+        self.__directory = Path.cwd() if directory is None else Path(directory)
+
+    @property
+    def exit_code(self) -> int:
+        return self.__exit_code
+
+    @property
+    def stderr(self) -> str:
+        return self.__stderr
+
+    @property
+    def diagnostics(self) -> list[Diagnostic]:
+        """
+        The [compiler errors][dda.utils.go.diagnostics.parse_compiler_output], with file paths resolved against
+        the working directory of the build.
+        """
+        from dda.utils.go.diagnostics import parse_compiler_output
+
+        return parse_compiler_output(self.__stderr, self.__directory)
+
+    def __str__(self) -> str:
+        return f"Build failed with exit code {self.__exit_code}\n{self.__stderr}".rstrip()
+
+
+class Target(Struct, frozen=True):
+    """
+    A compilation target.
+    """
+
+    goos: str
+    """The target operating system, e.g. `linux`."""
+    goarch: str
+    """The target architecture, e.g. `amd64`."""
+
+    def __str__(self) -> str:
+        return f"{self.goos}/{self.goarch}"
+
+    @classmethod
+    def from_string(cls, target: str) -> Target:
+        """
+        Parse a target in the `GOOS/GOARCH` format used by `go tool dist list`.
+        """
+        goos, sep, goarch = target.strip().partition("/")
+        if not (goos and sep and goarch):
+            msg = f"Invalid target, expected `GOOS/GOARCH`: {target}"
+            raise ValueError(msg)
+
+        return cls(goos=goos, goarch=goarch)
+
+
+# Matches the `RaceDetectorSupported` function of `internal/platform`
+RACE_TARGETS = frozenset({
+    Target("darwin", "amd64"),
+    Target("darwin", "arm64"),
+    Target("freebsd", "amd64"),
+    Target("linux", "amd64"),
+    Target("linux", "arm64"),
+    Target("linux", "loong64"),
+    Target("linux", "ppc64le"),
+    Target("linux", "riscv64"),
+    Target("linux", "s390x"),
+    Target("netbsd", "amd64"),
+    Target("windows", "amd64"),
+})
+
+
+class BuildResult(Struct, frozen=True):
+    """
+    The outcome of building a single target.
+    """
+
+    target: Target
+    """The target that was built."""
+    output: Path
+    """The path to the output binary."""
+    duration: float = 0
+    """The time spent building, in seconds."""
+    stderr: str = ""
+    """The output of the `go build` command."""
+    error: str | None = None
+    """A description of the failure, or `None` if the build succeeded."""
+    cached: bool = False
+    """Whether the build was skipped because none of its inputs changed since the output was produced."""
+    actions: tuple[Action, ...] = ()
+    """The commands run by the build, when tracing is enabled."""
+    attempts: int = 1
+    """The number of times the build ran, which is greater than 1 if transient failures were retried."""
+    warnings: tuple[str, ...] = ()
+    """Problems that did not prevent the build, such as conflicts with the `GOFLAGS` environment variable."""
+    size: int = 0
+    """The size of the binary in bytes, or the combined size of the binaries if the output is a directory, which is
+    0 if the build failed."""
+    sections: dict[str, int] = field(default_factory=dict)
+    """The combined size of the symbols of each [kind][dda.utils.go.build.parse_symbol_sizes], such as `text` or
+    `rodata`, when size reporting is enabled."""
+
+    @property
+    def succeeded(self) -> bool:
+        return self.error is None
+
+
+class MatrixReport(Struct, frozen=True):
+    """
+    The outcome of [validating][dda.tools.go.Go.build_matrix] that packages build for several targets.
+    """
+
+    results: tuple[BuildResult, ...]
+    """The result of each build, in the same order as the targets."""
+    duration: float = 0
+    """The time spent building all targets, in seconds."""
+
+    @property
+    def all_passed(self) -> bool:
+        return all(result.succeeded for result in self.results)
+
+    @property
+    def failures(self) -> list[BuildResult]:
+        """
+        The results of the targets that failed to build, whose `stderr` contains the output of the compiler.
+        """
+        return [result for result in self.results if not result.succeeded]
+
+    @property
+    def sizes(self) -> dict[str, int]:
+        """
+        The [size][dda.utils.go.build.BuildResult.size] of the binaries of each target that built, keyed by target
+        in the `GOOS/GOARCH` format, which may be stored and later used as the baseline of
+        [`size_regressions`][dda.utils.go.build.size_regressions].
+        """
+        return {str(result.target): result.size for result in self.results if result.succeeded}
+
+
+class ReproducibilityReport(Struct, frozen=True):
+    """
+    The outcome of [verifying][dda.tools.go.Go.verify_reproducible] that building the same sources twice produces
+    identical binaries.
+    """
+
+    digests: tuple[str, str]
+    """The SHA-256 hash of each binary."""
+    differences: tuple[tuple[int, int], ...] = ()
+    """The [ranges of bytes][dda.utils.go.build.diff_binaries] that differ, as start and end offsets."""
+
+    @property
+    def reproducible(self) -> bool:
+        return self.digests[0] == self.digests[1]
+
+
+class SizeChange(Struct, frozen=True):
+    """
+    A difference in the size of the binaries of a target relative to a baseline.
+    """
+
+    target: str
+    """The target, in the `GOOS/GOARCH` format."""
+    baseline: int
+    """The size of the baseline, in bytes."""
+    size: int
+    """The new size, in bytes."""
+
+    @property
+    def growth(self) -> int:
+        """The number of bytes gained, which is negative if the binaries shrank."""
+        return self.size - self.baseline
+
+    @property
+    def ratio(self) -> float:
+        """The growth relative to the baseline, such as `0.05` for 5%, which is infinite if the baseline is empty."""
+        if not self.baseline:
+            return float("inf") if self.size else 0.0
+
+        return self.growth / self.baseline
+
+
+class RetryPolicy(Struct, frozen=True):
+    """
+    How builds that fail due to [transient errors][dda.utils.go.build.is_transient_error] are retried. The delays
+    between attempts are determined by [`backoff_delays`][dda.utils.retry.backoff_delays].
+    """
+
+    max_attempts: int = 3
+    """The maximum number of times a build runs, including the first attempt."""
+    min_delay: float = 1
+    """The minimum number of seconds to wait before retrying."""
+    max_delay: float = 30
+    """The maximum number of seconds to wait before retrying."""
+
+
+def is_transient_error(output: str) -> bool:
+    """
+    Determine whether the output of a failed `go` command indicates a failure that may not happen again, such as
+    a network error while downloading modules, a server error from the module proxy or a timeout of the checksum
+    database, as opposed to failures that would happen again such as compilation errors.
+    """
+    return any(pattern.search(output) for pattern in _TRANSIENT_PATTERNS)
+
+
+def render_ldflags(ldflags: Iterable[str] | None = None, ldflags_vars: Mapping[str, str] | None = None) -> str:
+    """
+    Render the value of the `-ldflags` flag.
+
+    Parameters:
+        ldflags: Raw linker flags, which are kept as-is.
+        ldflags_vars: A mapping of fully qualified variable names, like `main.Version`, to the string values set
+            with the linker's `-X` flag. Values containing spaces or quotes are quoted so that the `go`
+            command parses them as a single argument.
+    """
+    parts = list(ldflags or ())
+    parts.extend(_quote_ldflag(f"-X={name}={value}") for name, value in sorted((ldflags_vars or {}).items()))
+    return " ".join(parts)
+
+
+def validate_extra_args(args: Iterable[str]) -> list[str]:
+    """
+    Check flags that are passed verbatim to the `go` command after those that are managed, and before the
+    package patterns. Whether the flags conflict with the managed flags is not checked, but arguments that would
+    change how the rest of the command line is interpreted are rejected:
+
+    - a leading argument that is not a flag, which would be a different subcommand or a package
+    - an argument that is not a flag and does not follow a flag without a value, which would be a package
+    - `--` and `-args`, which would cause the package patterns to be ignored or passed to the test binary
+
+    Returns:
+        The arguments.
+
+    Raises:
+        ValueError: If an argument is rejected.
+    """
+    args = list(args)
+    # Flags may have their value in the next argument, such as `-gcflags all=-N`
+    may_be_value = False
+    for arg in args:
+        if arg in {"--", "-args", "--args"}:
+            msg = f"Extra argument `{arg}` would change how the package patterns are interpreted"
+            raise ValueError(msg)
+
+        if arg.startswith("-"):
+            may_be_value = "=" not in arg
+        elif may_be_value:
+            may_be_value = False
+        else:
+            msg = f"Extra argument `{arg}` is not a flag"
+            raise ValueError(msg)
+
+    return args
+
+
+def resolve_build_files(
+    files: Iterable[str | PathLike[str]], directory: str | PathLike[str] | None = None
+) -> list[Path]:
+    """
+    Validate files that are built as a single package, rather than a package directory, in the same way as the
+    `go` command. Build constraints are not applied to such files.
+
+    Parameters:
+        files: The paths to the files.
+        directory: The directory against which relative paths are resolved, defaulting to the current working
+            directory.
+
+    Returns:
+        The absolute paths to the files.
+
+    Raises:
+        ValueError: If there are no files, a file is not a Go source file or does not exist, or the files are not
+            all in the same directory.
+    """
+    directory = Path.cwd() if directory is None else Path(directory)
+    paths = [(directory / file).resolve() for file in files]
+    if not paths:
+        msg = "No files to build"
+        raise ValueError(msg)
+
+    for path in paths:
+        if path.suffix != ".go":
+            msg = f"Named files must be Go source files: {path}"
+            raise ValueError(msg)
+
+        if not path.is_file():
+            msg = f"File does not exist: {path}"
+            raise ValueError(msg)
+
+    if len(directories := sorted({str(path.parent) for path in paths})) > 1:
+        msg = f"Named files must all be in the same directory, found: {', '.join(directories)}"
+        raise ValueError(msg)
+
+    return paths
+
+
+def resolve_pgo_profile(pgo: str, directory: str | PathLike[str] | None = None) -> str:
+    """
+    Determine the value of the `-pgo` flag for
+    [profile-guided optimization](https://go.dev/doc/pgo).
+
+    Parameters:
+        pgo: Either an empty string to not set the flag, in which case the `go` command uses the `default.pgo`
+            file in the directory of the main package if there is one, `auto` to do so explicitly, `off` to
+            disable the optimization, or the path to a profile.
+        directory: The directory against which a relative path is resolved, defaulting to the current working
+            directory.
+
+    Returns:
+        The value of the flag, which is an absolute path for profiles, or an empty string if the flag is not set.
+
+    Raises:
+        ValueError: If the profile does not exist.
+    """
+    if pgo in {"", "auto", "off"}:
+        return pgo
+
+    path = ((Path.cwd() if directory is None else Path(directory)) / pgo).resolve()
+    if not path.is_file():
+        msg = f"PGO profile does not exist: {path}"
+        raise ValueError(msg)
+
+    return str(path)
+
+
+def goflags_conflicts(goflags: Iterable[str], args: Sequence[str]) -> list[str]:
+    """
+    Find the flags of the `GOFLAGS` environment variable that are overridden by flags of a command with a
+    different value, since flags on the command line take precedence. Flags without a value are considered
+    to be boolean flags set to `true`.
+
+    Parameters:
+        goflags: The [flags][dda.utils.go.toolchain.split_goflags] of the `GOFLAGS` environment variable.
+        args: The arguments of the command, after the subcommand.
+
+    Returns:
+        A description of each conflict.
+    """
+    conflicts: list[str] = []
+    for goflag in goflags:
+        name, sep, value = goflag.lstrip("-").partition("=")
+        if not sep:
+            value = "true"
+
+        for index, arg in enumerate(args):
+            arg_name, arg_sep, arg_value = arg.lstrip("-").partition("=")
+            if not arg.startswith("-") or arg_name != name:
+                continue
+
+            if not arg_sep:
+                # The value of a flag that is not boolean may be the next argument, such as `-tags a,b`
+                following = args[index + 1] if index + 1 < len(args) else ""
+                if value not in {"true", "false"} and following and not following.startswith("-"):
+                    arg_value = following
+                else:
+                    arg_value = "true"
+
+            if arg_value != value:
+                conflicts.append(
+                    f"GOFLAGS sets `-{name}={value}` but the command sets `-{name}={arg_value}`, which takes precedence"
+                )
+
+    return conflicts
+
+
+def version_stamp(commit: str, date: str) -> dict[str, str]:
+    """
+    Returns:
+        The `main.Commit` and `main.BuildDate` variables to stamp a binary with, suitable for the `ldflags_vars`
+        parameter of [`Go.build`][dda.tools.go.Go.build].
+    """
+    return {"main.Commit": commit, "main.BuildDate": date}
+
+
+def input_digest(root: str | PathLike[str], context: BuildContext, config: Iterable[str]) -> str:
+    """
+    Compute a hash of everything that affects the outcome of a build: the module files, the source files of every
+    package in the module that are [compiled][dda.utils.go.packages.source_files] for the given context, the
+    files they [embed][dda.utils.go.packages.embed_files], the `default.pgo` profiles and the configuration. The
+    packages of the directories that modules are [replaced][dda.utils.go.modules.local_replacements] by are
+    hashed in the same way. Only paths relative to the root are hashed and files are visited in a sorted order so
+    that the result is the same across machines. Adding or removing a file that matches an embed pattern therefore
+    changes the result.
+
+    Parameters:
+        root: The root directory of the module.
+        context: The target configuration, which determines the source files that are hashed.
+        config: Anything else that affects the build, such as flags and environment variables, in a
+            deterministic order.
+
+    Raises:
+        BuildConstraintError: If the build constraints of a file are malformed.
+    """
+    import hashlib
+    import os
+
+    from dda.utils.go.modules import local_replacements
+
+    root = Path(root)
+    files = _module_input_files(root, context)
+    if (root / "go.mod").is_file():
+        for replacement in local_replacements(root):
+            files.extend(_module_input_files(replacement, context))
+
+    digester = hashlib.sha256()
+    for entry in config:
+        digester.update(entry.encode("utf-8"))
+        digester.update(b"\0")
+
+    for path in files:
+        # Replacements may be outside of the module
+        digester.update(Path(os.path.relpath(path, root)).as_posix().encode("utf-8"))
+        digester.update(b"\0")
+        digester.update(Path(path).hexdigest().encode("utf-8"))
+        digester.update(b"\0")
+
+    return digester.hexdigest()
+
+
+def output_size(output: str | PathLike[str]) -> int:
+    """
+    Returns:
+        The size of a binary in bytes, or the combined size of the files directly in a directory, which is how
+        binaries are output when building several packages at once. The size is 0 if there is no output.
+    """
+    import os
+
+    try:
+        if not os.path.isdir(output):
+            return os.path.getsize(output)
+
+        with os.scandir(output) as entries:
+            return sum(entry.stat().st_size for entry in entries if entry.is_file())
+    except FileNotFoundError:
+        return 0
+
+
+def parse_symbol_sizes(output: str) -> dict[str, int]:
+    """
+    Parse the output of `go tool nm -size`, which works for binaries of every target, into the combined size of
+    the symbols of each kind: `text` for code, `rodata` for read-only data such as type metadata and string
+    literals, `data` for initialized variables, `bss` for zero-initialized variables, and `other` for everything
+    else. Data that is not attributed to symbols, such as headers and debugging information, is not included.
+
+    Returns:
+        The size of each kind of symbol that is present, in bytes.
+    """
+    sizes: dict[str, int] = {}
+    for line in output.splitlines():
+        if (match := _SYMBOL_PATTERN.match(line)) is None or not (size := int(match.group(1))):
+            continue
+
+        kind = _SYMBOL_KINDS.get(match.group(2).upper(), "other")
+        sizes[kind] = sizes.get(kind, 0) + size
+
+    return sizes
+
+
+def size_regressions(
+    baseline: Mapping[str, int], sizes: Mapping[str, int], *, threshold: float = 0
+) -> list[SizeChange]:
+    """
+    Find the targets whose binaries grew beyond a threshold, such as to fail a release when the
+    [sizes][dda.utils.go.build.MatrixReport.sizes] of a matrix build exceed those of the previous release. Targets
+    that are missing from either mapping are ignored.
+
+    Example usage:
+
+    ```python
+    for change in size_regressions(baseline, report.sizes, threshold=0.05):
+        app.display_error(f"{change.target} grew by {change.growth} bytes ({change.ratio:.1%})")
+    ```
+
+    Parameters:
+        baseline: The previous size of each target, in bytes.
+        sizes: The new size of each target, in bytes.
+        threshold: The growth relative to the baseline that is tolerated, such as `0.05` for 5%.
+
+    Returns:
+        The changes of the targets that grew beyond the threshold, sorted by target.
+    """
+    return [
+        SizeChange(target=target, baseline=baseline[target], size=size)
+        for target, size in sorted(sizes.items())
+        if target in baseline and size > baseline[target] * (1 + threshold)
+    ]
+
+
+def diff_binaries(
+    first: str | PathLike[str], second: str | PathLike[str], *, limit: int | None = 100
+) -> list[tuple[int, int]]:
+    """
+    Find where two files differ, reading them in chunks rather than in their entirety. If one file is longer than
+    the other, its remaining bytes are reported as a final range.
+
+    Parameters:
+        first: The path to the first file.
+        second: The path to the second file.
+        limit: The maximum number of ranges to report, or `None` to report all of them.
+
+    Returns:
+        The ranges of bytes that differ, as start and end offsets where the end is exclusive.
+    """
+    ranges: list[tuple[int, int]] = []
+    start: int | None = None
+    offset = 0
+    with open(first, "rb") as f1, open(second, "rb") as f2:
+        while True:
+            chunk1 = f1.read(_CHUNK_SIZE)
+            chunk2 = f2.read(_CHUNK_SIZE)
+            if not chunk1 and not chunk2:
+                break
+
+            # Most chunks are identical, so only compare individual bytes of those that are not
+            if chunk1 == chunk2:
+                if start is not None:
+                    ranges.append((start, offset))
+                    start = None
+                offset += len(chunk1)
+            else:
+                for byte1, byte2 in zip(chunk1, chunk2, strict=False):
+                    if byte1 != byte2:
+                        if start is None:
+                            start = offset
+                    elif start is not None:
+                        ranges.append((start, offset))
+                        start = None
+                    offset += 1
+
+                if len(chunk1) != len(chunk2):
+                    rest = abs(len(chunk1) - len(chunk2))
+                    rest += len((f1 if len(chunk1) > len(chunk2) else f2).read())
+                    ranges.append((offset if start is None else start, offset + rest))
+                    start = None
+                    break
+
+            if limit is not None and len(ranges) >= limit:
+                break
+
+    if start is not None:
+        ranges.append((start, offset))
+
+    return ranges if limit is None else ranges[:limit]
+
+
+def write_overlay(
+    overlay: Mapping[str | PathLike[str], str | bytes | PathLike[str] | None],
+    directory: str | PathLike[str],
+    cwd: str | PathLike[str] | None = None,
+) -> Path:
+    """
+    Write the [overlay file](https://pkg.go.dev/cmd/go#hdr-Compile_packages_and_dependencies) expected by the `-overlay`
+    flag of the `go` command. Replacements given as contents are written to files within the directory.
+
+    Parameters:
+        overlay: A mapping of the paths to the original files to either the contents of the replacement, the path
+            to a replacement file, or `None` to treat the file as deleted.
+        directory: The directory in which to write the overlay file and the contents of the replacements.
+        cwd: The directory against which relative paths are resolved, defaulting to the current working directory.
+
+    Returns:
+        The path to the overlay file.
+    """
+    import json
+
+    directory = Path(directory)
+    cwd = Path.cwd() if cwd is None else Path(cwd).absolute()
+    replace: dict[str, str] = {}
+    for index, (original, replacement) in enumerate(overlay.items()):
+        original_path = cwd / original
+        if replacement is None:
+            replace[str(original_path)] = ""
+        elif isinstance(replacement, str | bytes):
+            # Keep the original name so that compiler errors refer to a recognizable file
+            replacement_path = directory / str(index) / original_path.name
+            replacement_path.parent.ensure_dir()
+            if isinstance(replacement, str):
+                replacement_path.write_text(replacement, encoding="utf-8")
+            else:
+                replacement_path.write_bytes(replacement)
+
+            replace[str(original_path)] = str(replacement_path)
+        else:
+            replace[str(original_path)] = str(cwd / replacement)
+
+    overlay_file = directory / "overlay.json"
+    overlay_file.write_text(json.dumps({"Replace": replace}, indent=2), encoding="utf-8")
+    return overlay_file
+
+
+def overlay_config(overlay_file: str | PathLike[str]) -> list[str]:
+    """
+    Describe the replacements of an overlay file by their contents rather than their location, for use as part of
+    the configuration of an [input digest][dda.utils.go.build.input_digest].
+    """
+    import json
+
+    replace: dict[str, str] = json.loads(Path(overlay_file).read_text(encoding="utf-8")).get("Replace", {})
+    return [
+        f"overlay:{original}={Path(replacement).hexdigest() if replacement else ''}"
+        for original, replacement in sorted(replace.items())
+    ]
+
+
+def output_lock_file(lock_dir: str | PathLike[str], output_dir: str | PathLike[str]) -> Path:
+    """
+    Get the lock file guarding an output directory. Every path to the same directory maps to the same file, so that
+    builds writing to it from different processes can be serialized.
+    """
+    import hashlib
+
+    key = hashlib.sha256(str(Path(output_dir).resolve()).encode("utf-8")).hexdigest()
+    return Path(lock_dir) / f"{key}.lock"
+
+
+def _module_input_files(root: Path, context: BuildContext) -> list[Path]:
+    import os
+
+    from dda.utils.go.packages import embed_files, parse_embed_patterns, source_files
+
+    files = [root / name for name in _MODULE_FILES if (root / name).is_file()]
+    for directory, dirs, _ in os.walk(root):
+        # Directories ignored by the `go` command
+        dirs[:] = sorted(d for d in dirs if not d.startswith((".", "_")) and d != "testdata")
+        package_files = source_files(directory, context)
+        files.extend(package_files)
+        # Embedded files may be anywhere within the package, including directories that are otherwise ignored
+        patterns = [
+            pattern
+            for path in package_files
+            if path.suffix == ".go"
+            for pattern in parse_embed_patterns(path.read_text(encoding="utf-8", errors="replace"))
+        ]
+        files.extend(path for path in embed_files(directory, patterns) if path not in package_files)
+        # Profiles are used automatically when they are next to a main package
+        if (profile := Path(directory, "default.pgo")).is_file():
+            files.append(profile)
+
+    return files
+
+
+def _quote_ldflag(arg: str) -> str:
+    # https://github.com/golang/go/blob/master/src/cmd/internal/quoted/quoted.go
+    if not any(c.isspace() or c in "'\"" for c in arg):
+        return arg
+
+    if "'" not in arg:
+        return f"'{arg}'"
+
+    if '"' not in arg:
+        return f'"{arg}"'
+
+    msg = f"Linker flag cannot contain both single and double quotes: {arg}"
+    raise ValueError(msg)
+
+
+_TRANSIENT_PATTERNS = (
+    re.compile(r"\bdial tcp\b"),
+    re.compile(r"\bi/o timeout\b"),
+    re.compile(r"\bconnection (?:reset by peer|refused)\b"),
+    re.compile(r"\bTLS handshake timeout\b"),
+    re.compile(r"\btemporary failure in name resolution\b", re.IGNORECASE),
+    re.compile(r"\bcontext deadline exceeded\b"),
+    re.compile(r"\bClient\.Timeout exceeded\b"),
+    # The proxy protocol and checksum database report server errors with the URL being fetched, e.g.
+    # `reading https://proxy.golang.org/example.com/foo/@v/list: 502 Bad Gateway`
+    re.compile(r"\breading https?://\S+: 5\d\d\b"),
+)
+_MODULE_FILES = ("go.mod", "go.sum", "go.work", "go.work.sum", "vendor/modules.txt")
+# Symbols are printed as `ADDRESS SIZE TYPE NAME` where the address is omitted for undefined symbols
+_SYMBOL_PATTERN = re.compile(r"^\s*(?:[0-9a-f]+\s+)?(\d+)\s+(\S)\s+\S")
+_SYMBOL_KINDS = {"T": "text", "R": "rodata", "D": "data", "B": "bss"}
+_CHUNK_SIZE = 65536
@@ -0,0 +1,118 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from collections.abc import Iterator
+    from os import PathLike
+
+
+class CacheStats(Struct, frozen=True):
+    """
+    The disk usage of the [build cache](https://pkg.go.dev/cmd/go#hdr-Build_and_test_caching).
+    """
+
+    directory: str
+    """The path to the cache, as reported by `go env GOCACHE`."""
+    size: int = 0
+    """The combined size of every entry, in bytes."""
+    entries: int = 0
+    """The number of entries, each of which is either the metadata of an action or one of its outputs."""
+
+
+def scan_cache(directory: str | PathLike[str]) -> CacheStats:
+    """
+    Measure a build cache without reading the contents of any entry. Files that are not entries, such as the
+    `README` or the trim timestamp, are ignored.
+
+    Returns:
+        The statistics of the cache, which are empty if the directory does not exist.
+    """
+    import os
+
+    size = entries = 0
+    for _, entry_size, _ in _iter_entries(directory):
+        size += entry_size
+        entries += 1
+
+    return CacheStats(directory=os.fspath(directory), size=size, entries=entries)
+
+
+def trim_cache(directory: str | PathLike[str], max_size: int) -> CacheStats:
+    """
+    Remove the least recently used entries of a build cache until its size does not exceed a budget. The `go`
+    command refreshes the modification time of an entry whenever it is used, at most once per hour, which is
+    what recency is based on.
+
+    Removing only some of the entries of an action is safe, the `go` command treats it as a cache miss.
+
+    Parameters:
+        directory: The path to the cache.
+        max_size: The maximum size of the cache after trimming, in bytes.
+
+    Returns:
+        The statistics of the entries that were removed.
+    """
+    import os
+
+    entries = list(_iter_entries(directory))
+    size = sum(entry_size for _, entry_size, _ in entries)
+    # Sort by modification time only, paths are not comparable with each other in a meaningful way
+    entries.sort(key=lambda entry: entry[2])
+
+    removed_size = removed_entries = 0
+    for path, entry_size, _ in entries:
+        if size <= max_size:
+            break
+
+        try:
+            os.remove(path)
+        except FileNotFoundError:
+            # Removed concurrently, for example by another trim or `go clean -cache`
+            pass
+        except OSError:
+            continue
+        else:
+            removed_size += entry_size
+            removed_entries += 1
+
+        size -= entry_size
+
+    return CacheStats(directory=os.fspath(directory), size=removed_size, entries=removed_entries)
+
+
+def _iter_entries(directory: str | PathLike[str]) -> Iterator[tuple[str, int, float]]:
+    import os
+
+    try:
+        subdirectories = list(os.scandir(directory))
+    except FileNotFoundError:
+        return
+
+    for subdirectory in subdirectories:
+        # Entries are sharded by the first byte of their hash into directories named `00` through `ff`
+        if len(subdirectory.name) != 2 or not subdirectory.is_dir(follow_symlinks=False):  # noqa: PLR2004
+            continue
+
+        try:
+            files = os.scandir(subdirectory.path)
+        except FileNotFoundError:
+            continue
+
+        with files:
+            for file in files:
+                # Action metadata has an `-a` suffix and outputs have a `-d` suffix
+                if not file.name.endswith(("-a", "-d")):
+                    continue
+
+                try:
+                    stat = file.stat(follow_symlinks=False)
+                except FileNotFoundError:
+                    continue
+
+                yield file.path, stat.st_size, stat.st_mtime
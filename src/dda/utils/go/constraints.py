@@ -0,0 +1,426 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from functools import cached_property
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+from dda.utils.go.constants import KNOWN_ARCH, KNOWN_OS, UNIX_OS
+from dda.utils.go.version import Version
+
+if TYPE_CHECKING:
+    from collections.abc import Callable, Iterator
+    from os import PathLike
+
+
+class BuildConstraintError(ValueError):
+    """
+    Raised when the build constraints of a file cannot be parsed or are inconsistent.
+    """
+
+
+class BuildContext(Struct, frozen=True):
+    """
+    The target configuration against which [build constraints](https://pkg.go.dev/cmd/go#hdr-Build_constraints)
+    are evaluated.
+    """
+
+    goos: str
+    """The target operating system, e.g. `linux`."""
+    goarch: str
+    """The target architecture, e.g. `amd64`."""
+    tags: frozenset[str] = frozenset()
+    """Extra build tags that are considered satisfied, as passed to the `-tags` flag."""
+    cgo: bool = False
+    """Whether cgo is enabled, which satisfies the `cgo` tag."""
+    compiler: str = "gc"
+    """The compiler in use, which satisfies the tag of the same name."""
+    go_version: Version | None = None
+    """
+    The version of the toolchain, which satisfies the release tags of every minor version up to its own, such as
+    `go1.21` for Go 1.22. Release tags are never satisfied if this is not set.
+    """
+
+    def matches(self, tag: str) -> bool:
+        """
+        Whether the given tag is satisfied by this context, following the same rules as the
+        [`go/build`](https://pkg.go.dev/go/build) package.
+        """
+        if tag in {self.goos, self.goarch, self.compiler} or tag in self.tags:
+            return True
+
+        if tag == "unix":
+            return self.goos in UNIX_OS
+
+        if tag == "cgo":
+            return self.cgo
+
+        if match := _RELEASE_TAG_PATTERN.match(tag):
+            return self.go_version is not None and self.go_version.at_least(1, int(match.group(1)))
+
+        # Some operating systems are supersets of others
+        return (
+            (tag == "linux" and self.goos == "android")
+            or (tag == "solaris" and self.goos == "illumos")
+            or (tag == "darwin" and self.goos == "ios")
+        )
+
+
+class Expr(Struct, frozen=True):
+    """
+    A node of a build constraint expression tree.
+    """
+
+    def evaluate(self, ok: Callable[[str], bool]) -> bool:
+        """
+        Evaluate the expression, using the given callable to decide whether each tag is satisfied.
+        """
+        raise NotImplementedError
+
+    def walk(self) -> Iterator[Expr]:
+        yield self
+
+
+class TagExpr(Expr, frozen=True):
+    """A single build tag, e.g. `linux`."""
+
+    tag: str
+
+    def evaluate(self, ok: Callable[[str], bool]) -> bool:
+        return ok(self.tag)
+
+    def __str__(self) -> str:
+        return self.tag
+
+
+class NotExpr(Expr, frozen=True):
+    """The negation of an expression, e.g. `!debug`."""
+
+    x: Expr
+
+    def evaluate(self, ok: Callable[[str], bool]) -> bool:
+        return not self.x.evaluate(ok)
+
+    def walk(self) -> Iterator[Expr]:
+        yield self
+        yield from self.x.walk()
+
+    def __str__(self) -> str:
+        if isinstance(self.x, TagExpr | NotExpr):
+            return f"!{self.x}"
+
+        return f"!({self.x})"
+
+
+class AndExpr(Expr, frozen=True):
+    """The conjunction of two expressions, e.g. `linux && amd64`."""
+
+    x: Expr
+    y: Expr
+
+    def evaluate(self, ok: Callable[[str], bool]) -> bool:
+        # Evaluate both sides so that every tag is consulted, like the Go implementation
+        x = self.x.evaluate(ok)
+        y = self.y.evaluate(ok)
+        return x and y
+
+    def walk(self) -> Iterator[Expr]:
+        yield self
+        yield from self.x.walk()
+        yield from self.y.walk()
+
+    def __str__(self) -> str:
+        return f"{_wrap(self.x, OrExpr)} && {_wrap(self.y, OrExpr)}"
+
+
+class OrExpr(Expr, frozen=True):
+    """The disjunction of two expressions, e.g. `linux || darwin`."""
+
+    x: Expr
+    y: Expr
+
+    def evaluate(self, ok: Callable[[str], bool]) -> bool:
+        x = self.x.evaluate(ok)
+        y = self.y.evaluate(ok)
+        return x or y
+
+    def walk(self) -> Iterator[Expr]:
+        yield self
+        yield from self.x.walk()
+        yield from self.y.walk()
+
+    def __str__(self) -> str:
+        return f"{_wrap(self.x, AndExpr)} || {_wrap(self.y, AndExpr)}"
+
+
+class BuildConstraint(Struct, frozen=True, dict=True):
+    """
+    The build constraints of a single Go source file.
+    """
+
+    expr: Expr | None
+    """
+    The effective expression, or `None` if the file is unconstrained. The `//go:build` line takes precedence
+    over legacy `// +build` lines, as with the `go` command.
+    """
+    go_build: Expr | None = None
+    """The expression of the `//go:build` line, if any."""
+    plus_build: Expr | None = None
+    """The conjunction of all legacy `// +build` lines, if any."""
+
+    @cached_property
+    def tags(self) -> frozenset[str]:
+        """All tags referenced by the constraints."""
+        return _tags(self.expr)
+
+    def evaluate(self, context: BuildContext) -> bool:
+        """
+        Whether the file would be included when building for the given context.
+        """
+        return self.expr is None or self.expr.evaluate(context.matches)
+
+
+def matches_filename(filename: str, context: BuildContext) -> bool:
+    """
+    Whether a file would be included when building for the given context based solely on the `_GOOS`, `_GOARCH`
+    and `_GOOS_GOARCH` suffixes of its name, such as `foo_linux.go` or `foo_windows_amd64_test.go`. Suffixes that
+    are not known operating systems or architectures impose no constraint, as with the `go` command.
+    """
+    # https://github.com/golang/go/blob/master/src/go/build/build.go (goodOSArchFile)
+    name = filename.partition(".")[0]
+    # Everything before the first underscore is ignored so that files like `linux.go` are not constrained
+    _, sep, suffix = name.partition("_")
+    if not sep:
+        return True
+
+    parts = suffix.split("_")
+    if parts[-1] == "test":
+        parts.pop()
+
+    if len(parts) >= 2 and parts[-2] in KNOWN_OS and parts[-1] in KNOWN_ARCH:  # noqa: PLR2004
+        return context.matches(parts[-2]) and context.matches(parts[-1])
+
+    if parts and (parts[-1] in KNOWN_OS or parts[-1] in KNOWN_ARCH):
+        return context.matches(parts[-1])
+
+    return True
+
+
+def parse_build_constraints(path: str | PathLike[str]) -> BuildConstraint:
+    """
+    Parse the build constraints from the header of a Go source file. Both the `//go:build` form and the
+    deprecated `// +build` form are supported.
+
+    Parameters:
+        path: The path to the Go source file.
+
+    Raises:
+        BuildConstraintError: If a constraint is malformed, there are multiple `//go:build` lines, or the
+            `//go:build` and `// +build` lines disagree.
+    """
+    from dda.utils.fs import Path
+
+    try:
+        return parse_build_constraints_from_source(Path(path).read_text(encoding="utf-8"))
+    except BuildConstraintError as e:
+        msg = f"{path}: {e}"
+        raise BuildConstraintError(msg) from None
+
+
+def parse_build_constraints_from_source(source: str) -> BuildConstraint:
+    """
+    Equivalent to [`parse_build_constraints`][dda.utils.go.constraints.parse_build_constraints] but for
+    the contents of a Go source file.
+    """
+    go_build_lines: list[str] = []
+    plus_build_lines: list[str] = []
+    for line in _header_constraint_lines(source):
+        if _GO_BUILD_PATTERN.match(line):
+            go_build_lines.append(line)
+        else:
+            plus_build_lines.append(line)
+
+    if len(go_build_lines) > 1:
+        msg = "multiple //go:build comments"
+        raise BuildConstraintError(msg)
+
+    go_build = _parse_go_build(go_build_lines[0].removeprefix("//go:build")) if go_build_lines else None
+    plus_build: Expr | None = None
+    for line in plus_build_lines:
+        expr = _parse_plus_build(line.removeprefix("//").lstrip().removeprefix("+build"))
+        plus_build = expr if plus_build is None else AndExpr(plus_build, expr)
+
+    if go_build is not None and plus_build is not None and not _equivalent(go_build, plus_build):
+        msg = f"//go:build and // +build lines disagree: `{go_build}` vs `{plus_build}`"
+        raise BuildConstraintError(msg)
+
+    expr = go_build if go_build is not None else plus_build
+    return BuildConstraint(expr=expr, go_build=go_build, plus_build=plus_build)
+
+
+_GO_BUILD_PATTERN = re.compile(r"^//go:build(\s|$)")
+_PLUS_BUILD_PATTERN = re.compile(r"^//\s*\+build(\s|$)")
+_RELEASE_TAG_PATTERN = re.compile(r"^go1\.(\d+)$")
+_TAG_PATTERN = re.compile(r"^[\w.]+$")
+_TOKEN_PATTERN = re.compile(r"\s*(\(|\)|!|&&|\|\||[\w.]+|\S)")
+# Exhaustively comparing expressions is exponential in the number of tags
+_MAX_EQUIVALENCE_TAGS = 16
+
+
+def _header_constraint_lines(source: str) -> list[str]:
+    # Constraints may only appear in the leading comments of a file, before the package clause. Legacy
+    # `// +build` lines must additionally be followed by a blank line to distinguish them from package docs.
+    lines = []
+    last_blank = 0
+    in_block_comment = False
+    for line in source.splitlines():
+        stripped = line.strip()
+        if in_block_comment:
+            if "*/" in stripped:
+                in_block_comment = False
+                if stripped.split("*/", 1)[1].strip():
+                    break
+            continue
+
+        if not stripped:
+            last_blank = len(lines)
+            lines.append("")
+        elif stripped.startswith("//"):
+            lines.append(stripped)
+        elif stripped.startswith("/*"):
+            in_block_comment = "*/" not in stripped[2:]
+            if not in_block_comment and stripped.split("*/", 1)[1].strip():
+                break
+        else:
+            break
+
+    return [
+        line
+        for i, line in enumerate(lines)
+        if _GO_BUILD_PATTERN.match(line) or (i < last_blank and _PLUS_BUILD_PATTERN.match(line))
+    ]
+
+
+def _parse_go_build(text: str) -> Expr:
+    tokens = _TOKEN_PATTERN.findall(text)
+    parser = _ExprParser(tokens)
+    expr = parser.parse_or()
+    if parser.peek() is not None:
+        msg = f"unexpected token `{parser.peek()}` in //go:build expression: {text.strip()}"
+        raise BuildConstraintError(msg)
+
+    return expr
+
+
+class _ExprParser:
+    def __init__(self, tokens: list[str]) -> None:
+        self.tokens = tokens
+        self.position = 0
+
+    def peek(self) -> str | None:
+        return self.tokens[self.position] if self.position < len(self.tokens) else None
+
+    def next(self) -> str:
+        token = self.peek()
+        if token is None:
+            msg = "unexpected end of //go:build expression"
+            raise BuildConstraintError(msg)
+
+        self.position += 1
+        return token
+
+    def parse_or(self) -> Expr:
+        expr = self.parse_and()
+        while self.peek() == "||":
+            self.next()
+            expr = OrExpr(expr, self.parse_and())
+
+        return expr
+
+    def parse_and(self) -> Expr:
+        expr = self.parse_not()
+        while self.peek() == "&&":
+            self.next()
+            expr = AndExpr(expr, self.parse_not())
+
+        return expr
+
+    def parse_not(self) -> Expr:
+        if self.peek() == "!":
+            self.next()
+            if self.peek() == "!":
+                msg = "double negation not allowed in //go:build expression"
+                raise BuildConstraintError(msg)
+
+            return NotExpr(self.parse_not())
+
+        return self.parse_atom()
+
+    def parse_atom(self) -> Expr:
+        token = self.next()
+        if token == "(":
+            expr = self.parse_or()
+            if self.peek() != ")":
+                msg = "missing close paren in //go:build expression"
+                raise BuildConstraintError(msg)
+
+            self.next()
+            return expr
+
+        if not _TAG_PATTERN.match(token):
+            msg = f"unexpected token `{token}` in //go:build expression"
+            raise BuildConstraintError(msg)
+
+        return TagExpr(token)
+
+
+def _parse_plus_build(text: str) -> Expr:
+    # Space-separated options are OR'd, comma-separated terms within an option are AND'd
+    expr: Expr | None = None
+    for option in text.split():
+        option_expr: Expr | None = None
+        for term in option.split(","):
+            tag = term.removeprefix("!")
+            if not _TAG_PATTERN.match(tag):
+                msg = f"invalid // +build term: {term}"
+                raise BuildConstraintError(msg)
+
+            term_expr: Expr = NotExpr(TagExpr(tag)) if term.startswith("!") else TagExpr(tag)
+            option_expr = term_expr if option_expr is None else AndExpr(option_expr, term_expr)
+
+        if option_expr is not None:
+            expr = option_expr if expr is None else OrExpr(expr, option_expr)
+
+    if expr is None:
+        msg = "empty // +build line"
+        raise BuildConstraintError(msg)
+
+    return expr
+
+
+def _equivalent(x: Expr, y: Expr) -> bool:
+    tags = sorted(_tags(x) | _tags(y))
+    if len(tags) > _MAX_EQUIVALENCE_TAGS:
+        return str(x) == str(y)
+
+    for mask in range(1 << len(tags)):
+        satisfied = {tag for i, tag in enumerate(tags) if mask & (1 << i)}
+        if x.evaluate(satisfied.__contains__) != y.evaluate(satisfied.__contains__):
+            return False
+
+    return True
+
+
+def _tags(expr: Expr | None) -> frozenset[str]:
+    if expr is None:
+        return frozenset()
+
+    return frozenset(node.tag for node in expr.walk() if isinstance(node, TagExpr))
+
+
+def _wrap(expr: Expr, kind: type[Expr]) -> str:
+    return f"({expr})" if isinstance(expr, kind) else str(expr)
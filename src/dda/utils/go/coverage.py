@@ -0,0 +1,127 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from typing import TYPE_CHECKING
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+    from os import PathLike
+
+COVERAGE_MODES = frozenset({"set", "count", "atomic"})
+
+
+class CoverageError(ValueError):
+    """
+    Raised when a coverage profile is malformed or profiles cannot be merged.
+    """
+
+
+def merge_coverage(profiles: Iterable[str | PathLike[str]], output: str | PathLike[str]) -> None:
+    """
+    Merge several profiles produced by `go test -coverprofile` into one. The counts of identical blocks are summed
+    for the `count` and `atomic` modes, while for the `set` mode a block is covered if it is covered by any
+    profile. Blocks are written sorted by file and position so that the output is deterministic.
+
+    Parameters:
+        profiles: The paths to the profiles to merge.
+        output: The path to the merged profile.
+
+    Raises:
+        CoverageError: If a profile is malformed or the profiles use different coverage modes.
+    """
+    from dda.utils.fs import Path
+
+    mode = ""
+    merged: dict[tuple[str, str], tuple[int, int]] = {}
+    for profile in profiles:
+        profile_mode, blocks = _read_profile(profile)
+        if not mode:
+            mode = profile_mode
+        elif profile_mode != mode:
+            msg = f"{profile}: cannot merge coverage mode `{profile_mode}` with `{mode}`"
+            raise CoverageError(msg)
+
+        _merge_blocks(merged, blocks, mode, profile)
+
+    if not mode:
+        msg = "No coverage profiles to merge"
+        raise CoverageError(msg)
+
+    lines = [f"mode: {mode}\n"]
+    lines.extend(
+        f"{file}:{position} {statements} {count}\n"
+        for (file, position), (statements, count) in sorted(merged.items(), key=lambda item: _sort_key(*item[0]))
+    )
+    Path(output).write_text("".join(lines), encoding="utf-8")
+
+
+def coverage_percent(profile: str | PathLike[str]) -> float:
+    """
+    Compute the percentage of statements covered by a profile, like the total reported by `go tool cover -func`.
+
+    Raises:
+        CoverageError: If the profile is malformed.
+    """
+    mode, blocks = _read_profile(profile)
+    merged: dict[tuple[str, str], tuple[int, int]] = {}
+    _merge_blocks(merged, blocks, mode, profile)
+
+    total = sum(statements for statements, _ in merged.values())
+    covered = sum(statements for statements, count in merged.values() if count)
+    return 100 * covered / total if total else 0.0
+
+
+def _read_profile(
+    profile: str | PathLike[str],
+) -> tuple[str, list[tuple[tuple[str, str], tuple[int, int]]]]:
+    from dda.utils.fs import Path
+
+    with Path(profile).open(encoding="utf-8") as f:
+        header = f.readline().strip()
+        mode = header.removeprefix("mode:").strip()
+        if not header.startswith("mode:") or mode not in COVERAGE_MODES:
+            msg = f"{profile}: invalid coverage mode line: {header}"
+            raise CoverageError(msg)
+
+        blocks: list[tuple[tuple[str, str], tuple[int, int]]] = []
+        for number, line in enumerate(f, 2):
+            if not (stripped := line.strip()):
+                continue
+
+            # github.com/DataDog/foo/bar.go:10.2,12.16 2 1
+            try:
+                block, statements, count = stripped.rsplit(" ", 2)
+                file, position = block.rsplit(":", 1)
+                blocks.append(((file, position), (int(statements), int(count))))
+            except ValueError:
+                msg = f"{profile}:{number}: invalid coverage block: {stripped}"
+                raise CoverageError(msg) from None
+
+    return mode, blocks
+
+
+def _merge_blocks(
+    merged: dict[tuple[str, str], tuple[int, int]],
+    blocks: list[tuple[tuple[str, str], tuple[int, int]]],
+    mode: str,
+    profile: str | PathLike[str],
+) -> None:
+    for key, (statements, count) in blocks:
+        if key not in merged:
+            merged[key] = (statements, min(count, 1) if mode == "set" else count)
+            continue
+
+        existing_statements, existing_count = merged[key]
+        if statements != existing_statements:
+            msg = f"{profile}: inconsistent number of statements for block {key[0]}:{key[1]}"
+            raise CoverageError(msg)
+
+        merged[key] = (statements, max(existing_count, min(count, 1)) if mode == "set" else existing_count + count)
+
+
+def _sort_key(file: str, position: str) -> tuple[str, tuple[int, ...]]:
+    start, _, end = position.partition(",")
+    numbers = [int(n) for part in (start, end) for n in part.split(".") if n.isdigit()]
+    return file, tuple(numbers)
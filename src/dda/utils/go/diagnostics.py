@@ -0,0 +1,149 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from os import PathLike
+
+
+class Diagnostic(Struct, frozen=True):
+    """
+    A problem reported at a position in a Go source file.
+    """
+
+    file: str
+    """The path to the file, as reported by the `go` command."""
+    line: int = 0
+    """The line number, starting at 1, or 0 if unknown."""
+    column: int = 0
+    """The column number, starting at 1, or 0 if unknown."""
+    message: str = ""
+    """The description of the problem."""
+    analyzer: str = ""
+    """The name of the `go vet` analyzer that reported the problem, if any."""
+    package: str = ""
+    """The import path of the package containing the file, if known."""
+
+    def __str__(self) -> str:
+        position = self.file
+        if self.line:
+            position += f":{self.line}"
+            if self.column:
+                position += f":{self.column}"
+
+        return f"{position}: {self.message}"
+
+    @classmethod
+    def from_position(cls, position: str, **kwargs: str) -> Diagnostic:
+        """
+        Create a diagnostic from a position in the `file:line:column` format used by the `go` command, where the
+        line and column are optional.
+        """
+        parts = position.rsplit(":", 2)
+        numbers: list[int] = []
+        # File paths may contain colons, such as drive letters on Windows
+        while len(parts) > 1 and parts[-1].isdigit():
+            numbers.insert(0, int(parts.pop()))
+
+        line, column = (numbers + [0, 0])[:2]
+        return cls(file=":".join(parts), line=line, column=column, **kwargs)
+
+
+def parse_vet_output(output: str) -> list[Diagnostic]:
+    """
+    Parse the output of `go vet -json`, which consists of one JSON object per package that maps analyzer names to
+    their findings. Text that is not part of a JSON object, such as the `# package` headers emitted by older
+    toolchains, is ignored.
+
+    Returns:
+        The diagnostics, in the order they were reported.
+
+    Raises:
+        ValueError: If an analyzer failed to run.
+    """
+    import json
+
+    decoder = json.JSONDecoder()
+    diagnostics: list[Diagnostic] = []
+    index = 0
+    while (index := output.find("{", index)) != -1:
+        try:
+            packages, index = decoder.raw_decode(output, index)
+        except json.JSONDecodeError:
+            index += 1
+            continue
+
+        for package, analyzers in packages.items():
+            for analyzer, findings in analyzers.items():
+                # Failures of an analyzer are reported as an object rather than a list of findings
+                if isinstance(findings, dict):
+                    msg = f"Analyzer `{analyzer}` failed for package `{package}`: {findings.get('error', findings)}"
+                    raise ValueError(msg)
+
+                diagnostics.extend(
+                    Diagnostic.from_position(
+                        finding.get("posn", ""),
+                        message=finding.get("message", ""),
+                        analyzer=analyzer,
+                        package=package,
+                    )
+                    for finding in findings
+                )
+
+    return diagnostics
+
+
+def parse_compiler_output(output: str, directory: str | PathLike[str] | None = None) -> list[Diagnostic]:
+    """
+    Parse the errors printed by `go build` in the `file:line:column: message` format. Lines indented with a tab
+    that follow an error, such as the `have` and `want` types of a mismatch, are continuations of its message.
+    The `# package` headers preceding the errors of each package determine their `package`.
+
+    Parameters:
+        output: The standard error of the `go build` command.
+        directory: The working directory of the build, against which relative file paths are resolved.
+
+    Returns:
+        The diagnostics, in the order they were reported.
+    """
+    from dda.utils.fs import Path
+
+    diagnostics: list[Diagnostic] = []
+    package = ""
+    for line in output.splitlines():
+        if line.startswith("# "):
+            package = line.removeprefix("# ").strip()
+        elif line.startswith("\t") and diagnostics:
+            last = diagnostics[-1]
+            diagnostics[-1] = Diagnostic(
+                file=last.file,
+                line=last.line,
+                column=last.column,
+                message=f"{last.message}\n{line.strip()}",
+                package=last.package,
+            )
+        elif (match := _COMPILER_ERROR_PATTERN.match(line)) is not None:
+            file, line_number, column, message = match.groups()
+            if directory is not None:
+                file = str(Path(directory, file))
+
+            diagnostics.append(
+                Diagnostic(
+                    file=file,
+                    line=int(line_number),
+                    column=int(column or 0),
+                    message=message,
+                    package=package,
+                )
+            )
+
+    return diagnostics
+
+
+_COMPILER_ERROR_PATTERN = re.compile(r"^(\S.*?):(\d+)(?::(\d+))?: (.*)$")
@@ -0,0 +1,225 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+
+from msgspec import Struct
+
+
+class ChecksumError(Exception):
+    """
+    Raised when a downloaded module does not match the checksum recorded in `go.sum` or reported by the
+    [checksum database](https://go.dev/ref/mod#checksum-database).
+
+    Parameters:
+        module: The module that failed verification, in the `path@version` format.
+        message: The output of the `go` command describing the mismatch.
+    """
+
+    def __init__(self, module: str, message: str) -> None:
+        super().__init__(module, message)
+
+        self.__module = module
+        self.__message = message
+
+    @property
+    def module(self) -> str:
+        return self.__module
+
+    @property
+    def message(self) -> str:
+        return self.__message
+
+    def __str__(self) -> str:
+        return f"Checksum verification failed for `{self.__module}`\n{self.__message}".rstrip()
+
+
+class VerificationError(Exception):
+    """
+    Raised when [verifying][dda.tools.go.Go.verify_modules] the modules of a module finds problems.
+
+    Parameters:
+        failures: The reason each module failed `go mod verify`, keyed by module in the `path@version` format.
+        missing: The lines that `go.sum` lacks relative to the build list.
+        extra: The lines of `go.sum` that are not needed by the build list.
+    """
+
+    def __init__(
+        self, failures: dict[str, str], missing: list[str] | None = None, extra: list[str] | None = None
+    ) -> None:
+        super().__init__(failures, missing, extra)
+
+        self.__failures = failures
+        self.__missing = missing or []
+        self.__extra = extra or []
+
+    @property
+    def failures(self) -> dict[str, str]:
+        return self.__failures
+
+    @property
+    def missing(self) -> list[str]:
+        return self.__missing
+
+    @property
+    def extra(self) -> list[str]:
+        return self.__extra
+
+    def __str__(self) -> str:
+        lines: list[str] = []
+        if self.__failures:
+            lines.append("Modules failed verification:")
+            lines.extend(f"  {module}: {reason}" for module, reason in self.__failures.items())
+        if self.__missing:
+            lines.append("Missing from go.sum:")
+            lines.extend(f"  {line}" for line in self.__missing)
+        if self.__extra:
+            lines.append("Not needed in go.sum:")
+            lines.extend(f"  {line}" for line in self.__extra)
+
+        return "\n".join(lines)
+
+
+class ModuleDownload(Struct, frozen=True):
+    """
+    A module downloaded to the module cache, as reported by `go mod download -json`.
+    """
+
+    path: str
+    """The module path, e.g. `golang.org/x/mod`."""
+    version: str
+    """The module version, e.g. `v0.17.0`."""
+    error: str = ""
+    """The reason the module could not be downloaded, if any."""
+    zip: str = ""
+    """The path to the archive of the module in the module cache."""
+    dir: str = ""
+    """The path to the extracted module in the module cache."""
+    sum: str = ""
+    """The checksum of the module, as recorded in `go.sum`."""
+    size: int = 0
+    """The size of the archive of the module, in bytes."""
+
+    def __str__(self) -> str:
+        return f"{self.path}@{self.version}"
+
+
+class DownloadReport(Struct, frozen=True):
+    """
+    The outcome of [downloading][dda.tools.go.Go.download] modules.
+    """
+
+    modules: tuple[ModuleDownload, ...]
+    """The modules, in the order they were reported."""
+    duration: float = 0
+    """The time spent downloading, in seconds."""
+
+    @property
+    def total_size(self) -> int:
+        """
+        The combined size of the archives of every module, in bytes. The `go` command does not report how much was
+        transferred, so this includes modules that were already in the module cache.
+        """
+        return sum(module.size for module in self.modules)
+
+
+def parse_download_output(output: str) -> list[ModuleDownload]:
+    """
+    Parse the output of `go mod download -json`, which consists of one JSON object per module. The size of each
+    archive is read from the module cache, if it exists.
+
+    Returns:
+        The modules, in the order they were reported.
+    """
+    import json
+    import os
+
+    decoder = json.JSONDecoder()
+    modules: list[ModuleDownload] = []
+    index = 0
+    while (index := output.find("{", index)) != -1:
+        module, index = decoder.raw_decode(output, index)
+        zip_path = module.get("Zip", "")
+        try:
+            size = os.path.getsize(zip_path) if zip_path else 0
+        except OSError:
+            size = 0
+
+        modules.append(
+            ModuleDownload(
+                path=module.get("Path", ""),
+                version=module.get("Version", ""),
+                error=module.get("Error", ""),
+                zip=zip_path,
+                dir=module.get("Dir", ""),
+                sum=module.get("Sum", ""),
+                size=size,
+            )
+        )
+
+    return modules
+
+
+def find_checksum_error(output: str) -> ChecksumError | None:
+    """
+    Find a checksum verification failure in the output of the `go` command, which is reported with a
+    `verifying` prefix.
+
+    Returns:
+        The error, or `None` if verification did not fail.
+    """
+    if (match := _VERIFYING_PATTERN.search(output)) is None:
+        return None
+
+    return ChecksumError(match.group(1), output[match.start() :].strip())
+
+
+def parse_verify_output(output: str) -> dict[str, str]:
+    """
+    Parse the failures reported by `go mod verify`, such as
+    `golang.org/x/mod v0.17.0: dir has been modified (/path/to/dir)`.
+
+    Returns:
+        The reason each module failed, keyed by module in the `path@version` format, in the order they were
+        reported.
+    """
+    failures: dict[str, str] = {}
+    for line in output.splitlines():
+        if match := _VERIFY_FAILURE_PATTERN.match(line):
+            failures[f"{match.group(1)}@{match.group(2)}"] = match.group(3)
+
+    return failures
+
+
+def parse_sum_diff(output: str) -> tuple[list[str], list[str]]:
+    """
+    Parse the changes to `go.sum` from the output of `go mod tidy -diff`, ignoring those to `go.mod`. A line
+    whose checksum differs is reported as both missing and extra.
+
+    Returns:
+        The lines that are missing and those that are extra, in the order they were reported.
+    """
+    missing: list[str] = []
+    extra: list[str] = []
+    in_sum = False
+    for line in output.splitlines():
+        if line.startswith("diff "):
+            in_sum = line.endswith("/go.sum")
+        elif not in_sum or line.startswith(("+++ ", "--- ")):
+            continue
+        elif line.startswith("+") and line[1:].strip():
+            missing.append(line[1:])
+        elif line.startswith("-") and line[1:].strip():
+            extra.append(line[1:])
+
+    return missing, extra
+
+
+# Failures are reported as `verifying path@version: ...`, with a `/go.mod` suffix if only the `go.mod` file was
+# checked, followed by a `SECURITY ERROR` notice for mismatches. Failures to query the checksum database are
+# reported as `verifying module: path@version: ...` instead
+_VERIFYING_PATTERN = re.compile(r"\bverifying (?:module: |go\.mod: )?(\S+?@[^\s/:]+)(?:/go\.mod)?: ")
+# Modules are reported with a space between the path and the version, unlike everywhere else
+_VERIFY_FAILURE_PATTERN = re.compile(r"^(\S+) (v\S+): (.+)$")
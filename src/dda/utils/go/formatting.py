@@ -0,0 +1,112 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from typing import TYPE_CHECKING
+
+from msgspec import Struct, field
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+    from os import PathLike
+
+FORMATTERS = frozenset({"gofmt", "gofumpt"})
+# The version of `gofumpt` that is installed when it is not found on `PATH`, which is pinned so that the results
+# of formatting do not change as new versions are released
+GOFUMPT_VERSION = "v0.8.0"
+
+
+class FormatResult(Struct, frozen=True):
+    """
+    The outcome of [formatting][dda.tools.go.Go.format] Go source files.
+    """
+
+    files: tuple[str, ...]
+    """The files whose formatting differs from that of the formatter, relative to the root directory. When
+    formatting in place, these are the files that were rewritten."""
+    diffs: dict[str, str] = field(default_factory=dict)
+    """The unified diff of each file that would change, if requested."""
+
+    @property
+    def formatted(self) -> bool:
+        """Whether every file already had the expected formatting."""
+        return not self.files
+
+
+def find_go_files(
+    root: str | PathLike[str], paths: Iterable[str | PathLike[str]] = (".",), exclude: Iterable[str] = ()
+) -> list[str]:
+    """
+    Find the Go source files to format, skipping hidden directories and excluded directories.
+
+    Parameters:
+        root: The directory against which paths are resolved.
+        paths: The files and directories to search, relative to the root unless absolute.
+        exclude: Directories to skip, either by name at any depth, such as `vendor`, or by a path relative to the
+            root, such as `pkg/generated`.
+
+    Returns:
+        The files, relative to the root and using forward slashes, in sorted order.
+    """
+    import os
+
+    from dda.utils.fs import Path
+
+    root = Path(root)
+    excluded_names = {name for name in exclude if "/" not in name.strip("/")}
+    excluded_paths = {name.strip("/") for name in exclude} - excluded_names
+
+    files: set[str] = set()
+    for path in paths:
+        path = root / path
+        if path.is_file():
+            files.add(_relative_path(path, root))
+            continue
+
+        for directory, dirs, filenames in os.walk(path):
+            relative_dir = _relative_path(directory, root)
+            dirs[:] = [
+                d
+                for d in dirs
+                if not d.startswith(".")
+                and d not in excluded_names
+                and f"{relative_dir}/{d}".removeprefix("./") not in excluded_paths
+            ]
+            files.update(
+                f"{relative_dir}/{name}".removeprefix("./")
+                for name in filenames
+                if name.endswith(".go") and not name.startswith(".")
+            )
+
+    return sorted(files)
+
+
+def parse_format_diff(output: str) -> dict[str, str]:
+    """
+    Split the output of `gofmt -d` into the diff of each file.
+
+    Returns:
+        A mapping of the files, as passed to the formatter, to their unified diff.
+    """
+    diffs: dict[str, list[str]] = {}
+    lines: list[str] = []
+    for line in output.splitlines(keepends=True):
+        # Every diff starts with a `diff NAME.orig NAME` header
+        header = line.rstrip("\r\n").removeprefix("diff ")
+        if line.startswith("diff ") and (length := (len(header) - len(".orig ")) // 2) > 0:
+            name = header[-length:]
+            if header == f"{name}.orig {name}":
+                lines = diffs.setdefault(name, [])
+                continue
+
+        lines.append(line)
+
+    return {name: "".join(lines) for name, lines in diffs.items()}
+
+
+def _relative_path(path: str | PathLike[str], root: PathLike[str]) -> str:
+    import os
+
+    relative = os.path.relpath(path, root)
+    return "." if relative == os.curdir else relative.replace(os.sep, "/")
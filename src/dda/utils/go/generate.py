@@ -0,0 +1,146 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from collections.abc import Iterator, Mapping
+    from os import PathLike
+
+    from dda.utils.fs import Path
+
+
+class Generator(Struct, frozen=True):
+    """
+    A `//go:generate` directive selected by `go generate`.
+    """
+
+    file: str
+    """The file containing the directive, relative to the directory in which `go generate` was run."""
+    command: str
+    """The command, after the expansion of variables like `$GOFILE`."""
+
+    def __str__(self) -> str:
+        return f"{self.file}: {self.command}"
+
+
+def parse_generate_plan(output: str, cwd: str | PathLike[str] | None = None) -> list[Generator]:
+    """
+    Parse the output of `go generate -n -v`, which lists the name of every processed file followed by the
+    commands it would run.
+
+    Parameters:
+        output: The standard error of the command.
+        cwd: The directory in which the command was run, used to tell file names apart from commands.
+
+    Returns:
+        The generators, in the order they would run.
+    """
+    from dda.utils.fs import Path
+
+    root = Path.cwd() if cwd is None else Path(cwd)
+    generators: list[Generator] = []
+    current_file = ""
+    for line in output.splitlines():
+        if not (text := line.strip()):
+            continue
+
+        if text.endswith(".go") and (root / text).is_file():
+            current_file = text
+        elif current_file:
+            generators.append(Generator(file=current_file, command=text))
+
+    return generators
+
+
+def snapshot_files(root: str | PathLike[str]) -> dict[str, tuple[int, int]]:
+    """
+    Record the size and modification time of every file within a directory, excluding the `.git` directory.
+
+    Returns:
+        A mapping of paths relative to the root, using forward slashes, to their size and modification time.
+    """
+    snapshot: dict[str, tuple[int, int]] = {}
+    for name, path in _walk_files(root):
+        stat = path.lstat()
+        snapshot[name] = (stat.st_size, stat.st_mtime_ns)
+
+    return snapshot
+
+
+def changed_files(
+    original: str | PathLike[str],
+    modified: str | PathLike[str],
+    snapshot: Mapping[str, tuple[int, int]],
+) -> list[str]:
+    """
+    Compare a copy of a directory that may have been modified against the original. Differences in line endings
+    and in the number of trailing newlines are ignored so that the result does not depend on the platform.
+
+    Parameters:
+        original: The original directory.
+        modified: The copy of the original directory.
+        snapshot: The [snapshot][dda.utils.go.generate.snapshot_files] of the copy taken before it was modified,
+            which allows skipping the comparison of files that were not touched.
+
+    Returns:
+        The paths relative to the root, using forward slashes, of files that were added, removed or changed,
+        sorted by name.
+    """
+    from dda.utils.fs import Path
+
+    current = snapshot_files(modified)
+    changed: list[str] = []
+    for name in sorted(current.keys() | snapshot.keys()):
+        if name not in current or name not in snapshot:
+            changed.append(name)
+        elif current[name] != snapshot[name] and _normalize(Path(original, name)) != _normalize(Path(modified, name)):
+            changed.append(name)
+
+    return changed
+
+
+def diff_directories(original: str | PathLike[str], other: str | PathLike[str]) -> list[str]:
+    """
+    Compare the contents of two directories, which may not exist. Differences in line endings and in the number
+    of trailing newlines are ignored, like with [`changed_files`][dda.utils.go.generate.changed_files].
+
+    Returns:
+        The paths relative to the roots, using forward slashes, of files that exist in only one of the
+        directories or whose contents differ, sorted by name.
+    """
+    from dda.utils.fs import Path
+
+    original_files = dict(_walk_files(original)) if Path(original).is_dir() else {}
+    other_files = dict(_walk_files(other)) if Path(other).is_dir() else {}
+    return [
+        name
+        for name in sorted(original_files.keys() | other_files.keys())
+        if name not in original_files
+        or name not in other_files
+        or _normalize(original_files[name]) != _normalize(other_files[name])
+    ]
+
+
+def _walk_files(root: str | PathLike[str]) -> Iterator[tuple[str, Path]]:
+    import os
+
+    from dda.utils.fs import Path
+
+    root = Path(root)
+    for directory, dirs, files in os.walk(root):
+        dirs[:] = [d for d in dirs if d != ".git"]
+        for name in files:
+            path = Path(directory, name)
+            yield path.relative_to(root).as_posix(), path
+
+
+def _normalize(path: Path) -> bytes:
+    try:
+        return path.read_bytes().replace(b"\r\n", b"\n").rstrip(b"\n")
+    except FileNotFoundError:
+        return b""
@@ -0,0 +1,145 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from types import MappingProxyType
+from typing import TYPE_CHECKING
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+
+
+class ModuleGraph:
+    """
+    The module requirement graph of a Go project, as reported by `go mod graph`. Nodes are identified by
+    `path@version`, except for the main module(s) which have no version.
+
+    Example usage:
+
+    ```python
+    graph = app.tools.go.module_graph()
+    for node in graph.path("github.com/DataDog/datadog-agent", "golang.org/x/net"):
+        print(node)
+    ```
+    """
+
+    def __init__(self, edges: dict[str, list[str]]) -> None:
+        from dda.utils.go.semver import semver_key
+
+        self.__edges = MappingProxyType({node: tuple(requirements) for node, requirements in edges.items()})
+
+        reverse_edges: dict[str, list[str]] = {}
+        versions: dict[str, list[str]] = {}
+        for node, requirements in self.__edges.items():
+            reverse_edges.setdefault(node, [])
+            for requirement in (node, *requirements):
+                path, _, version = requirement.partition("@")
+                # Exclude the `go@1.21` and `toolchain@go1.21.0` nodes which do not use semantic versioning
+                if version and path not in _TOOLCHAIN_NODES:
+                    versions.setdefault(path, []).append(version)
+
+            for requirement in requirements:
+                reverse_edges.setdefault(requirement, []).append(node)
+
+        self.__reverse_edges = reverse_edges
+        # Minimal version selection picks the highest version required anywhere in the graph
+        self.__selected = MappingProxyType({
+            path: max(candidates, key=semver_key) for path, candidates in sorted(versions.items())
+        })
+
+    @classmethod
+    def from_lines(cls, lines: Iterable[str]) -> ModuleGraph:
+        """
+        Build a graph from the output of `go mod graph` in a single pass, without buffering the whole output.
+        """
+        edges: dict[str, list[str]] = {}
+        for line in lines:
+            if not (parts := line.split()):
+                continue
+
+            node, *requirements = parts
+            edges.setdefault(node, []).extend(requirements)
+            for requirement in requirements:
+                edges.setdefault(requirement, [])
+
+        return cls(edges)
+
+    @property
+    def edges(self) -> MappingProxyType[str, tuple[str, ...]]:
+        """The direct requirements of every node."""
+        return self.__edges
+
+    @property
+    def selected(self) -> MappingProxyType[str, str]:
+        """The version selected for each module path, deduplicating the multiple versions present in the graph."""
+        return self.__selected
+
+    def requirements(self, module: str) -> list[str]:
+        """
+        Returns:
+            The nodes directly required by the given module.
+        """
+        return [requirement for node in self.__resolve(module) for requirement in self.__edges.get(node, ())]
+
+    def dependents(self, module: str) -> list[str]:
+        """
+        Parameters:
+            module: A node in the form `path@version`, or a bare module path matching every version of it.
+
+        Returns:
+            The nodes that directly require the given module.
+        """
+        return sorted({
+            dependent for node in self.__resolve(module) for dependent in self.__reverse_edges.get(node, ())
+        })
+
+    def path(self, source: str, target: str) -> list[str]:
+        """
+        Find one of the shortest requirement chains explaining why `target` is in the graph.
+
+        Parameters:
+            source: The starting node, usually the main module. As with
+                [`dependents`][dda.utils.go.graph.ModuleGraph.dependents], a bare module path matches every
+                version of it.
+            target: The node to reach.
+
+        Returns:
+            The nodes from `source` to `target`, inclusive, or an empty list if `target` is unreachable.
+        """
+        from collections import deque
+
+        targets = set(self.__resolve(target))
+        previous: dict[str, str | None] = {}
+        queue: deque[str] = deque()
+        for node in self.__resolve(source):
+            previous[node] = None
+            queue.append(node)
+
+        while queue:
+            node = queue.popleft()
+            if node in targets:
+                chain = [node]
+                while (parent := previous[chain[-1]]) is not None:
+                    chain.append(parent)
+
+                return chain[::-1]
+
+            for requirement in self.__edges.get(node, ()):
+                if requirement not in previous:
+                    previous[requirement] = node
+                    queue.append(requirement)
+
+        return []
+
+    def __resolve(self, module: str) -> list[str]:
+        if module in self.__edges:
+            return [module]
+
+        if "@" in module:
+            return []
+
+        return [node for node in self.__edges if node.partition("@")[0] == module]
+
+
+_TOOLCHAIN_NODES = frozenset({"go", "toolchain"})
@@ -0,0 +1,117 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from os import PathLike
+
+MANIFEST_NAME = ".dda-tools.json"
+
+
+class ToolSpec(Struct, frozen=True):
+    """
+    A tool to install with `go install`, as written in the `package@version` format.
+    """
+
+    package: str
+    """The import path of the main package, e.g. `go.uber.org/mock/mockgen`."""
+    version: str
+    """The requested version, which may be a query such as `latest`."""
+
+    def __str__(self) -> str:
+        return f"{self.package}@{self.version}"
+
+    @classmethod
+    def from_string(cls, spec: str) -> ToolSpec:
+        """
+        Raises:
+            ValueError: If the spec does not contain both a package and a version.
+        """
+        package, sep, version = spec.rpartition("@")
+        if not sep or not package or not version:
+            msg = f"Invalid tool `{spec}`, expected the format `package@version`"
+            raise ValueError(msg)
+
+        return cls(package=package, version=version)
+
+    @property
+    def pinned(self) -> bool:
+        """
+        Whether the version refers to a single release rather than a query that may resolve to different
+        versions over time, such as `latest` or a branch name.
+        """
+        from dda.utils.go.semver import semver_key
+
+        return semver_key(self.version) != (0,)
+
+    def binary_name(self, goos: str) -> str:
+        """
+        The name of the binary produced by `go install`, which is the last element of the import path unless that
+        is a major version suffix such as `v2`.
+        """
+        parts = self.package.split("/")
+        name = parts[-2] if len(parts) > 1 and _MAJOR_VERSION_PATTERN.match(parts[-1]) else parts[-1]
+        return f"{name}.exe" if goos == "windows" else name
+
+
+class ToolResult(Struct, frozen=True):
+    """
+    The outcome of running a tool of the toolchain with [`go tool`][dda.tools.go.Go.tool].
+    """
+
+    stdout: bytes
+    """The standard output, which may be incomplete if the tool was stopped."""
+    stderr: bytes
+    """The standard error, which may be incomplete if the tool was stopped."""
+    exit_code: int
+    """The exit code, which is nonzero or negative if the tool was stopped."""
+    error: str | None = None
+    """Why the tool was stopped before it exited, such as a timeout or a cancellation."""
+
+    @property
+    def succeeded(self) -> bool:
+        """Whether the tool ran to completion and exited successfully."""
+        return self.error is None and self.exit_code == 0
+
+
+def read_manifest(bin_dir: str | PathLike[str]) -> dict[str, str]:
+    """
+    Read the manifest recording the tools installed in a directory.
+
+    Returns:
+        A mapping of binary names to the spec they were installed from, which is empty if there is no manifest or
+        it cannot be read.
+    """
+    import json
+
+    from dda.utils.fs import Path
+
+    manifest = Path(bin_dir) / MANIFEST_NAME
+    try:
+        data = json.loads(manifest.read_text(encoding="utf-8"))
+    except (OSError, ValueError):
+        return {}
+
+    return {str(k): str(v) for k, v in data.items()} if isinstance(data, dict) else {}
+
+
+def write_manifest(bin_dir: str | PathLike[str], tools: dict[str, str]) -> None:
+    """
+    Write the manifest recording the tools installed in a directory.
+    """
+    import json
+
+    from dda.utils.fs import Path
+
+    manifest = Path(bin_dir) / MANIFEST_NAME
+    manifest.write_text(json.dumps(dict(sorted(tools.items())), indent=2) + "\n", encoding="utf-8")
+
+
+# Matches the `isVersionElement` function of `cmd/go/internal/load`
+_MAJOR_VERSION_PATTERN = re.compile(r"^v([2-9]|[1-9]\d+)$")
@@ -0,0 +1,183 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct, field
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+    from typing import IO
+    from xml.etree.ElementTree import Element
+
+    from dda.utils.go.testing import TestEvent
+
+
+def write_junit(events: Iterable[TestEvent], stream: IO[str]) -> None:
+    """
+    Write the [events][dda.utils.go.testing.TestEvent] of a test run as a JUnit XML report, with one
+    `<testsuite>` per package and one `<testcase>` per test. Subtests are reported as separate test cases named
+    like `TestFoo/bar`, as they are by the `go` command.
+
+    Failures and skips include the output of the test. Packages that fail without a failing test, such as those
+    that do not build or whose `TestMain` function exits early, are reported as an error of their test suite so
+    that the failure is not lost. Tests that never finished, for example because their package timed out, are
+    reported as failures.
+
+    Example usage:
+
+    ```python
+    from dda.utils.go.junit import write_junit
+
+    run = app.tools.go.run_tests("./...")
+    with open("junit.xml", "w", encoding="utf-8") as f:
+        write_junit(run.events, f)
+    ```
+
+    Parameters:
+        events: The events, in the order they were emitted.
+        stream: The text stream to write to.
+    """
+    from xml.etree.ElementTree import Element, ElementTree, SubElement, indent
+
+    from dda.utils.go.testing import TestAction
+
+    suites: dict[str, _Suite] = {}
+    build_output: dict[str, list[str]] = {}
+    stderr_output: dict[str, list[str]] = {}
+    stderr_section: list[str] = []
+    for event in events:
+        if event.action == TestAction.BUILD_OUTPUT:
+            build_output.setdefault(event.import_path, []).append(event.output)
+            continue
+
+        # Older toolchains print compilation errors to standard error, with a `# package` header per package
+        if event.action == TestAction.STDERR:
+            if match := _BUILD_HEADER_PATTERN.match(event.output):
+                stderr_section = stderr_output.setdefault(match.group(1), [])
+            stderr_section.append(event.output)
+            continue
+
+        if not event.package:
+            continue
+
+        suite = suites.setdefault(event.package, _Suite())
+        if not suite.timestamp and event.time:
+            suite.timestamp = event.time
+        if not event.test:
+            if event.action == TestAction.OUTPUT:
+                suite.output.append(event.output)
+            elif event.action in {TestAction.PASS, TestAction.FAIL, TestAction.SKIP}:
+                suite.result = event.action
+                suite.elapsed = event.elapsed or 0
+                suite.failed_build = event.failed_build
+            continue
+
+        case = suite.cases.setdefault(event.test, _Case())
+        if event.action == TestAction.OUTPUT:
+            case.output.append(event.output)
+        elif event.action in {TestAction.PASS, TestAction.BENCH, TestAction.FAIL, TestAction.SKIP}:
+            case.result = event.action
+            case.elapsed = event.elapsed or 0
+
+    root = Element("testsuites")
+    totals = dict.fromkeys(("tests", "failures", "errors", "skipped"), 0)
+    total_time = 0.0
+    for package, suite in suites.items():
+        element = SubElement(root, "testsuite", name=package)
+        counts = dict.fromkeys(totals, 0)
+        for name, case in suite.cases.items():
+            case_element = SubElement(
+                element, "testcase", name=name, classname=package, time=_format_duration(case.elapsed)
+            )
+            counts["tests"] += 1
+            if case.result == TestAction.FAIL or not case.result:
+                counts["failures"] += 1
+                message = _failure_message(case.output) if case.result else "Test did not complete"
+                _add_output(SubElement(case_element, "failure", message=message), case.output)
+            elif case.result == TestAction.SKIP:
+                counts["skipped"] += 1
+                _add_output(SubElement(case_element, "skipped", message=_failure_message(case.output)), case.output)
+
+        if suite.result == TestAction.FAIL and not counts["failures"]:
+            counts["tests"] += 1
+            counts["errors"] += 1
+            # Only newer toolchains report which package failed to build
+            if suite.failed_build or any(line.rstrip().endswith("[build failed]") for line in suite.output):
+                name, message = "[build failed]", f"Failed to build {package}"
+                output = build_output.get(suite.failed_build) or _stderr_output(stderr_output, package)
+            else:
+                name, message, output = "[package failed]", _failure_message(suite.output), suite.output
+            # Parsers that only consider test cases would otherwise ignore the error
+            case_element = SubElement(element, "testcase", name=name, classname=package, time="0.000")
+            _add_output(SubElement(case_element, "error", message=message), output)
+
+        element.set("tests", str(counts["tests"]))
+        for key in ("failures", "errors", "skipped"):
+            element.set(key, str(counts[key]))
+        element.set("time", _format_duration(suite.elapsed))
+        if suite.timestamp:
+            # The schema only allows local times without a time zone
+            element.set("timestamp", suite.timestamp[:19])
+
+        for key, value in counts.items():
+            totals[key] += value
+        total_time += suite.elapsed
+
+    for key, value in totals.items():
+        root.set(key, str(value))
+    root.set("time", _format_duration(total_time))
+
+    indent(root)
+    ElementTree(root).write(stream, encoding="unicode", xml_declaration=True)
+    stream.write("\n")
+
+
+class _Case(Struct):
+    result: str = ""
+    elapsed: float = 0
+    output: list[str] = field(default_factory=list)
+
+
+class _Suite(Struct):
+    result: str = ""
+    elapsed: float = 0
+    timestamp: str = ""
+    failed_build: str = ""
+    output: list[str] = field(default_factory=list)
+    cases: dict[str, _Case] = field(default_factory=dict)
+
+
+def _failure_message(output: list[str]) -> str:
+    # The first line that is not a status line of the `testing` package is usually the reason
+    for line in output:
+        if (text := line.strip()) and not _STATUS_LINE_PATTERN.match(text):
+            return _sanitize(text)
+
+    return "Failed"
+
+
+def _stderr_output(stderr_output: dict[str, list[str]], package: str) -> list[str]:
+    return [line for name, lines in stderr_output.items() if name.split(" ", 1)[0] == package for line in lines]
+
+
+def _add_output(element: Element, output: list[str]) -> None:
+    if text := "".join(output):
+        element.text = _sanitize(text)
+
+
+def _sanitize(text: str) -> str:
+    # Control characters such as those of terminal escape sequences are not allowed in XML documents
+    return _INVALID_XML_PATTERN.sub("\ufffd", text)
+
+
+def _format_duration(seconds: float) -> str:
+    return f"{seconds:.3f}"
+
+
+_BUILD_HEADER_PATTERN = re.compile(r"^# (\S+(?: \[\S+\])?)\s*$")
+_STATUS_LINE_PATTERN = re.compile(r"^(?:=== (?:RUN|PAUSE|CONT|NAME)|--- (?:PASS|FAIL|SKIP)|PASS$|FAIL\b|ok\s)")
+_INVALID_XML_PATTERN = re.compile("[\x00-\x08\x0b\x0c\x0e-\x1f\ufffe\uffff]")
@@ -0,0 +1,202 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+
+from msgspec import Struct
+
+
+class Requirement(Struct, frozen=True):
+    """
+    A module required by a `go.mod` file, with any applicable `replace` directive.
+    """
+
+    path: str
+    """The module path, e.g. `golang.org/x/mod`."""
+    version: str
+    """The required version, e.g. `v0.17.0`."""
+    indirect: bool = False
+    """Whether the requirement is marked with an `// indirect` comment."""
+    replacement: str = ""
+    """The replacement in the `path@version` format, or the directory of a local replacement."""
+
+
+class ModuleChange(Struct, frozen=True):
+    """
+    A difference between the requirements of two `go.mod` files.
+    """
+
+    path: str
+    """The module path, which is that of the new requirement if the module was moved to a new major version."""
+    old_version: str = ""
+    """The previously required version, which is empty if the module was added."""
+    new_version: str = ""
+    """The newly required version, which is empty if the module was removed."""
+    indirect: bool = False
+    """Whether the module is an indirect dependency, as of the new requirement if there is one."""
+    old_replacement: str = ""
+    """The previous replacement of the module, if any."""
+    new_replacement: str = ""
+    """The new replacement of the module, if any."""
+    previous_path: str = ""
+    """The path of the module before it was moved to a new major version, such as `github.com/foo/bar` for a
+    move to `github.com/foo/bar/v2`."""
+
+    @property
+    def added(self) -> bool:
+        return not self.old_version
+
+    @property
+    def removed(self) -> bool:
+        return not self.new_version
+
+    @property
+    def major_bump(self) -> bool:
+        """
+        Whether the major version increased, which usually implies breaking changes. This includes moves to a
+        path with a new major version suffix.
+        """
+        from dda.utils.go.semver import semver_major
+
+        old, new = semver_major(self.old_version), semver_major(self.new_version)
+        return old is not None and new is not None and new > old
+
+
+class ModuleDiffReport(Struct, frozen=True):
+    """
+    The [differences][dda.tools.go.Go.module_diff] between the requirements of two `go.mod` files.
+    """
+
+    changes: tuple[ModuleChange, ...]
+    """Every change, sorted by module path."""
+
+    @property
+    def added(self) -> list[ModuleChange]:
+        return [change for change in self.changes if change.added]
+
+    @property
+    def removed(self) -> list[ModuleChange]:
+        return [change for change in self.changes if change.removed]
+
+    @property
+    def changed(self) -> list[ModuleChange]:
+        """The modules that are required by both files but with a different version or replacement."""
+        return [change for change in self.changes if not change.added and not change.removed]
+
+    @property
+    def direct(self) -> list[ModuleChange]:
+        return [change for change in self.changes if not change.indirect]
+
+    @property
+    def indirect(self) -> list[ModuleChange]:
+        return [change for change in self.changes if change.indirect]
+
+    @property
+    def major_bumps(self) -> list[ModuleChange]:
+        return [change for change in self.changes if change.major_bump]
+
+
+def parse_requirements(output: str) -> dict[str, Requirement]:
+    """
+    Parse the output of `go mod edit -json`, which is produced by the same parser as the `go` command uses, so
+    that every syntax of `require` and `replace` directives is supported. Replacements of a specific version
+    only apply if that version is the one required.
+
+    Returns:
+        A mapping of module paths to their requirement.
+    """
+    import json
+
+    data = json.loads(output)
+    replacements: dict[tuple[str, str], str] = {}
+    for replace in data.get("Replace") or ():
+        old, new = replace["Old"], replace["New"]
+        replacement = f"{new['Path']}@{new['Version']}" if new.get("Version") else new["Path"]
+        replacements[old["Path"], old.get("Version", "")] = replacement
+
+    requirements: dict[str, Requirement] = {}
+    for require in data.get("Require") or ():
+        path, version = require["Path"], require["Version"]
+        requirements[path] = Requirement(
+            path=path,
+            version=version,
+            indirect=require.get("Indirect", False),
+            replacement=replacements.get((path, version)) or replacements.get((path, ""), ""),
+        )
+
+    return requirements
+
+
+def diff_requirements(old: dict[str, Requirement], new: dict[str, Requirement]) -> ModuleDiffReport:
+    """
+    Compare the [requirements][dda.utils.go.moddiff.parse_requirements] of two `go.mod` files. A module that is
+    removed while another with the same path but a higher major version suffix is added, such as
+    `gopkg.in/yaml.v2` and `gopkg.in/yaml.v3`, is reported as a single change.
+    """
+    changes: dict[str, ModuleChange] = {}
+    for path in old.keys() | new.keys():
+        before, after = old.get(path), new.get(path)
+        if before is not None and after is not None:
+            if (before.version, before.replacement) != (after.version, after.replacement):
+                changes[path] = ModuleChange(
+                    path=path,
+                    old_version=before.version,
+                    new_version=after.version,
+                    indirect=after.indirect,
+                    old_replacement=before.replacement,
+                    new_replacement=after.replacement,
+                )
+        elif after is not None:
+            changes[path] = ModuleChange(
+                path=path, new_version=after.version, indirect=after.indirect, new_replacement=after.replacement
+            )
+        elif before is not None:
+            changes[path] = ModuleChange(
+                path=path, old_version=before.version, indirect=before.indirect, old_replacement=before.replacement
+            )
+
+    # Pair the highest removed major version with each added one
+    removed_by_prefix: dict[str, ModuleChange] = {}
+    for change in sorted(changes.values(), key=lambda change: _split_major(change.path)[1]):
+        if change.removed:
+            removed_by_prefix[_split_major(change.path)[0]] = change
+
+    for change in list(changes.values()):
+        if not change.added:
+            continue
+
+        prefix, major = _split_major(change.path)
+        previous = removed_by_prefix.get(prefix)
+        if previous is None or previous.path == change.path or _split_major(previous.path)[1] >= major:
+            continue
+
+        del removed_by_prefix[prefix]
+        del changes[previous.path]
+        changes[change.path] = ModuleChange(
+            path=change.path,
+            old_version=previous.old_version,
+            new_version=change.new_version,
+            indirect=change.indirect,
+            old_replacement=previous.old_replacement,
+            new_replacement=change.new_replacement,
+            previous_path=previous.path,
+        )
+
+    return ModuleDiffReport(changes=tuple(changes[path] for path in sorted(changes)))
+
+
+def _split_major(path: str) -> tuple[str, int]:
+    # Paths without a suffix are at major version 0 or 1
+    if (match := _GOPKG_IN_PATTERN.match(path) or _MAJOR_SUFFIX_PATTERN.search(path)) is None:
+        return path, 1
+
+    # The prefix keeps the package name so that different `gopkg.in` modules are not mistaken for each other
+    return path[: match.start(1)], int(match.group(2))
+
+
+# Major version suffixes are path elements like `/v2`
+_MAJOR_SUFFIX_PATTERN = re.compile(r"(/v(\d+))$")
+# Modules of `gopkg.in` have suffixes like `.v2` instead, as in `gopkg.in/yaml.v3` or `gopkg.in/DataDog/dd-trace-go.v1`
+_GOPKG_IN_PATTERN = re.compile(r"^gopkg\.in/(?:[^/]+/)?[^/]+(\.v(\d+))$")
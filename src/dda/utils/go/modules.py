@@ -0,0 +1,370 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+from dda.utils.fs import Path
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+    from os import PathLike
+
+    from dda.utils.go.version import Version
+
+
+class NoModuleError(Exception):
+    """
+    Raised when no `go.mod` file exists in a directory or any of its parents.
+
+    Parameters:
+        start: The directory from which the search began.
+    """
+
+    def __init__(self, start: Path) -> None:
+        super().__init__(start)
+
+        self.__start = start
+
+    @property
+    def start(self) -> Path:
+        return self.__start
+
+    def __str__(self) -> str:
+        return f"No `go.mod` file found in `{self.__start}` or any parent directory"
+
+
+class Module(Struct, frozen=True):
+    """
+    A Go module within a directory tree.
+    """
+
+    path: str
+    """The module path declared in the `go.mod` file, e.g. `github.com/DataDog/datadog-agent`."""
+    directory: Path
+    """The directory containing the `go.mod` file."""
+
+
+class Workspace(Struct, frozen=True):
+    """
+    A Go [workspace](https://go.dev/ref/mod#workspaces) defined by a `go.work` file.
+    """
+
+    file: Path
+    """The path to the `go.work` file."""
+    modules: tuple[Path, ...] = ()
+    """The directories of the member modules listed in the `use` directives, in the order they appear."""
+
+    @property
+    def directory(self) -> Path:
+        """The directory containing the `go.work` file, against which relative paths are resolved."""
+        return self.file.parent
+
+
+def detect_project_root(start: str | PathLike[str] | None = None) -> Path:
+    """
+    Find the root of the Go module containing the given directory by walking up the filesystem until a `go.mod`
+    file is found. In the case of nested modules, the nearest one is chosen.
+
+    Symbolic links are resolved first so that aliased paths of the same directory always produce the same root.
+
+    Parameters:
+        start: The directory from which to start the search, defaulting to the current working directory.
+            If this is a file, its parent directory is used.
+
+    Returns:
+        The directory containing the `go.mod` file.
+
+    Raises:
+        NoModuleError: If the filesystem root is reached without finding a `go.mod` file.
+    """
+    directory = _resolve_start(start)
+    if (root := _find_upward(directory, "go.mod")) is None:
+        raise NoModuleError(directory)
+
+    return root
+
+
+def find_workspace_file(start: str | PathLike[str] | None = None) -> Path | None:
+    """
+    Find the `go.work` file that applies to the given directory, following the same rules as the `go` command.
+    The `GOWORK` environment variable takes precedence: if set to `off` workspace mode is disabled, and if set to
+    a path that file is used.
+
+    Parameters:
+        start: The directory from which to start the search, defaulting to the current working directory.
+
+    Returns:
+        The path to the `go.work` file, or `None` if the directory is not part of a workspace.
+    """
+    import os
+
+    if gowork := os.environ.get("GOWORK"):
+        return None if gowork == "off" else Path(gowork).resolve()
+
+    if (root := _find_upward(_resolve_start(start), "go.work")) is None:
+        return None
+
+    return root / "go.work"
+
+
+def detect_workspace(start: str | PathLike[str] | None = None) -> Workspace | None:
+    """
+    Find the [workspace][dda.utils.go.modules.find_workspace_file] that applies to the given directory and read
+    its member modules from the `use` directives of the `go.work` file.
+
+    Parameters:
+        start: The directory from which to start the search, defaulting to the current working directory.
+
+    Returns:
+        The workspace, or `None` if the directory is not part of a workspace.
+    """
+    if (work_file := find_workspace_file(start)) is None or not work_file.is_file():
+        return None
+
+    modules: list[Path] = []
+    in_block = False
+    for line in work_file.read_text(encoding="utf-8").splitlines():
+        fields = line.partition("//")[0].split()
+        if not fields:
+            continue
+
+        if in_block:
+            if fields == [")"]:
+                in_block = False
+                continue
+        elif fields[0] == "use":
+            if fields[1:] == ["("]:
+                in_block = True
+                continue
+
+            fields = fields[1:]
+        else:
+            continue
+
+        if len(fields) == 1:
+            modules.append((work_file.parent / fields[0].strip('"`')).resolve())
+
+    return Workspace(file=work_file, modules=tuple(modules))
+
+
+def required_version(root: str | PathLike[str] | None = None) -> Version:
+    """
+    Read the minimum Go version required by a module from the `go` and `toolchain` directives of its `go.mod` file.
+    The `toolchain` directive takes precedence, unless it is older than the `go` directive, in which case the
+    `go` command ignores it. Modules without a `go` directive are assumed to require Go 1.16.
+
+    Parameters:
+        root: The directory of the module, defaulting to the root of the module containing the current
+            working directory.
+
+    Raises:
+        NoModuleError: If no `go.mod` file is found.
+        ValueError: If a directive contains an invalid version.
+    """
+    from dda.utils.go.version import Version, parse_version
+
+    directory = detect_project_root() if root is None else _resolve_start(root)
+    mod_file = directory / "go.mod"
+    if not mod_file.is_file():
+        raise NoModuleError(directory)
+
+    directives: dict[str, str] = {}
+    for line in mod_file.read_text(encoding="utf-8").splitlines():
+        fields = line.partition("//")[0].split()
+        if len(fields) == 2 and fields[0] in {"go", "toolchain"}:  # noqa: PLR2004
+            directives[fields[0]] = fields[1]
+
+    try:
+        version = parse_version(directives["go"]) if "go" in directives else Version(major=1, minor=16)
+        # The special `default` value means that no particular toolchain is suggested
+        if directives.get("toolchain", "default") != "default":
+            version = max(version, parse_version(directives["toolchain"]))
+    except ValueError as e:
+        msg = f"{mod_file}: {e}"
+        raise ValueError(msg) from None
+
+    return version
+
+
+def check_toolchain(root: str | PathLike[str], toolchain: str) -> str:
+    """
+    Validate a toolchain for the `toolchain` directive of a module's `go.mod` file, which
+    [`set_toolchain`][dda.tools.go.Go.set_toolchain] writes.
+
+    Parameters:
+        root: The directory of the module.
+        toolchain: The toolchain name, such as `go1.22.3`. The `go` prefix is optional.
+
+    Returns:
+        The toolchain name, with the `go` prefix.
+
+    Raises:
+        NoModuleError: If no `go.mod` file is found.
+        ValueError: If the toolchain is invalid or older than the version in the `go` directive.
+    """
+    from dda.utils.go.version import parse_version
+
+    directory = _resolve_start(root)
+    mod_file = directory / "go.mod"
+    if not mod_file.is_file():
+        raise NoModuleError(directory)
+
+    if not toolchain.startswith("go"):
+        toolchain = f"go{toolchain}"
+
+    # The directive requires a release or prerelease rather than a language version like `go1.22`
+    version = parse_version(toolchain)
+    if version.patch is None and not version.prerelease:
+        msg = f"Invalid toolchain `{toolchain}`, expected a version like `go1.22.3`"
+        raise ValueError(msg)
+
+    for line in mod_file.read_text(encoding="utf-8").splitlines():
+        fields = line.partition("//")[0].split()
+        if len(fields) != 2 or fields[0] != "go":  # noqa: PLR2004
+            continue
+
+        try:
+            minimum = parse_version(fields[1])
+        except ValueError as e:
+            msg = f"{mod_file}: {e}"
+            raise ValueError(msg) from None
+
+        if version < minimum:
+            msg = f"Toolchain `{toolchain}` is older than the version required by the `go` directive: {fields[1]}"
+            raise ValueError(msg)
+
+        break
+
+    return toolchain
+
+
+def local_replacements(root: str | PathLike[str]) -> list[Path]:
+    """
+    Read the directories that modules are replaced by in the `replace` directives of a module's `go.mod` file,
+    such as `replace example.com/foo => ../foo`. Replacements by another module version are ignored.
+
+    Parameters:
+        root: The directory of the module.
+
+    Returns:
+        The absolute paths to the directories, in the order they appear.
+
+    Raises:
+        NoModuleError: If no `go.mod` file is found.
+    """
+    import os
+
+    directory = _resolve_start(root)
+    mod_file = directory / "go.mod"
+    if not mod_file.is_file():
+        raise NoModuleError(directory)
+
+    directories: list[Path] = []
+    in_block = False
+    for line in mod_file.read_text(encoding="utf-8").splitlines():
+        fields = line.partition("//")[0].split()
+        if not fields:
+            continue
+
+        if in_block:
+            if fields == [")"]:
+                in_block = False
+                continue
+        elif fields[0] == "replace":
+            if fields[1:] == ["("]:
+                in_block = True
+                continue
+
+            fields = fields[1:]
+        else:
+            continue
+
+        if "=>" not in fields:
+            continue
+
+        # Only paths that are absolute or begin with `./` or `../` are directories, and those have no version
+        replacement = fields[fields.index("=>") + 1 :]
+        if len(replacement) != 1:
+            continue
+
+        path = replacement[0].strip('"`')
+        if path.startswith(("./", "../", ".\\", "..\\")) or os.path.isabs(path):
+            directories.append((directory / path).resolve())
+
+    return directories
+
+
+def find_modules(
+    root: str | PathLike[str],
+    *,
+    ignore: Iterable[str] = (),
+    top_level_only: bool = False,
+) -> list[Module]:
+    """
+    Find every Go module within a directory tree. Directories named `vendor` or `testdata`, and those whose names
+    begin with a `.`, are never searched.
+
+    Parameters:
+        root: The directory to search.
+        ignore: Glob patterns of directories to skip, matched against both their name and their path relative
+            to the root using forward slashes, e.g. `tools` or `test/e2e/*`.
+        top_level_only: Whether to stop searching a directory once a module is found in it, excluding any
+            nested modules.
+
+    Returns:
+        The modules, sorted by directory.
+    """
+    import os
+    from fnmatch import fnmatch
+
+    root = Path(root).resolve()
+    patterns = list(ignore)
+    modules: list[Module] = []
+    for directory, dirs, files in os.walk(root):
+        current = Path(directory)
+        if "go.mod" in files:
+            modules.append(Module(path=_read_module_path(current / "go.mod"), directory=current))
+            if top_level_only:
+                dirs.clear()
+                continue
+
+        kept = []
+        for name in dirs:
+            if name in {"vendor", "testdata"} or name.startswith("."):
+                continue
+
+            relative = (current / name).relative_to(root).as_posix()
+            if any(fnmatch(name, pattern) or fnmatch(relative, pattern) for pattern in patterns):
+                continue
+
+            kept.append(name)
+
+        dirs[:] = kept
+
+    return sorted(modules, key=lambda module: module.directory)
+
+
+def _read_module_path(mod_file: Path) -> str:
+    for line in mod_file.read_text(encoding="utf-8").splitlines():
+        fields = line.partition("//")[0].split()
+        if len(fields) == 2 and fields[0] == "module":  # noqa: PLR2004
+            return fields[1].strip('"`')
+
+    return ""
+
+
+def _resolve_start(start: str | PathLike[str] | None) -> Path:
+    directory = (Path.cwd() if start is None else Path(start)).resolve()
+    return directory if not directory.is_file() else directory.parent
+
+
+def _find_upward(directory: Path, filename: str) -> Path | None:
+    for parent in (directory, *directory.parents):
+        if (parent / filename).is_file():
+            return parent
+
+    return None
@@ -0,0 +1,125 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import threading
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from collections.abc import Mapping
+    from typing import IO
+
+
+class Invocation(Struct, frozen=True):
+    """
+    A run of the `go` command, as reported to an [`Observer`][dda.utils.go.observer.Observer].
+    """
+
+    command: list[str]
+    """The full command, starting with the path to the binary."""
+    env_vars: dict[str, str]
+    """The environment variables that differ from those of the current process, after filtering."""
+    cwd: str
+    """The absolute path to the working directory."""
+    duration: float | None = None
+    """The time spent running, in seconds, or `None` if the command has not finished."""
+    exit_code: int | None = None
+    """The exit code, or `None` if the command has not finished or its exit code is unknown."""
+
+    @property
+    def shell_command(self) -> str:
+        """
+        The command line that reproduces the invocation in a POSIX shell, such as
+        `cd /src && GOOS=linux /usr/bin/go build ./...`.
+        """
+        from dda.utils.go.plan import CommandSpec
+
+        return str(CommandSpec(command=self.command, env_vars=self.env_vars, cwd=self.cwd))
+
+
+class Observer:
+    """
+    A hook that is notified before and after every run of the `go` command by the
+    [`Go`][dda.tools.go.Go] tool while it is [registered][dda.tools.go.Go.observe]. Methods may be called
+    concurrently when builds run in parallel. Nothing is redacted unless
+    [`filter_env_var`][dda.utils.go.observer.Observer.filter_env_var] is overridden.
+    """
+
+    def started(self, invocation: Invocation) -> None:
+        """
+        Called right before the command starts.
+        """
+
+    def finished(self, invocation: Invocation) -> None:
+        """
+        Called once the command exits, or if it could not be started, with its duration and exit code.
+        """
+
+    def filter_env_var(self, name: str, value: str) -> str | None:  # noqa: ARG002, PLR6301
+        """
+        Returns:
+            The value to report for an environment variable, such as a placeholder for a secret, or `None` to
+            omit the variable entirely.
+        """
+        return value
+
+
+class JSONObserver(Observer):
+    """
+    An observer that writes every finished invocation to a stream as a JSON object on a single line, for use as
+    an audit log.
+
+    Parameters:
+        stream: The text stream to write to.
+    """
+
+    def __init__(self, stream: IO[str]) -> None:
+        self.__stream = stream
+        self.__lock = threading.Lock()
+
+    def finished(self, invocation: Invocation) -> None:
+        from msgspec.json import encode
+
+        line = encode(invocation).decode("utf-8")
+        with self.__lock:
+            self.__stream.write(f"{line}\n")
+            self.__stream.flush()
+
+
+def env_var_delta(
+    env: Mapping[str, str] | None, overrides: Mapping[str, str], observers: tuple[Observer, ...]
+) -> dict[str, str]:
+    """
+    Determine how the environment of a command differs from that of the current process, applying the
+    [filters][dda.utils.go.observer.Observer.filter_env_var] of every observer in order. Variables that are
+    removed from the environment are not reported.
+
+    Parameters:
+        env: The full environment of the command, or `None` if it inherits that of the current process.
+        overrides: Variables set by the tool, which do not take precedence over those in `env`.
+        observers: The observers whose filters to apply.
+    """
+    import os
+
+    final = dict(os.environ if env is None else env)
+    for name, value in overrides.items():
+        final.setdefault(name, value)
+
+    delta: dict[str, str] = {}
+    for name, value in sorted(final.items()):
+        if os.environ.get(name) == value:
+            continue
+
+        for observer in observers:
+            if (filtered := observer.filter_env_var(name, value)) is None:
+                break
+
+            value = filtered
+        else:
+            delta[name] = value
+
+    return delta
+
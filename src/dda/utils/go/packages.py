@@ -0,0 +1,420 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+    from os import PathLike
+
+    from dda.utils.fs import Path
+    from dda.utils.go.constraints import BuildContext
+
+
+class PackageImports(Struct, frozen=True):
+    """
+    The dependencies of a package, as reported by `go list -json`.
+    """
+
+    import_path: str
+    """The import path of the package."""
+    imports: tuple[str, ...] = ()
+    """The packages imported directly by the non-test files of the package."""
+    test_imports: tuple[str, ...] = ()
+    """The packages imported by the test files that belong to the package."""
+    xtest_imports: tuple[str, ...] = ()
+    """The packages imported by the test files of the external `_test` package."""
+    deps: tuple[str, ...] = ()
+    """All packages imported by the non-test files of the package, directly or transitively."""
+    cgo_files: tuple[str, ...] = ()
+    """The names of the files of the package that import `C`."""
+    standard: bool = False
+    """Whether the package is part of the standard library."""
+    module: str = ""
+    """The path of the module containing the package, which is empty for the standard library."""
+    module_version: str = ""
+    """
+    The version of the module containing the package, which is empty for the main module and for modules that are
+    replaced by a local directory, as neither can be downloaded.
+    """
+    directory: str = ""
+    """The directory containing the source files of the package."""
+
+
+def parse_package_list(output: str) -> list[PackageImports]:
+    """
+    Parse the output of `go list -json`, which consists of one JSON object per package.
+
+    Returns:
+        The packages, in the order they were listed.
+    """
+    import json
+
+    decoder = json.JSONDecoder()
+    packages: list[PackageImports] = []
+    index = 0
+    while (index := output.find("{", index)) != -1:
+        package, index = decoder.raw_decode(output, index)
+        module = package.get("Module") or {}
+        replacement = module.get("Replace") or {}
+        downloadable = not module.get("Main") and (not replacement or replacement.get("Version"))
+        packages.append(
+            PackageImports(
+                import_path=package.get("ImportPath", ""),
+                imports=tuple(package.get("Imports", ())),
+                test_imports=tuple(package.get("TestImports", ())),
+                xtest_imports=tuple(package.get("XTestImports", ())),
+                deps=tuple(package.get("Deps", ())),
+                cgo_files=tuple(package.get("CgoFiles", ())),
+                standard=package.get("Standard", False),
+                module=module.get("Path", ""),
+                module_version=module.get("Version", "") if downloadable else "",
+                directory=package.get("Dir", ""),
+            )
+        )
+
+    return packages
+
+
+def package_files(directory: str | PathLike[str], context: BuildContext) -> list[Path]:
+    """
+    Determine which Go source files of a package are compiled for the given context, like the `GoFiles` of
+    [`go/build`](https://pkg.go.dev/go/build)'s `Context.ImportDir` but without loading imports. A file is
+    included only if both its [name][dda.utils.go.constraints.matches_filename] and its
+    [build constraints][dda.utils.go.constraints.parse_build_constraints] match. Test files and files whose names
+    begin with `_` or `.` are always excluded.
+
+    Parameters:
+        directory: The directory of the package.
+        context: The target configuration.
+
+    Returns:
+        The included files, sorted by name.
+
+    Raises:
+        BuildConstraintError: If the build constraints of a file are malformed.
+    """
+    return _matching_files(directory, context, frozenset({".go"}))
+
+
+def source_files(directory: str | PathLike[str], context: BuildContext) -> list[Path]:
+    """
+    Determine every file of a package that is compiled or linked for the given context. In addition to the
+    [Go source files][dda.utils.go.packages.package_files], this includes the C, C++, Objective-C, Fortran, SWIG
+    and assembly sources, the headers and the `.syso` objects, like the `CFiles`, `HFiles`, `SFiles`, `SysoFiles`
+    and related fields of [`go/build`](https://pkg.go.dev/go/build)'s `Package`. Files are selected by the same
+    rules, except that the build constraints of `.syso` objects are not read since they are binary.
+
+    Parameters:
+        directory: The directory of the package.
+        context: The target configuration.
+
+    Returns:
+        The included files, sorted by name.
+
+    Raises:
+        BuildConstraintError: If the build constraints of a file are malformed.
+    """
+    return _matching_files(directory, context, _SOURCE_EXTENSIONS)
+
+
+def parse_embed_patterns(source: str) -> list[str]:
+    """
+    Extract the patterns of the [`//go:embed`](https://pkg.go.dev/embed) directives of a Go source file, which
+    are separated by spaces and may be quoted with double quotes or backquotes. Only lines that begin with the
+    directive are considered. A directive that cannot be parsed is ignored from where the error occurs, as the
+    compiler reports it.
+
+    Returns:
+        The patterns in the order they appear, which may have the `all:` prefix.
+    """
+    import json
+
+    patterns: list[str] = []
+    for line in source.splitlines():
+        directive = line.lstrip()
+        if not directive.startswith(_EMBED_DIRECTIVE):
+            continue
+
+        args = directive.removeprefix(_EMBED_DIRECTIVE)
+        if args and not args[0].isspace():
+            continue
+
+        args = args.strip()
+        while args:
+            if args[0] == "`":
+                if (end := args.find("`", 1)) < 0:
+                    break
+
+                pattern, args = args[1:end], args[end + 1 :]
+            elif args[0] == '"':
+                if (match := _QUOTED_PATTERN.match(args)) is None:
+                    break
+
+                # The escape sequences of Go strings that are valid in paths are the same as those of JSON
+                try:
+                    pattern = json.loads(match.group())
+                except ValueError:
+                    break
+
+                args = args[match.end() :]
+            else:
+                pattern = args.split(maxsplit=1)[0]
+                args = args[len(pattern) :]
+
+            if args and not args[0].isspace():
+                break
+
+            patterns.append(pattern)
+            args = args.lstrip()
+
+    return patterns
+
+
+def embed_files(directory: str | PathLike[str], patterns: Iterable[str]) -> list[Path]:
+    """
+    Resolve [`//go:embed`](https://pkg.go.dev/embed) patterns relative to the directory of a package like the
+    `go` command does. Patterns use the syntax of [`path.Match`](https://pkg.go.dev/path#Match), in which `*`
+    does not match `/`. A pattern that matches a directory embeds the files within it recursively, except for
+    those whose names begin with `.` or `_` unless the pattern has the `all:` prefix. Files of other modules,
+    whose directories contain a `go.mod` file, are never embedded. Invalid patterns and patterns that match
+    nothing are ignored, as the compiler reports them.
+
+    Parameters:
+        directory: The directory of the package.
+        patterns: The [patterns][dda.utils.go.packages.parse_embed_patterns] of the source files of the package.
+
+    Returns:
+        The embedded files, sorted.
+    """
+    import os
+
+    from dda.utils.fs import Path
+
+    directory = Path(directory)
+    files: set[Path] = set()
+    for pattern in patterns:
+        glob = pattern.removeprefix("all:")
+        include_hidden = glob != pattern
+        if glob in {"", "."} or glob.startswith("/") or any(part in {"", ".", ".."} for part in glob.split("/")):
+            continue
+
+        for match in _glob(directory, glob.split("/")):
+            # Every directory between the package and the match must belong to the same module
+            if any((parent / "go.mod").is_file() for parent in (match, *match.parents) if directory in parent.parents):
+                continue
+
+            if match.is_file():
+                files.add(match)
+                continue
+
+            for root, dirs, names in os.walk(match):
+                dirs[:] = [
+                    name
+                    for name in dirs
+                    if name not in _VCS_DIRECTORIES
+                    and (include_hidden or not name.startswith((".", "_")))
+                    and not os.path.isfile(os.path.join(root, name, "go.mod"))
+                ]
+                files.update(
+                    Path(root, name)
+                    for name in names
+                    if (include_hidden or not name.startswith((".", "_"))) and os.path.isfile(os.path.join(root, name))
+                )
+
+    return sorted(files)
+
+
+def affected_packages(
+    packages: Iterable[PackageImports],
+    changed_files: Iterable[str | PathLike[str]],
+    root: str | PathLike[str],
+    *,
+    depth: int | None = None,
+    tests: bool = True,
+) -> list[str]:
+    """
+    Select the packages of a module that may be affected by changes to some files. Each file belongs to the
+    package of the closest directory containing it, so that changes to files such as `testdata` or embedded
+    assets are attributed to their package. The packages that import an affected package are then affected in
+    turn. Changes to the `go.mod` and `go.sum` files or to the `vendor` directory affect every package.
+
+    Parameters:
+        packages: Every package of the module, with their
+            [directories][dda.utils.go.packages.PackageImports.directory] and imports.
+        changed_files: The changed files, relative to the root unless absolute. Files that were deleted are
+            attributed to the package of their former directory if it still exists.
+        root: The root directory of the module.
+        depth: The maximum number of imports between a changed package and an affected package, such as `1` to
+            only include the packages that import a changed package directly. By default, every package that
+            imports a changed package transitively is affected.
+        tests: Whether the packages whose tests import an affected package are themselves affected. Packages
+            that only import an affected package in their tests do not affect their own importers.
+
+    Returns:
+        The import paths of the affected packages, sorted.
+    """
+    import os
+
+    from dda.utils.fs import Path
+
+    root = Path(root).resolve()
+    packages = list(packages)
+    by_directory = {
+        os.path.normcase(Path(package.directory).resolve()): package.import_path
+        for package in packages
+        if package.directory
+    }
+
+    changed: set[str] = set()
+    for changed_file in changed_files:
+        path = root / changed_file
+        try:
+            relative = path.resolve().relative_to(root)
+        except ValueError:
+            continue
+
+        if relative.parts and (relative.parts[0] == "vendor" or str(relative) in {"go.mod", "go.sum"}):
+            return sorted(by_directory.values())
+
+        # Walk up from the directory of the file until a package is found, without leaving the root
+        directory = path.resolve().parent
+        while (import_path := by_directory.get(os.path.normcase(directory))) is None and directory != root:
+            directory = directory.parent
+
+        if import_path is not None:
+            changed.add(import_path)
+
+    importers: dict[str, set[str]] = {}
+    test_importers: dict[str, set[str]] = {}
+    for package in packages:
+        for imported in package.imports:
+            importers.setdefault(imported, set()).add(package.import_path)
+        for imported in (*package.test_imports, *package.xtest_imports):
+            test_importers.setdefault(imported, set()).add(package.import_path)
+
+    # Packages that are only affected through their tests are tracked separately as they do not propagate
+    affected = set(changed)
+    affected_tests: set[str] = set()
+    frontier = changed
+    distance = 0
+    while frontier and (depth is None or distance < depth):
+        distance += 1
+        if tests:
+            affected_tests.update(
+                importer for import_path in frontier for importer in test_importers.get(import_path, ())
+            )
+        frontier = {importer for import_path in frontier for importer in importers.get(import_path, ())} - affected
+        affected |= frontier
+
+    return sorted(affected | affected_tests)
+
+
+def _matching_files(directory: str | PathLike[str], context: BuildContext, extensions: frozenset[str]) -> list[Path]:
+    from dda.utils.fs import Path
+    from dda.utils.go.constraints import matches_filename, parse_build_constraints
+
+    files: list[Path] = []
+    for entry in sorted(Path(directory).iterdir(), key=lambda entry: entry.name):
+        name = entry.name
+        if (
+            entry.suffix not in extensions
+            or name.endswith("_test.go")
+            or name.startswith(("_", "."))
+            or not entry.is_file()
+            or not matches_filename(name, context)
+        ):
+            continue
+
+        if entry.suffix == ".syso" or parse_build_constraints(entry).evaluate(context):
+            files.append(entry)
+
+    return files
+
+
+def _glob(directory: Path, segments: list[str]) -> list[Path]:
+    import os
+
+    matches = [directory]
+    for segment in segments:
+        if (regex := _glob_regex(segment)) is None:
+            return []
+
+        candidates: list[Path] = []
+        for parent in matches:
+            try:
+                names = sorted(os.listdir(parent))
+            except OSError:
+                continue
+
+            candidates.extend(parent / name for name in names if regex.fullmatch(name))
+        matches = candidates
+
+    return matches
+
+
+def _glob_regex(segment: str) -> re.Pattern[str] | None:
+    # Translate the syntax of `path.Match`, which has escapes and no special meaning for leading dots
+    parts: list[str] = []
+    chars = iter(segment)
+    for char in chars:
+        if char == "*":
+            parts.append(".*")
+        elif char == "?":
+            parts.append(".")
+        elif char == "\\":
+            if (escaped := next(chars, None)) is None:
+                return None
+            parts.append(re.escape(escaped))
+        elif char == "[":
+            class_parts = ["["]
+            if (char := next(chars, None)) == "^":
+                class_parts.append("^")
+                char = next(chars, None)
+            if char in {None, "]", "-"}:
+                return None
+            while char != "]":
+                if char is None:
+                    return None
+                if char == "\\" and (char := next(chars, None)) is None:
+                    return None
+                class_parts.append("-" if char == "-" else re.escape(char))
+                char = next(chars, None)
+            parts.append("".join(class_parts) + "]")
+        else:
+            parts.append(re.escape(char))
+
+    return re.compile("".join(parts), re.DOTALL)
+
+
+_EMBED_DIRECTIVE = "//go:embed"
+_QUOTED_PATTERN = re.compile(r'"(?:[^"\\]|\\.)*"')
+_VCS_DIRECTORIES = frozenset({".bzr", ".git", ".hg", ".svn"})
+# https://github.com/golang/go/blob/master/src/go/build/build.go (Context.Import)
+_SOURCE_EXTENSIONS = frozenset({
+    ".go",
+    ".c",
+    ".cc",
+    ".cpp",
+    ".cxx",
+    ".m",
+    ".h",
+    ".hh",
+    ".hpp",
+    ".hxx",
+    ".f",
+    ".F",
+    ".for",
+    ".f90",
+    ".s",
+    ".S",
+    ".sx",
+    ".swig",
+    ".swigcxx",
+    ".syso",
+})
@@ -0,0 +1,44 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from msgspec import Struct
+
+
+class CommandSpec(Struct, frozen=True):
+    """
+    A run of the `go` command that would take place, as described by a [`Plan`][dda.utils.go.plan.Plan].
+    Converting it to a string gives the command line that runs it in a POSIX shell, such as
+    `cd /src && GOOS=linux /usr/bin/go build ./...`.
+    """
+
+    command: list[str]
+    """The full command, starting with the path to the binary."""
+    env_vars: dict[str, str]
+    """The environment variables that would differ from those of the current process."""
+    cwd: str
+    """The absolute path to the working directory."""
+
+    def __str__(self) -> str:
+        import shlex
+
+        parts = [f"cd {shlex.quote(self.cwd)} &&"]
+        parts.extend(f"{name}={shlex.quote(value)}" for name, value in sorted(self.env_vars.items()))
+        parts.append(shlex.join(self.command))
+        return " ".join(parts)
+
+
+class Plan(Struct, frozen=True):
+    """
+    The commands that a method of the [`Go`][dda.tools.go.Go] tool would run, as returned instead of running
+    them when `dry_run` is enabled.
+    """
+
+    commands: tuple[CommandSpec, ...] = ()
+    """The commands, in the order they would run."""
+    warnings: tuple[str, ...] = ()
+    """Problems found while planning, such as conflicting flags or targets that would not be built."""
+
+    def __str__(self) -> str:
+        return "\n".join(str(command) for command in self.commands)
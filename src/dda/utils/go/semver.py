@@ -0,0 +1,65 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+
+
+def compare_semver(v1: str, v2: str) -> int:
+    """
+    Compare two module versions following [Semantic Versioning](https://semver.org) precedence, as the `go`
+    command does. Build metadata such as `+incompatible` is ignored and invalid versions sort before valid ones.
+
+    Returns:
+        A negative number if `v1` is older than `v2`, zero if they are equal, and a positive number if `v1` is
+        newer.
+    """
+    key1, key2 = semver_key(v1), semver_key(v2)
+    return (key1 > key2) - (key1 < key2)
+
+
+def semver_key(version: str) -> tuple:
+    """
+    Returns:
+        A key suitable for sorting module versions, e.g. `sorted(versions, key=semver_key)`.
+    """
+    if (match := _SEMVER_PATTERN.match(version)) is None:
+        return (0,)
+
+    major, minor, patch, prerelease = match.groups()
+    if prerelease is None:
+        # Releases sort after all of their prereleases
+        prerelease_key: tuple = ((2,),)
+    else:
+        prerelease_key = tuple(
+            (1, int(identifier), "") if identifier.isdigit() else (1, 2**63, identifier)
+            for identifier in prerelease.split(".")
+        )
+
+    return (1, int(major), int(minor or 0), int(patch or 0), prerelease_key)
+
+
+def is_prerelease(version: str) -> bool:
+    """
+    Whether a module version is a prerelease, such as `v1.2.0-rc.1` or a pseudo-version like
+    `v0.0.0-20240101000000-abcdef123456`. Invalid versions are not prereleases.
+    """
+    return (match := _SEMVER_PATTERN.match(version)) is not None and match.group(4) is not None
+
+
+def semver_major(version: str) -> int | None:
+    """
+    Returns:
+        The major version of a module version, such as 2 for `v2.1.0+incompatible`, or `None` if the version is
+        invalid.
+    """
+    return None if (match := _SEMVER_PATTERN.match(version)) is None else int(match.group(1))
+
+
+# https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+_SEMVER_PATTERN = re.compile(
+    r"^v(0|[1-9]\d*)(?:\.(0|[1-9]\d*))?(?:\.(0|[1-9]\d*))?"
+    r"(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?"
+    r"(?:\+[0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*)?$"
+)
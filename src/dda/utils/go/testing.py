@@ -0,0 +1,316 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from enum import StrEnum
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    import queue
+    import subprocess
+    from collections.abc import Iterable, Iterator, Mapping
+    from typing import IO
+
+
+class TestAction(StrEnum):
+    """
+    The actions of [`TestEvent`][dda.utils.go.testing.TestEvent] objects. All values except `STDOUT` and `STDERR`
+    are defined by [`go test -json`](https://pkg.go.dev/cmd/test2json).
+    """
+
+    __test__ = False
+
+    START = "start"
+    RUN = "run"
+    PAUSE = "pause"
+    CONT = "cont"
+    PASS = "pass"
+    BENCH = "bench"
+    FAIL = "fail"
+    OUTPUT = "output"
+    SKIP = "skip"
+    BUILD_OUTPUT = "build-output"
+    BUILD_FAIL = "build-fail"
+    STDOUT = "stdout"
+    """A line of standard output that is not JSON."""
+    STDERR = "stderr"
+    """A line of standard error, such as compilation errors emitted by older toolchains."""
+
+
+class TestTimeoutError(Exception):
+    """
+    Raised when `go test` is killed because its `-timeout` elapsed.
+
+    Parameters:
+        running_at_timeout: The tests that were still running, which started but never passed, failed or were
+            skipped, in the order they started.
+        packages: The packages whose tests timed out.
+        run: The outcome of the run up to the timeout.
+    """
+
+    __test__ = False
+
+    def __init__(self, running_at_timeout: list[str], packages: list[str], run: TestRun) -> None:
+        super().__init__(running_at_timeout, packages)
+
+        self.__running_at_timeout = running_at_timeout
+        self.__packages = packages
+        self.__run = run
+
+    @property
+    def running_at_timeout(self) -> list[str]:
+        return self.__running_at_timeout
+
+    @property
+    def packages(self) -> list[str]:
+        return self.__packages
+
+    @property
+    def run(self) -> TestRun:
+        return self.__run
+
+    def __str__(self) -> str:
+        message = f"Tests timed out in: {', '.join(self.__packages)}"
+        if self.__running_at_timeout:
+            message += f"\nStill running: {', '.join(self.__running_at_timeout)}"
+
+        return message
+
+
+class TestEvent(Struct, frozen=True, rename="pascal"):
+    """
+    A single event emitted by `go test -json`.
+    """
+
+    __test__ = False
+
+    action: str
+    """The [action][dda.utils.go.testing.TestAction] of the event."""
+    package: str = ""
+    """The package being tested."""
+    test: str = ""
+    """The test, or an empty string for package-level events."""
+    time: str = ""
+    """The time at which the event occurred, in RFC 3339 format."""
+    elapsed: float | None = None
+    """The duration of the test or package in seconds, for `pass` and `fail` events."""
+    output: str = ""
+    """The output, for `output` events and lines that are not JSON."""
+    import_path: str = ""
+    """The package being built, for `build-output` and `build-fail` events."""
+    failed_build: str = ""
+    """The package that failed to build, causing the tests of this package to fail."""
+
+
+class TestRun(Struct, frozen=True):
+    """
+    The outcome of [running tests][dda.tools.go.Go.run_tests].
+    """
+
+    __test__ = False
+
+    events: tuple[TestEvent, ...]
+    """Every event, in the order they were emitted."""
+    exit_code: int
+    """The exit code of the `go test` process."""
+    warnings: tuple[str, ...] = ()
+    """Problems with the command, such as conflicts with the `GOFLAGS` environment variable."""
+
+    @property
+    def passed(self) -> bool:
+        return self.exit_code == 0
+
+    @property
+    def failures(self) -> list[str]:
+        """
+        The tests that failed, in the `package.Test` format.
+        """
+        return [
+            f"{event.package}.{event.test}"
+            for event in self.events
+            if event.action == TestAction.FAIL and event.test
+        ]
+
+
+def decode_test_event(line: str) -> TestEvent:
+    """
+    Decode a line of `go test -json` output. Lines that are not valid JSON are returned as events with the
+    [`STDOUT`][dda.utils.go.testing.TestAction.STDOUT] action rather than raising an error.
+    """
+    from msgspec import DecodeError
+    from msgspec.json import decode
+
+    if line.startswith("{"):
+        try:
+            return decode(line, type=TestEvent)
+        except DecodeError:
+            pass
+
+    return TestEvent(action=TestAction.STDOUT, output=line)
+
+
+def run_pattern(tests: Iterable[str] = (), subtests: Mapping[str, Iterable[str]] | None = None) -> str:
+    """
+    Build a value for the `-run` flag that selects exactly the given tests, unlike a raw regular expression which
+    also selects every test whose name merely contains it. Regular expression metacharacters in the names are
+    escaped.
+
+    Example usage:
+
+    ```python
+    run_pattern(["TestA", "TestB"], {"TestC": ["Sub", "Nested/Sub"]})
+    # ^(TestA|TestB)$|^TestC$/^Sub$|^TestC$/^Nested$/^Sub$
+    ```
+
+    Parameters:
+        tests: The names of top-level tests to run along with all of their subtests.
+        subtests: A mapping of top-level tests to the subtests of theirs to run, which may be nested with `/`
+            separators. A test that is also part of `tests`, or that has no subtests, runs in its entirety.
+
+    Returns:
+        The pattern, or an empty string if no test was given.
+    """
+    tests = list(dict.fromkeys(tests))
+    alternatives = []
+    for test, names in (subtests or {}).items():
+        if test in tests:
+            continue
+
+        if not (names := list(dict.fromkeys(names))):
+            tests.append(test)
+            continue
+
+        # Each element of a subtest name is matched against the corresponding level of the test hierarchy
+        alternatives.extend(
+            "/".join(f"^{_quote_meta(part)}$" for part in (test, *name.split("/"))) for name in names
+        )
+
+    if tests:
+        quoted = "|".join(_quote_meta(test) for test in tests)
+        alternatives.insert(0, f"^{quoted}$" if len(tests) == 1 else f"^({quoted})$")
+
+    return "|".join(alternatives)
+
+
+class TestStream:
+    """
+    The live events of a running `go test -json` process, available by iterating over the instance. Iteration
+    ends once the process exits, after which the [`exit_code`][dda.utils.go.testing.TestStream.exit_code] and
+    [`passed`][dda.utils.go.testing.TestStream.passed] properties are set.
+
+    Lines of standard error are interleaved with the JSON events as
+    [`STDERR`][dda.utils.go.testing.TestAction.STDERR] events.
+
+    Tests are tracked as they start and finish, so that those still running when a package
+    [times out][dda.utils.go.testing.TestStream.timed_out] can be determined.
+
+    Parameters:
+        process: The `go test -json` process, whose standard output and standard error are pipes.
+        warnings: Problems with the command that did not prevent it from running.
+    """
+
+    __test__ = False
+
+    def __init__(self, process: subprocess.Popen[str], *, warnings: Iterable[str] = ()) -> None:
+        self.__process = process
+        self.__warnings = list(warnings)
+        self.__exit_code: int | None = None
+        # Dictionaries rather than sets preserve the order in which tests started
+        self.__running: dict[tuple[str, str], None] = {}
+        self.__timed_out: dict[str, None] = {}
+
+    @property
+    def process(self) -> subprocess.Popen[str]:
+        return self.__process
+
+    @property
+    def warnings(self) -> list[str]:
+        """Problems with the command, such as conflicts with the `GOFLAGS` environment variable."""
+        return self.__warnings
+
+    @property
+    def exit_code(self) -> int | None:
+        """The exit code of the process, or `None` if iteration has not finished."""
+        return self.__exit_code
+
+    @property
+    def passed(self) -> bool:
+        """Whether the process exited successfully, meaning that all tests passed."""
+        return self.__exit_code == 0
+
+    @property
+    def timed_out(self) -> list[str]:
+        """The packages whose tests exceeded the `-timeout` of `go test`, in the order they timed out."""
+        return list(self.__timed_out)
+
+    def running(self, package: str | None = None) -> list[str]:
+        """
+        The tests that started but have not yet passed, failed or been skipped, in the order they started. Once
+        iteration has finished, these are the tests that never completed, such as those that were running when
+        their package timed out.
+
+        Parameters:
+            package: The package whose tests to return, defaulting to all packages.
+        """
+        return [test for test_package, test in self.__running if package is None or test_package == package]
+
+    def __track(self, event: TestEvent) -> None:
+        if event.test and event.action == TestAction.RUN:
+            self.__running[event.package, event.test] = None
+        elif event.test and event.action in {TestAction.PASS, TestAction.FAIL, TestAction.SKIP}:
+            self.__running.pop((event.package, event.test), None)
+        elif event.action == TestAction.OUTPUT and event.output.startswith(_TIMEOUT_PANIC):
+            self.__timed_out[event.package] = None
+
+    def __iter__(self) -> Iterator[TestEvent]:
+        import queue
+        import threading
+
+        # Both streams must be consumed concurrently to prevent the process from blocking on a full pipe
+        events: queue.Queue[TestEvent | None] = queue.Queue()
+        readers = [
+            threading.Thread(target=_read_events, args=(stream, events, stderr), daemon=True)
+            for stream, stderr in ((self.__process.stdout, False), (self.__process.stderr, True))
+            if stream is not None
+        ]
+        for reader in readers:
+            reader.start()
+
+        remaining = len(readers)
+        while remaining:
+            event = events.get()
+            if event is None:
+                remaining -= 1
+            else:
+                self.__track(event)
+                yield event
+
+        for reader in readers:
+            reader.join()
+
+        self.__exit_code = self.__process.wait()
+
+
+def _read_events(stream: IO[str], events: queue.Queue[TestEvent | None], stderr: bool) -> None:  # noqa: FBT001
+    try:
+        # Iterating over a text stream only ever produces complete lines, so buffered writes by the
+        # process can never result in partial JSON objects
+        for line in stream:
+            events.put(TestEvent(action=TestAction.STDERR, output=line) if stderr else decode_test_event(line))
+    finally:
+        events.put(None)
+
+
+# The message printed by the `testing` package when the `-timeout` elapses
+_TIMEOUT_PANIC = "panic: test timed out after "
+
+
+def _quote_meta(text: str) -> str:
+    # Equivalent to `regexp.QuoteMeta`, with `/` also escaped as the `testing` package splits patterns on it
+    return "".join(f"\\{c}" if c in _METACHARACTERS else c for c in text)
+
+
+_METACHARACTERS = frozenset("\\.+*?()|[]{}^$/")
@@ -0,0 +1,257 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import threading
+from typing import TYPE_CHECKING
+
+from msgspec import Struct, field
+
+if TYPE_CHECKING:
+    from os import PathLike
+
+    from dda.utils.go.version import Version
+
+
+class ToolchainError(Exception):
+    """
+    Raised when a `go` binary cannot be queried.
+    """
+
+
+class GoEnv(Struct, frozen=True):
+    """
+    The environment of a toolchain, as reported by `go env -json`.
+    """
+
+    gopath: str = ""
+    goroot: str = ""
+    gocache: str = ""
+    gomodcache: str = ""
+    goflags: str = ""
+    goos: str = ""
+    goarch: str = ""
+    cgo_enabled: bool = False
+    raw: dict[str, str] = field(default_factory=dict)
+    """All variables, including those without a dedicated field."""
+
+    @classmethod
+    def from_dict(cls, raw: dict[str, str]) -> GoEnv:
+        return cls(
+            gopath=raw.get("GOPATH", ""),
+            goroot=raw.get("GOROOT", ""),
+            gocache=raw.get("GOCACHE", ""),
+            gomodcache=raw.get("GOMODCACHE", ""),
+            goflags=raw.get("GOFLAGS", ""),
+            goos=raw.get("GOOS", ""),
+            goarch=raw.get("GOARCH", ""),
+            cgo_enabled=raw.get("CGO_ENABLED", "0") == "1",
+            raw=raw,
+        )
+
+
+class Toolchain:
+    """
+    Information about a `go` binary that is computed at most once per process. The cache is keyed by the resolved
+    path of the binary, so every instance referring to the same binary shares the same information. All methods
+    are safe for concurrent use.
+
+    The binary is queried with `GOTOOLCHAIN=local` so that the information describes the binary itself rather than
+    a toolchain it would switch to.
+
+    Parameters:
+        path: The name or path of the `go` binary.
+    """
+
+    def __init__(self, path: str | PathLike[str] = "go") -> None:
+        import os
+        import shutil
+
+        self.__path = os.path.realpath(shutil.which(path) or path)
+
+    @property
+    def path(self) -> str:
+        """The resolved path to the `go` binary."""
+        return self.__path
+
+    def version(self) -> Version:
+        """
+        Returns:
+            The version of the toolchain, as reported by `go version`.
+        """
+        with _LOCK:
+            info = _CACHE.setdefault(self.__path, _ToolchainInfo())
+            if info.version is None:
+                from dda.utils.go.version import parse_version
+
+                # go version go1.22.3 linux/amd64
+                output = self.__run("version").strip().removeprefix("go version ")
+                info.version = parse_version(output.rsplit(" ", 1)[0])
+
+            return info.version
+
+    def env(self, key: str) -> str:
+        """
+        Returns:
+            The value of the given `go env` variable, or an empty string if it is unknown.
+        """
+        return self.__env().get(key, "")
+
+    def environment(self) -> GoEnv:
+        """
+        Returns:
+            All `go env` variables, loaded with a single invocation of the binary.
+        """
+        return GoEnv.from_dict(dict(self.__env()))
+
+    def invalidate(self) -> None:
+        """
+        Discard the cached information about this binary.
+        """
+        with _LOCK:
+            _CACHE.pop(self.__path, None)
+
+    def __env(self) -> dict[str, str]:
+        with _LOCK:
+            info = _CACHE.setdefault(self.__path, _ToolchainInfo())
+            if info.env is None:
+                from msgspec.json import decode
+
+                info.env = decode(self.__run("env", "-json"), type=dict[str, str])
+
+            return info.env
+
+    def __run(self, *args: str) -> str:
+        import os
+        import subprocess
+
+        command = [self.__path, *args]
+        try:
+            process = subprocess.run(
+                command,
+                capture_output=True,
+                encoding="utf-8",
+                env={**os.environ, "GOTOOLCHAIN": "local"},
+                check=False,
+            )
+        except OSError as e:
+            msg = f"Unable to run `{self.__path}`: {e}"
+            raise ToolchainError(msg) from None
+
+        if process.returncode:
+            msg = f"Command failed with exit code {process.returncode}: {command}\n{process.stderr}"
+            raise ToolchainError(msg)
+
+        return process.stdout
+
+
+def split_goflags(value: str) -> list[str]:
+    """
+    Split the value of the `GOFLAGS` environment variable into flags, in the same way as the `go` command. Flags
+    are separated by whitespace and may be enclosed in single or double quotes, which are removed.
+
+    Raises:
+        ValueError: If a quote is not terminated.
+    """
+    flags: list[str] = []
+    index = 0
+    while index < len(value):
+        if value[index].isspace():
+            index += 1
+            continue
+
+        # No unescaping is done within quotes
+        if (quote := value[index]) in {"'", '"'}:
+            end = value.find(quote, index + 1)
+            if end == -1:
+                msg = f"Unterminated {quote} string in GOFLAGS: {value}"
+                raise ValueError(msg)
+
+            flags.append(value[index + 1 : end])
+            index = end + 1
+            continue
+
+        end = index
+        while end < len(value) and not value[end].isspace():
+            end += 1
+
+        flags.append(value[index:end])
+        index = end
+
+    return flags
+
+
+def find_binary(path: str | PathLike[str] | None = None) -> str | None:
+    """
+    Locate the `go` binary using the following order of precedence:
+
+    1. The given path
+    2. `$GOROOT/bin/go`, if the `GOROOT` environment variable is set and the binary exists
+    3. The first `go` binary on `PATH`
+
+    As a result, `GOROOT` takes precedence over `PATH` when they refer to different installations. Unlike
+    [`resolve_binary`][dda.utils.go.toolchain.resolve_binary], the binary is not verified.
+
+    Returns:
+        The path to the binary, or `None` if no binary could be found.
+    """
+    import os
+
+    from dda.utils.platform import PLATFORM_ID, which
+
+    if path is not None:
+        return os.fspath(path)
+
+    if goroot := os.environ.get("GOROOT"):
+        binary = os.path.join(goroot, "bin", "go.exe" if PLATFORM_ID == "windows" else "go")
+        if os.path.isfile(binary):
+            return binary
+
+    return which("go")
+
+
+def resolve_binary(path: str | PathLike[str] | None = None, *, min_version: Version | None = None) -> str:
+    """
+    [Locate][dda.utils.go.toolchain.find_binary] the `go` binary and verify that it can run `go version`.
+
+    Parameters:
+        path: An explicit path to the binary, which takes precedence over the environment.
+        min_version: The minimum version that the binary must have.
+
+    Returns:
+        The path to the binary.
+
+    Raises:
+        ToolchainError: If no binary is found, it cannot be executed or it is older than `min_version`.
+    """
+    import os
+
+    binary = find_binary(path)
+    if binary is None:
+        msg = "Unable to find the `go` binary, set the `GOROOT` environment variable or add it to `PATH`"
+        raise ToolchainError(msg)
+
+    if not os.path.isfile(binary):
+        msg = f"The `go` binary does not exist: {binary}"
+        raise ToolchainError(msg)
+
+    if not os.access(binary, os.X_OK):
+        msg = f"The `go` binary is not executable: {binary}"
+        raise ToolchainError(msg)
+
+    version = Toolchain(binary).version()
+    if min_version is not None and version < min_version:
+        msg = f"The `go` binary is too old, {min_version} or later is required but found {version}: {binary}"
+        raise ToolchainError(msg)
+
+    return binary
+
+
+class _ToolchainInfo(Struct):
+    version: Version | None = None
+    env: dict[str, str] | None = None
+
+
+_LOCK = threading.Lock()
+_CACHE: dict[str, _ToolchainInfo] = {}
@@ -0,0 +1,148 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+from typing import TYPE_CHECKING
+
+from msgspec import Struct
+
+if TYPE_CHECKING:
+    from collections.abc import Iterable
+
+
+class Action(Struct, frozen=True):
+    """
+    A command run by the `go` command while building, as printed with the `-x` flag.
+    """
+
+    tool: str
+    """The name of the program, such as `compile`, `link`, `asm` or `gcc`."""
+    args: tuple[str, ...]
+    """The arguments passed to the program."""
+    package: str = ""
+    """The import path of the package that the action belongs to, or an empty string if unknown."""
+    directory: str = ""
+    """The working directory of the action."""
+
+
+def parse_build_trace(lines: Iterable[str]) -> list[Action]:
+    """
+    Parse the commands printed by `go build -x`. Commands are split using shell quoting rules and the
+    here-documents used to write intermediate files are skipped.
+
+    The package of an action is taken from its `-p` flag when present, otherwise it is inferred from the
+    temporary `$WORK/bNNN` directory it refers to, which is how link actions are associated with their main
+    package.
+
+    Returns:
+        The actions, in the order they ran.
+    """
+    import shlex
+
+    actions: list[Action] = []
+    work_packages: dict[str, str] = {}
+    directory = ""
+    heredoc_end: str | None = None
+    for raw_line in lines:
+        line = raw_line.rstrip("\r\n")
+        if heredoc_end is not None:
+            if line == heredoc_end:
+                heredoc_end = None
+            continue
+
+        if not (text := line.split(" # internal", 1)[0].strip()):
+            continue
+
+        # Intermediate files such as import configurations are written with here-documents
+        if match := _HEREDOC_PATTERN.search(text):
+            heredoc_end = match.group(1)
+            continue
+
+        try:
+            parts = shlex.split(text, posix=True)
+        except ValueError:
+            # Unbalanced quotes can only come from output that is not a command, such as compiler errors
+            continue
+
+        # Some commands are prefixed with environment variables, such as `GOROOT='/usr/local/go' .../link`
+        while parts and _ASSIGNMENT_PATTERN.match(parts[0]):
+            parts.pop(0)
+
+        if not parts or parts[0].startswith("#"):
+            continue
+
+        if parts[0] == "cd" and len(parts) == 2:  # noqa: PLR2004
+            directory = parts[1]
+            continue
+
+        # Build directories are referred to both through the variable and its resolved value
+        work_dirs = {match.group(1) for arg in parts for match in _WORK_DIR_PATTERN.finditer(arg)}
+        tool = _tool_name(parts[0])
+        package = _flag_value(parts, "-p") if tool in _PACKAGE_TOOLS else ""
+        if package:
+            for work_dir in work_dirs:
+                work_packages.setdefault(work_dir, package)
+        else:
+            package = next((work_packages[d] for d in sorted(work_dirs) if d in work_packages), "")
+
+        actions.append(Action(tool=tool, args=tuple(parts[1:]), package=package, directory=directory))
+
+    return actions
+
+
+def actions_by_package(actions: Iterable[Action]) -> dict[str, list[Action]]:
+    """
+    Group actions by their package, preserving the order in which packages were first encountered. Actions
+    without a known package are grouped under an empty string.
+    """
+    groups: dict[str, list[Action]] = {}
+    for action in actions:
+        groups.setdefault(action.package, []).append(action)
+
+    return groups
+
+
+def pgo_profile(actions: Iterable[Action]) -> str:
+    """
+    Determine which profile, if any, a traced build used for
+    [profile-guided optimization](https://go.dev/doc/pgo). Packages that are up to date in the build cache are
+    not compiled, so a build that did nothing can only be assessed with the `force_rebuild` option.
+
+    Returns:
+        The path to the profile as passed to the `go` command, the path to its preprocessed form in the build
+        cache if it was not preprocessed by this build, or an empty string if no package was compiled with one.
+    """
+    compiled_profile = ""
+    for action in actions:
+        # The profile is converted into a more compact format before compiling with Go 1.23 and later
+        if action.tool == "preprofile" and (profile := _flag_value(list(action.args), "-i")):
+            return profile
+
+        if action.tool == "compile" and not compiled_profile:
+            compiled_profile = _flag_value(list(action.args), "-pgoprofile")
+
+    return compiled_profile
+
+
+def _tool_name(program: str) -> str:
+    name = re.split(r"[/\\]", program)[-1]
+    return name.removesuffix(".exe")
+
+
+def _flag_value(parts: list[str], flag: str) -> str:
+    for i, part in enumerate(parts):
+        if part == flag and i + 1 < len(parts):
+            return parts[i + 1]
+        if part.startswith(f"{flag}="):
+            return part.removeprefix(f"{flag}=")
+
+    return ""
+
+
+# The tools of the toolchain that accept the import path of the package being built
+_PACKAGE_TOOLS = frozenset({"asm", "cgo", "compile"})
+_ASSIGNMENT_PATTERN = re.compile(r"^[A-Za-z_][A-Za-z0-9_]*=")
+_HEREDOC_PATTERN = re.compile(r"<<\s*'?(\w+)'?\s*$")
+_WORK_DIR_PATTERN = re.compile(r"(?:\$WORK|go-build\d+)[/\\](b\d+)")
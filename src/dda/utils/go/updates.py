@@ -0,0 +1,70 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from msgspec import Struct
+
+
+class ModuleUpdate(Struct, frozen=True):
+    """
+    A dependency for which a newer version is available, as reported by `go list -m -u`.
+    """
+
+    path: str
+    """The module path, e.g. `golang.org/x/mod`."""
+    current: str
+    """The version selected by the build list, e.g. `v0.16.0`."""
+    latest: str
+    """The newest available version, e.g. `v0.17.0`."""
+    indirect: bool = False
+    """Whether the module is only an indirect dependency of the main module."""
+
+
+def parse_module_updates(output: str, *, indirect: bool = False, prereleases: bool = False) -> list[ModuleUpdate]:
+    """
+    Parse the output of `go list -m -u -json`, which consists of one JSON object per module. The main module and
+    modules that are already at their newest version are skipped.
+
+    Versions are compared with [`compare_semver`][dda.utils.go.semver.compare_semver], so that `+incompatible`
+    versions of modules predating major version suffixes are ordered like any other.
+
+    Parameters:
+        output: The output of the command.
+        indirect: Whether to include modules that are only indirect dependencies.
+        prereleases: Whether to suggest prereleases for modules whose current version is a release. Prereleases
+            are always suggested for modules that are currently at a prerelease or pseudo-version.
+
+    Returns:
+        The available updates, in the order the modules were listed.
+    """
+    import json
+
+    from dda.utils.go.semver import compare_semver, is_prerelease
+
+    decoder = json.JSONDecoder()
+    updates: list[ModuleUpdate] = []
+    index = 0
+    while (index := output.find("{", index)) != -1:
+        module, index = decoder.raw_decode(output, index)
+        if module.get("Main") or (module.get("Indirect", False) and not indirect):
+            continue
+
+        current = module.get("Version", "")
+        latest = (module.get("Update") or {}).get("Version", "")
+        if not latest or compare_semver(latest, current) <= 0:
+            continue
+
+        if not prereleases and is_prerelease(latest) and not is_prerelease(current):
+            continue
+
+        updates.append(
+            ModuleUpdate(
+                path=module.get("Path", ""),
+                current=current,
+                latest=latest,
+                indirect=module.get("Indirect", False),
+            )
+        )
+
+    return updates
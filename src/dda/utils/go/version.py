@@ -0,0 +1,127 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import re
+
+from msgspec import Struct
+
+
+class Version(Struct, frozen=True):
+    """
+    A Go version, as reported by `go version` or used in `go.mod` files. Versions are ordered following the
+    [rules](https://go.dev/doc/toolchain#version) of the `go` command:
+
+    ```
+    1.21 < 1.21rc1 < 1.21rc2 < 1.21.0 < 1.21.1
+    ```
+
+    The zero value sorts before any real version so that unparsed values never satisfy minimum-version gates.
+    """
+
+    major: int = 0
+    minor: int = 0
+    patch: int | None = None
+    """The patch number, or `None` for language versions like `1.21` or prereleases like `1.22rc1`."""
+    prerelease: str = ""
+    """The prerelease identifier, e.g. `rc1` or `beta2`."""
+    devel: bool = False
+    """Whether this is a development build of the toolchain, which sorts after the version it is based on."""
+
+    def compare(self, other: Version) -> int:
+        """
+        Returns:
+            A negative number if this version is older than `other`, zero if they are equal, and a positive number
+            if this version is newer.
+        """
+        key, other_key = self.__key(), other.__key()
+        return (key > other_key) - (key < other_key)
+
+    def at_least(self, major: int, minor: int) -> bool:
+        """
+        Whether this version is at least the given release, including its prereleases. For example, `1.22rc1`
+        is considered to be at least `1.22`.
+        """
+        return self.compare(Version(major=major, minor=minor)) >= 0
+
+    def __lt__(self, other: Version) -> bool:
+        return self.compare(other) < 0
+
+    def __le__(self, other: Version) -> bool:
+        return self.compare(other) <= 0
+
+    def __gt__(self, other: Version) -> bool:
+        return self.compare(other) > 0
+
+    def __ge__(self, other: Version) -> bool:
+        return self.compare(other) >= 0
+
+    def __str__(self) -> str:
+        if self.devel and not self.major:
+            return "devel"
+
+        version = f"go{self.major}.{self.minor}"
+        if self.patch is not None:
+            version += f".{self.patch}"
+        version += self.prerelease
+        return f"devel {version}" if self.devel else version
+
+    def __key(self) -> tuple[int, ...]:
+        # Development builds without a known base version are assumed to come from the tip of the main branch
+        unknown_devel = int(self.devel and not self.major)
+        if self.prerelease:
+            match = _PRERELEASE_PATTERN.match(self.prerelease)
+            kind, number = match.groups() if match else ("", "0")
+            stage = (1, _PRERELEASE_KINDS.get(kind, -1), int(number or 0))
+        elif self.patch is None:
+            stage = (0, 0, 0)
+        else:
+            stage = (2, 0, 0)
+
+        patch = -1 if self.patch is None else self.patch
+        return (unknown_devel, self.major, self.minor, patch, *stage, int(self.devel))
+
+
+def parse_version(version: str) -> Version:
+    """
+    Parse a Go version string. The `go` prefix is optional and any custom toolchain suffix such as
+    `go1.22.3-bigcorp` or `go1.22.3 X:boringcrypto` is ignored.
+
+    Examples:
+        ```python
+        parse_version("go1.21")  # Version(major=1, minor=21)
+        parse_version("go1.21.0")  # Version(major=1, minor=21, patch=0)
+        parse_version("go1.22rc1")  # Version(major=1, minor=22, prerelease="rc1")
+        parse_version("devel go1.23-abcdef")  # Version(major=1, minor=23, devel=True)
+        ```
+
+    Raises:
+        ValueError: If the version is invalid.
+    """
+    text = version.strip()
+    devel = text.startswith("devel")
+    if devel:
+        text = text.removeprefix("devel").strip()
+        # Development builds may or may not embed the version they are based on, e.g.
+        # `devel go1.23-abcdef Tue Jan 1 00:00:00 2024 +0000` or `devel +abcdef Tue Jan 1 00:00:00 2024 +0000`
+        if not text.startswith("go"):
+            return Version(devel=True)
+
+    if (match := _VERSION_PATTERN.match(text)) is None:
+        msg = f"Invalid Go version: {version}"
+        raise ValueError(msg)
+
+    major, minor, patch, prerelease = match.groups()
+    return Version(
+        major=int(major),
+        minor=int(minor or 0),
+        patch=None if patch is None else int(patch),
+        prerelease=prerelease or "",
+        devel=devel,
+    )
+
+
+_VERSION_PATTERN = re.compile(r"^(?:go)?(\d+)(?:\.(\d+))?(?:\.(\d+))?((?:alpha|beta|rc)\d+)?(?:[-+\s].*)?$")
+_PRERELEASE_PATTERN = re.compile(r"^(alpha|beta|rc)(\d*)$")
+_PRERELEASE_KINDS = {"alpha": 0, "beta": 1, "rc": 2}
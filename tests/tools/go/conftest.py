@@ -10,3 +10,21 @@ def get_random_filename():
         return urlsafe_b64encode(urandom(k)).decode("utf-8")
 
     return _get_random_filename
+
+
+@pytest.fixture
+def go_env(request, mocker):
+    """
+    Replace the environment of the `go` binary with that of a linux/amd64 machine, so that tests of the commands
+    that are mocked never run `go env`, for example to look up the flags set with `go env -w`. Tests that run the
+    real binary keep its environment.
+    """
+    if request.node.get_closest_marker("requires_ci"):
+        yield None
+        return
+
+    from dda.utils.process import EnvVars
+
+    env = {"GOOS": "linux", "GOARCH": "amd64", "GOHOSTOS": "linux", "GOHOSTARCH": "amd64"}
+    with EnvVars(exclude=["GOFLAGS"]):
+        yield mocker.patch("dda.utils.go.toolchain.Toolchain.env", side_effect=lambda key: env.get(key, ""))
@@ -3,11 +3,32 @@
 # SPDX-License-Identifier: MIT
 from __future__ import annotations
 
-import platform
+import io
+import json
+import os
+import sys
+import threading
+import time
+from contextlib import contextmanager
+from subprocess import CompletedProcess
 
 import pytest
+from filelock import FileLock, Timeout
 
+from dda.tools.base import ExecutionContext
 from dda.utils.fs import Path
+from dda.utils.go.build import BuildError, RaceUnsupportedError, RetryPolicy, Target, output_lock_file
+from dda.utils.go.cache import CacheStats
+from dda.utils.go.constraints import BuildContext
+from dda.utils.go.diagnostics import Diagnostic
+from dda.utils.go.download import ChecksumError, VerificationError
+from dda.utils.go.formatting import GOFUMPT_VERSION, FormatResult
+from dda.utils.go.moddiff import ModuleChange
+from dda.utils.go.observer import Observer
+from dda.utils.go.testing import TestTimeoutError
+from dda.utils.go.toolchain import ToolchainError
+from dda.utils.go.version import Version
+from dda.utils.process import EnvVars
 
 
 def test_default(app):
@@ -15,6 +36,24 @@ def test_default(app):
         assert context.env_vars == {}
 
 
+@pytest.mark.skip_windows
+def test_path_goroot_over_path(app, mocker, temp_dir):
+    mocker.patch("dda.utils.platform.which", return_value="/usr/bin/go")
+    binary = temp_dir / "bin" / "go"
+    binary.parent.ensure_dir()
+    binary.touch()
+
+    with EnvVars({"GOROOT": str(temp_dir)}):
+        assert app.tools.go.path == str(binary)
+
+
+def test_path_without_goroot(app, mocker):
+    mocker.patch("dda.utils.platform.which", return_value="/usr/bin/go")
+
+    with EnvVars(exclude=["GOROOT"]):
+        assert app.tools.go.path == "/usr/bin/go"
+
+
 class TestPrecedence:
     def test_workspace_file(self, app, temp_dir):
         (temp_dir / "go.work").write_text("stuff\ngo X.Y.Z\nstuff")
@@ -35,6 +74,24 @@ class TestPrecedence:
             assert context.env_vars == {"GOTOOLCHAIN": "goX.Y.Zrc2"}
 
 
+class TestEnsureRequiredVersion:
+    def test_satisfied(self, app, mocker, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22\n")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=22, patch=3))
+
+        app.tools.go.ensure_required_version(temp_dir)
+
+    def test_too_old(self, app, mocker, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22\n")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=21, patch=3))
+
+        with pytest.raises(SystemExit):
+            app.tools.go.ensure_required_version(temp_dir)
+
+        assert app.last_error == "go.mod requires go1.22 but go1.21.3 is installed"
+
+
+@pytest.mark.usefixtures("go_env")
 class TestBuild:
     @pytest.mark.parametrize(
         "call_args",
@@ -64,13 +121,12 @@ class TestBuild:
             ("-trimpath",),
             ("-mod=readonly",),
             (f"-o={output}",),
+            # The race detector is enabled by default on targets that support it
+            ("-race",),
             # ("-v",), # By default verbosity is INFO
             # ("-x",),
         }
 
-        if not (platform.system() == "Windows" and platform.machine() == "arm64"):
-            flags.add(("-race",))
-
         if call_args.get("build_tags"):
             flags.add(("-tags", ",".join(sorted(call_args.get("build_tags", [])))))
         if call_args.get("gcflags"):
@@ -94,6 +150,299 @@ class TestBuild:
         if n_packages > 0:
             assert seen_command_parts[-len(packages) :] == [str(package) for package in packages]
 
+    def test_error(self, app, mocker, temp_dir):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=1,
+                stdout="",
+                stderr="# example.com/foo\n./main.go:3:20: too many arguments in call to f\n\thave (number)\n",
+            ),
+        )
+
+        with pytest.raises(BuildError) as exc_info:
+            app.tools.go.build(".", output="out", cwd=temp_dir)
+
+        error = exc_info.value
+        assert error.exit_code == 1
+        assert error.stderr.startswith("# example.com/foo\n")
+        assert str(error).startswith("Build failed with exit code 1\n")
+        assert error.diagnostics == [
+            Diagnostic(
+                file=str(temp_dir / "main.go"),
+                line=3,
+                column=20,
+                message="too many arguments in call to f\nhave (number)",
+                package="example.com/foo",
+            )
+        ]
+
+    def test_race(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build", return_value="output")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", side_effect={"GOOS": "linux", "GOARCH": "amd64"}.get)
+        display_warning = mocker.patch.object(app, "display_warning")
+
+        with EnvVars(exclude=["GOFLAGS"]):
+            app.tools.go.build(".", output="out", race=True, env_vars={"CGO_ENABLED": "0"})
+
+        assert "-race" in build.call_args.args[0]
+        assert build.call_args.kwargs["env"]["CGO_ENABLED"] == "1"
+        display_warning.assert_called_once_with("Enabling cgo, which is required by the race detector")
+
+    def test_race_unsupported(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build", return_value="output")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", side_effect={"GOOS": "linux", "GOARCH": "amd64"}.get)
+
+        with pytest.raises(RaceUnsupportedError, match="The race detector is not supported on linux/386"):
+            app.tools.go.build(".", output="out", race=True, env_vars={"GOARCH": "386"})
+
+        build.assert_not_called()
+
+    def test_race_default(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build", return_value="output")
+
+        with EnvVars(exclude=["CGO_ENABLED"]):
+            app.tools.go.build(".", output="out")
+
+        assert "-race" in build.call_args.args[0]
+        assert build.call_args.kwargs["env"]["CGO_ENABLED"] == "1"
+
+    def test_race_default_unsupported(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build", return_value="output")
+
+        app.tools.go.build(".", output="out", env_vars={"GOARCH": "386"})
+
+        assert "-race" not in build.call_args.args[0]
+        assert build.call_args.kwargs["env"] == {"GOARCH": "386"}
+
+    def test_no_race(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build", return_value="output")
+
+        app.tools.go.build(".", output="out", race=False)
+
+        assert "-race" not in build.call_args.args[0]
+
+    def test_workspace(self, app, mocker, temp_dir):
+        build = mocker.patch("dda.tools.go.Go._build", return_value="output")
+        (temp_dir / "go.work").write_text("go 1.22\n\nuse ./a\n")
+        (temp_dir / "a").mkdir()
+        (temp_dir / "other").mkdir()
+
+        with EnvVars(exclude=["GOWORK"]), (temp_dir / "other").as_cwd():
+            app.tools.go.build("../a/...", output="out", race=False)
+            app.tools.go.build("example.com/a", output="out", race=False)
+
+        first, second = build.call_args_list
+        assert first.kwargs["env"] == {"GOWORK": str((temp_dir / "go.work").resolve())}
+        assert second.kwargs["env"] is None
+
+    def test_ldflags_vars(self, app, mocker):
+        mocker.patch("dda.tools.go.Go._build", return_value="output")
+
+        app.tools.go.build(".", output="out", ldflags=["-s"], ldflags_vars={"main.Version": "1.0 beta"})
+
+        seen_command_parts = app.tools.go._build.call_args[0][0]  # noqa: SLF001
+        assert "-ldflags=-s '-X=main.Version=1.0 beta'" in seen_command_parts
+
+    @pytest.mark.parametrize("toolchain", ["local", "go1.22.3", "go1.22.3+auto", "go1.23rc1"])
+    def test_toolchain(self, app, mocker, toolchain):
+        build = mocker.patch("dda.tools.go.Go._build")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=21, patch=0))
+
+        app.tools.go.build(".", output="out", env_vars={"FOO": "bar"}, toolchain=toolchain, race=False)
+
+        assert build.call_args.kwargs["env"] == {"FOO": "bar", "GOTOOLCHAIN": toolchain}
+
+    @pytest.mark.parametrize("toolchain", ["1.22.3", "go1.22.3+foo", "gofoo", "auto"])
+    def test_invalid_toolchain(self, app, mocker, toolchain):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", toolchain=toolchain)
+
+        assert app.last_error.startswith(f"Invalid toolchain `{toolchain}`")
+        build.assert_not_called()
+
+    def test_toolchain_unsupported(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=20, patch=14))
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", toolchain="go1.22.3")
+
+        assert app.last_error == "Selecting the toolchain `go1.22.3` requires Go 1.21 or later, found go1.20.14"
+        build.assert_not_called()
+
+    @pytest.mark.parametrize(("mod", "flag"), [("vendor", "-mod=vendor"), ("mod", "-mod=mod"), ("auto", None)])
+    def test_mod(self, app, mocker, mod, flag):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        app.tools.go.build(".", output="out", mod=mod)
+
+        flags = [part for part in build.call_args.args[0] if part.startswith("-mod")]
+        assert flags == ([flag] if flag else [])
+
+    def test_invalid_mod(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", mod="vendored")
+
+        assert app.last_error == "Invalid module mode `vendored`, expected one of: auto, mod, readonly, vendor"
+        build.assert_not_called()
+
+    def test_overlay(self, app, mocker, temp_dir):
+        def build(command_parts, **_kwargs):
+            overlay_file = Path(next(part for part in command_parts if part.startswith("-overlay=")).split("=", 1)[1])
+            replace = json.loads(overlay_file.read_text())["Replace"]
+            assert Path(replace[str(temp_dir / "gen.go")]).read_text() == "package main\n"
+            return "output"
+
+        _build = mocker.patch("dda.tools.go.Go._build", side_effect=build)
+
+        with temp_dir.as_cwd():
+            app.tools.go.build("./cmd", output="out", overlay={"gen.go": "package main\n"})
+
+        command_parts = _build.call_args.args[0]
+        assert command_parts[-1] == "./cmd"
+        assert command_parts[-2].startswith("-overlay=")
+        # The overlay only exists for the duration of the build
+        assert not Path(command_parts[-2].split("=", 1)[1]).exists()
+
+    def test_overlay_cwd(self, app, mocker, temp_dir):
+        def build(command_parts, **_kwargs):
+            overlay_file = Path(next(part for part in command_parts if part.startswith("-overlay=")).split("=", 1)[1])
+            replace = json.loads(overlay_file.read_text())["Replace"]
+            assert Path(replace[str(temp_dir / "cmd" / "gen.go")]).read_text() == "package main\n"
+            assert str(temp_dir / "gen.go") not in replace
+            return "output"
+
+        mocker.patch("dda.tools.go.Go._build", side_effect=build)
+        (temp_dir / "cmd").mkdir()
+
+        app.tools.go.build(".", output="out", overlay={"gen.go": "package main\n"}, cwd=str(temp_dir / "cmd"))
+
+    def test_goflags_conflict(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+        display_warning = mocker.patch.object(app, "display_warning")
+
+        app.tools.go.build(".", output="out", env_vars={"GOFLAGS": "-mod=mod -v"})
+
+        assert build.call_args.kwargs["env"]["GOFLAGS"] == "-mod=mod -v"
+        display_warning.assert_called_once_with(
+            "GOFLAGS sets `-mod=mod` but the command sets `-mod=readonly`, which takes precedence"
+        )
+
+    def test_goflags_from_environment(self, app, mocker):
+        mocker.patch("dda.tools.go.Go._build")
+        go_env = mocker.patch("dda.utils.go.toolchain.Toolchain.env")
+        display_warning = mocker.patch.object(app, "display_warning")
+
+        with EnvVars({"GOFLAGS": "-mod=vendor"}):
+            app.tools.go.build(".", output="out", race=False)
+
+        display_warning.assert_called_once_with(
+            "GOFLAGS sets `-mod=vendor` but the command sets `-mod=readonly`, which takes precedence"
+        )
+        go_env.assert_not_called()
+
+    def test_goflags_unavailable(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", side_effect=ToolchainError("Unable to run `go`"))
+        display_warning = mocker.patch.object(app, "display_warning")
+
+        with EnvVars(exclude=["GOFLAGS"]):
+            app.tools.go.build(".", output="out", race=False)
+
+        build.assert_called_once()
+        display_warning.assert_not_called()
+
+    def test_no_trimpath(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        app.tools.go.build(".", output="out")
+        assert "-trimpath" in build.call_args.args[0]
+
+        app.tools.go.build(".", output="out", trimpath=False)
+        assert "-trimpath" not in build.call_args.args[0]
+
+    def test_extra_args(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        app.tools.go.build("./cmd", output="out", extra_args=["-gcflags", "all=-N -l", "-buildvcs=false"])
+
+        assert build.call_args.args[0][-4:] == ["-gcflags", "all=-N -l", "-buildvcs=false", "./cmd"]
+
+    def test_extra_args_subcommand(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build("./cmd", output="out", extra_args=["install"])
+
+        assert app.last_error == "Extra argument `install` is not a flag"
+        build.assert_not_called()
+
+    def test_pgo(self, app, mocker, temp_dir):
+        build = mocker.patch("dda.tools.go.Go._build")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=21, patch=0))
+        (temp_dir / "cpu.pprof").touch()
+
+        app.tools.go.build(".", output="out", pgo="cpu.pprof", cwd=temp_dir)
+        assert f"-pgo={(temp_dir / 'cpu.pprof').resolve()}" in build.call_args.args[0]
+
+        app.tools.go.build(".", output="out", pgo="off")
+        assert "-pgo=off" in build.call_args.args[0]
+
+        app.tools.go.build(".", output="out")
+        assert not any(part.startswith("-pgo=") for part in build.call_args.args[0])
+
+    def test_pgo_missing_profile(self, app, mocker, temp_dir):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", pgo="cpu.pprof", cwd=temp_dir)
+
+        assert app.last_error == f"PGO profile does not exist: {(temp_dir / 'cpu.pprof').resolve()}"
+        build.assert_not_called()
+
+    def test_pgo_unsupported_toolchain(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", pgo="off", toolchain="go1.20.3")
+
+        assert app.last_error == "Profile-guided optimization requires Go 1.21 or later, found go1.20.3"
+        build.assert_not_called()
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_files(self, app, temp_dir):
+        project = Path(__file__).parent / "fixtures" / "small_go_project"
+        with project.as_cwd():
+            app.tools.go.build(output=(temp_dir / "testbinary").absolute(), files=["main.go", "debug.go"])
+
+        # Build constraints do not apply to files that are named explicitly
+        assert "DEBUG" in app.subprocess.capture(str(temp_dir / "testbinary"))
+
+    def test_files_command(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+        project = Path(__file__).parent / "fixtures" / "small_go_project"
+
+        with project.as_cwd():
+            app.tools.go.build(output="out", files=["main.go", "debug.go"])
+
+        assert build.call_args.args[0][-2:] == [str(project / "main.go"), str(project / "debug.go")]
+
+    def test_files_with_packages(self, app, mocker):
+        build = mocker.patch("dda.tools.go.Go._build")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", files=["main.go"])
+
+        assert app.last_error == "Packages and files cannot be built together"
+        build.assert_not_called()
+
     # This test is quite slow, we'll only run it in CI
     @pytest.mark.requires_ci
     @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
@@ -111,3 +460,1940 @@ class TestBuild:
                 output = app.subprocess.capture(str(temp_dir / "testbinary"))
                 assert output_mark in output
                 # Note: doing both builds in the same test with the same name also allows us to test the force rebuild
+
+
+@pytest.mark.usefixtures("go_env")
+class TestBuildTargets:
+    @pytest.fixture(autouse=True)
+    def _toolchain(self, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.supported_targets",
+            new_callable=mocker.PropertyMock,
+            return_value=frozenset({Target("linux", "amd64"), Target("linux", "arm64"), Target("windows", "amd64")}),
+        )
+        mocker.patch(
+            "dda.tools.go.Go.host_target", new_callable=mocker.PropertyMock, return_value=Target("linux", "amd64")
+        )
+
+    def test_goflags_conflict(self, app, mocker):
+        mocker.patch("dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr=""))
+
+        results = app.tools.go.build_targets(
+            ".", targets=[Target("linux", "amd64")], output="out", mod="vendor", env_vars={"GOFLAGS": "-mod=mod"}
+        )
+
+        assert results[0].succeeded
+        assert results[0].warnings == (
+            "GOFLAGS sets `-mod=mod` but the command sets `-mod=vendor`, which takes precedence",
+        )
+
+    def test_matrix(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            side_effect=[
+                CompletedProcess([], returncode=0, stdout="", stderr=""),
+                CompletedProcess([], returncode=1, stdout="", stderr="compile error"),
+            ],
+        )
+
+        results = app.tools.go.build_targets(
+            ".",
+            targets=[Target("linux", "amd64"), Target("solaris", "mips"), Target("windows", "amd64")],
+            output="dist/{goos}_{goarch}/app",
+            build_tags={"prod"},
+        )
+
+        assert [result.target for result in results] == [
+            Target("linux", "amd64"),
+            Target("solaris", "mips"),
+            Target("windows", "amd64"),
+        ]
+        assert [result.output for result in results] == [
+            Path("dist/linux_amd64/app"),
+            Path("dist/solaris_mips/app"),
+            Path("dist/windows_amd64/app"),
+        ]
+
+        assert results[0].succeeded
+        assert results[1].error == "Unsupported target: solaris/mips"
+        assert results[2].error == "Build failed with exit code 1"
+        assert results[2].stderr == "compile error"
+
+        assert attach.call_count == 2
+        host_call, cross_call = attach.call_args_list
+        assert host_call.args[0][0] == "build"
+        assert "-race" not in host_call.args[0]
+        assert f"-o={Path('dist/linux_amd64/app')}" in host_call.args[0]
+        assert host_call.args[0][-1] == "."
+        assert host_call.kwargs["env"]["GOOS"] == "linux"
+        assert host_call.kwargs["env"].get("CGO_ENABLED") == os.environ.get("CGO_ENABLED")
+        assert cross_call.kwargs["env"]["GOOS"] == "windows"
+        assert cross_call.kwargs["env"]["CGO_ENABLED"] == "0"
+
+    def test_cgo_override(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+
+        app.tools.go.build_targets(targets=[Target("linux", "arm64")], output="out", cgo=True)
+
+        assert attach.call_args.kwargs["env"]["CGO_ENABLED"] == "1"
+
+    def test_toolchain(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+
+        app.tools.go.build_targets(targets=[Target("linux", "arm64")], output="out", toolchain="local")
+
+        assert attach.call_args.kwargs["env"]["GOTOOLCHAIN"] == "local"
+
+    def test_output_stream(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        first, second = (mocker.MagicMock(), mocker.MagicMock())
+        first.stdout = iter(["example.com/foo\n", "example.com/foo/cmd\n"])
+        first.wait.return_value = 0
+        second.stdout = iter(["main.go:3:12: undefined: foo"])
+        second.wait.return_value = 1
+        popen.return_value.__enter__.side_effect = [first, second]
+        stream = io.StringIO()
+
+        results = app.tools.go.build_targets(
+            ".",
+            targets=[Target("linux", "amd64"), Target("windows", "amd64")],
+            output="dist/{goos}_{goarch}/app",
+            output_stream=stream,
+            prefix_output=True,
+        )
+
+        assert stream.getvalue() == (
+            "[linux/amd64] example.com/foo\n"
+            "[linux/amd64] example.com/foo/cmd\n"
+            "[windows/amd64] main.go:3:12: undefined: foo\n"
+        )
+        assert results[0].succeeded
+        assert results[0].stderr == "example.com/foo\nexample.com/foo/cmd\n"
+        assert results[1].error == "Build failed with exit code 1"
+        assert popen.call_args.args[0][0] == "build"
+        assert popen.call_args.kwargs["env_vars"]["GOOS"] == "windows"
+
+    def test_trace(self, app, mocker):
+        trace = [
+            "WORK=/tmp/go-build1\n",
+            "/usr/local/go/pkg/tool/linux_amd64/compile -o $WORK/b001/_pkg_.a -p main ./main.go\n",
+            "/usr/local/go/pkg/tool/linux_amd64/link -o $WORK/b001/exe/a.out $WORK/b001/_pkg_.a\n",
+        ]
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        process = mocker.MagicMock()
+        process.stdout = iter(trace)
+        process.wait.return_value = 0
+        popen.return_value.__enter__.return_value = process
+        stream = io.StringIO()
+
+        results = app.tools.go.build_targets(
+            ".", targets=[Target("linux", "amd64")], output="out", trace=True, trace_stream=stream
+        )
+
+        assert "-x" in popen.call_args.args[0]
+        assert stream.getvalue() == "".join(trace)
+        assert [(action.tool, action.package) for action in results[0].actions] == [
+            ("compile", "main"),
+            ("link", "main"),
+        ]
+
+    def test_no_trace(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], 0, stdout="", stderr="compile -p main ./main.go\n"),
+        )
+
+        results = app.tools.go.build_targets(".", targets=[Target("linux", "amd64")], output="out")
+
+        assert "-x" not in attach.call_args.args[0]
+        assert results[0].actions == ()
+
+    @pytest.mark.skip_windows
+    def test_timeout(self, app, mocker, temp_dir):
+        marker = temp_dir / "alive"
+        # Simulate the compiler being spawned as a child process that outlives its parent
+        child = f"import time; time.sleep(2); open({str(marker)!r}, 'w').close()"
+        script = f"import subprocess, sys, time; subprocess.Popen([sys.executable, '-c', {child!r}]); time.sleep(60)"
+
+        @contextmanager
+        def execution_context(_self, _command):
+            yield ExecutionContext(command=[sys.executable, "-c", script], env_vars={})
+
+        mocker.patch("dda.tools.go.Go.execution_context", execution_context)
+
+        start = time.monotonic()
+        results = app.tools.go.build_targets(
+            targets=[Target("linux", "amd64"), Target("linux", "arm64")], output="out", timeout=0.5
+        )
+
+        assert time.monotonic() - start < 10
+        assert results[0].error == "Build of linux/amd64 timed out after 0.5 seconds"
+        assert results[1].error == "Build of linux/arm64 timed out after 0.5 seconds"
+
+        time.sleep(3)
+        assert not marker.exists()
+
+    def test_cancel(self, app, mocker):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        cancel = threading.Event()
+        cancel.set()
+
+        results = app.tools.go.build_targets(targets=[Target("linux", "amd64")], output="out", cancel=cancel)
+
+        assert results[0].error == "Build of linux/amd64 was cancelled"
+        attach.assert_not_called()
+        popen.assert_not_called()
+
+    def test_incremental(self, app, mocker, temp_dir):
+        def build(command, **_kwargs):
+            output = Path(next(part for part in command if part.startswith("-o=")).removeprefix("-o="))
+            output.parent.ensure_dir()
+            output.touch()
+            return CompletedProcess([], returncode=0, stdout="", stderr="")
+
+        attach = mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="1")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=22, patch=3))
+        (temp_dir / "go.mod").write_text("module example.com/foo\n")
+        (temp_dir / "main.go").write_text("package main\n")
+
+        with temp_dir.as_cwd():
+            targets = [Target("linux", "amd64")]
+            first = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            second = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            forced = app.tools.go.build_targets(
+                ".", targets=targets, output="dist/app", incremental=True, force_rebuild=True
+            )
+            (temp_dir / "main.go").write_text("package main\n\nfunc main() {}\n")
+            changed = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+
+            assert (temp_dir / "dist" / "app.inputs").is_file()
+
+        assert not first[0].cached
+        assert second[0].cached
+        assert second[0].succeeded
+        assert not forced[0].cached
+        assert not changed[0].cached
+        assert attach.call_count == 3
+
+    def test_incremental_workspace(self, app, mocker, temp_dir):
+        def build(command, **_kwargs):
+            output = Path(next(part for part in command if part.startswith("-o=")).removeprefix("-o="))
+            output.parent.ensure_dir()
+            output.touch()
+            return CompletedProcess([], returncode=0, stdout="", stderr="")
+
+        attach = mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="1")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=22, patch=3))
+        (temp_dir / "go.work").write_text("go 1.22\n\nuse (\n\t./app\n\t./lib\n)\n")
+        for name in ("app", "lib"):
+            (temp_dir / name).mkdir()
+            (temp_dir / name / "go.mod").write_text(f"module example.com/{name}\n")
+            (temp_dir / name / f"{name}.go").write_text(f"package {name}\n")
+
+        with EnvVars(exclude=["GOWORK"]), (temp_dir / "app").as_cwd():
+            targets = [Target("linux", "amd64")]
+            first = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            second = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            (temp_dir / "lib" / "lib.go").write_text("package lib\n\nconst X = 1\n")
+            changed = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+
+        assert not first[0].cached
+        assert second[0].cached
+        assert not changed[0].cached
+        assert attach.call_count == 2
+        assert attach.call_args.kwargs["env"]["GOWORK"] == str((temp_dir / "go.work").resolve())
+
+    def test_incremental_cgo_source(self, app, mocker, temp_dir):
+        def build(command, **_kwargs):
+            output = Path(next(part for part in command if part.startswith("-o=")).removeprefix("-o="))
+            output.parent.ensure_dir()
+            output.touch()
+            return CompletedProcess([], returncode=0, stdout="", stderr="")
+
+        attach = mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="1")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=22, patch=3))
+        (temp_dir / "go.mod").write_text("module example.com/foo\n")
+        (temp_dir / "main.go").write_text('package main\n\n// #include "hello.h"\nimport "C"\n')
+        (temp_dir / "hello.h").write_text("void hello(void);\n")
+        (temp_dir / "hello.c").write_text("void hello(void) {}\n")
+
+        with temp_dir.as_cwd():
+            targets = [Target("linux", "amd64")]
+            first = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            second = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            (temp_dir / "hello.c").write_text('#include <stdio.h>\n\nvoid hello(void) { puts("hello"); }\n')
+            changed = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+
+        assert not first[0].cached
+        assert second[0].cached
+        assert not changed[0].cached
+        assert attach.call_count == 2
+
+    def test_incremental_replacement(self, app, mocker, temp_dir):
+        def build(command, **_kwargs):
+            output = Path(next(part for part in command if part.startswith("-o=")).removeprefix("-o="))
+            output.parent.ensure_dir()
+            output.touch()
+            return CompletedProcess([], returncode=0, stdout="", stderr="")
+
+        attach = mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="1")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.version", return_value=Version(major=1, minor=22, patch=3))
+        for name in ("app", "lib"):
+            (temp_dir / name).mkdir()
+            (temp_dir / name / f"{name}.go").write_text(f"package {name}\n")
+        (temp_dir / "app" / "go.mod").write_text(
+            "module example.com/app\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n"
+        )
+        (temp_dir / "lib" / "go.mod").write_text("module example.com/lib\n")
+
+        with EnvVars(exclude=["GOWORK"]), (temp_dir / "app").as_cwd():
+            targets = [Target("linux", "amd64")]
+            first = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            second = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+            (temp_dir / "lib" / "lib.go").write_text("package lib\n\nconst X = 1\n")
+            changed = app.tools.go.build_targets(".", targets=targets, output="dist/app", incremental=True)
+
+        assert not first[0].cached
+        assert second[0].cached
+        assert not changed[0].cached
+        assert attach.call_count == 2
+
+    def test_parallelism(self, app, mocker):
+        # Both builds must be running at the same time for either to complete
+        barrier = threading.Barrier(2, timeout=5)
+
+        def build(command, **kwargs):
+            barrier.wait()
+            goos = kwargs["env"]["GOOS"]
+            return CompletedProcess([], returncode=int(goos == "windows"), stdout="", stderr=f"{goos} output")
+
+        mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+
+        results = app.tools.go.build_targets(
+            targets=[Target("windows", "amd64"), Target("linux", "amd64")], output="out", parallelism=2
+        )
+
+        assert [(result.target, result.stderr, result.succeeded) for result in results] == [
+            (Target("windows", "amd64"), "windows output", False),
+            (Target("linux", "amd64"), "linux output", True),
+        ]
+
+    def test_retry(self, app, mocker):
+        transient = (
+            'go: example.com/foo@v1.0.0: Get "https://proxy.golang.org/example.com/foo/@v/v1.0.0.mod": '
+            "dial tcp: lookup proxy.golang.org: i/o timeout"
+        )
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            side_effect=[
+                CompletedProcess([], returncode=1, stdout="", stderr=transient),
+                CompletedProcess([], returncode=1, stdout="", stderr=transient),
+                CompletedProcess([], returncode=0, stdout="", stderr=""),
+            ],
+        )
+        sleep = mocker.patch("time.sleep")
+        display_warning = mocker.patch.object(app, "display_warning")
+
+        results = app.tools.go.build_targets(
+            ".", targets=[Target("linux", "amd64")], output="out", retry=RetryPolicy(max_attempts=3, max_delay=2)
+        )
+
+        assert results[0].succeeded
+        assert results[0].attempts == 3
+        assert attach.call_count == 3
+        assert sleep.call_count == 2
+        assert all(1 <= call.args[0] <= 2 for call in sleep.call_args_list)
+        assert display_warning.call_count == 2
+        assert display_warning.call_args.args[0].startswith(
+            "Build of linux/amd64 failed with a transient error, retrying in "
+        )
+
+    def test_retry_exhausted(self, app, mocker):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+        mocker.patch("time.sleep")
+        stream = io.StringIO()
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        processes = [mocker.MagicMock(), mocker.MagicMock()]
+        for process in processes:
+            process.stdout = iter(["go: reading https://proxy.golang.org/example.com/foo/@v/list: 503\n"])
+            process.wait.return_value = 1
+        popen.return_value.__enter__.side_effect = processes
+
+        results = app.tools.go.build_targets(
+            targets=[Target("linux", "amd64")], output="out", retry=RetryPolicy(max_attempts=2), output_stream=stream
+        )
+
+        attach.assert_not_called()
+        assert popen.call_count == 2
+        assert results[0].error == "Build failed with exit code 1"
+        assert results[0].attempts == 2
+        assert stream.getvalue().count("Build of linux/amd64 failed with a transient error, retrying in ") == 1
+
+    def test_no_retry_compile_error(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="./main.go:3:12: undefined: foo"),
+        )
+        sleep = mocker.patch("time.sleep")
+
+        results = app.tools.go.build_targets(targets=[Target("linux", "amd64")], output="out", retry=RetryPolicy())
+
+        assert results[0].error == "Build failed with exit code 1"
+        assert results[0].attempts == 1
+        assert attach.call_count == 1
+        sleep.assert_not_called()
+
+    def test_race(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.supported_targets",
+            new_callable=mocker.PropertyMock,
+            return_value=frozenset({Target("linux", "amd64"), Target("linux", "386"), Target("windows", "amd64")}),
+        )
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+        display_warning = mocker.patch.object(app, "display_warning")
+
+        results = app.tools.go.build_targets(
+            targets=[Target("linux", "amd64"), Target("linux", "386"), Target("windows", "amd64")],
+            output="out",
+            cgo=False,
+            race=True,
+        )
+
+        assert results[0].succeeded
+        assert results[1].error == "The race detector is not supported on linux/386"
+        assert results[2].succeeded
+        assert attach.call_count == 2
+        for call in attach.call_args_list:
+            assert "-race" in call.args[0]
+            assert call.kwargs["env"]["CGO_ENABLED"] == "1"
+        display_warning.assert_called_once_with("Enabling cgo, which is required by the race detector")
+
+    def test_race_default(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.supported_targets",
+            new_callable=mocker.PropertyMock,
+            return_value=frozenset({Target("linux", "amd64"), Target("linux", "386")}),
+        )
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+
+        results = app.tools.go.build_targets(
+            targets=[Target("linux", "amd64"), Target("linux", "386")], output="out", race=None
+        )
+
+        assert all(result.succeeded for result in results)
+        supported_call, unsupported_call = attach.call_args_list
+        assert "-race" in supported_call.args[0]
+        assert supported_call.kwargs["env"]["CGO_ENABLED"] == "1"
+        assert "-race" not in unsupported_call.args[0]
+        assert unsupported_call.kwargs["env"]["CGO_ENABLED"] == "0"
+
+    def test_lock(self, app, mocker, temp_dir):
+        lock_file = output_lock_file(temp_dir / "locks", temp_dir / "dist")
+
+        def build(*_args, **_kwargs):
+            with pytest.raises(Timeout):
+                FileLock(lock_file).acquire(blocking=False)
+
+            raise RuntimeError
+
+        mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+
+        with temp_dir.as_cwd(), pytest.raises(RuntimeError):
+            app.tools.go.build_targets(
+                targets=[Target("linux", "amd64")], output="dist/app", lock=True, lock_dir=temp_dir / "locks"
+            )
+
+        # The lock is released even though the build raised
+        with FileLock(lock_file).acquire(blocking=False):
+            pass
+
+    def test_try_lock(self, app, mocker, temp_dir):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+        lock_dir = temp_dir / "locks"
+        lock_dir.ensure_dir()
+
+        with temp_dir.as_cwd(), FileLock(output_lock_file(lock_dir, temp_dir / "dist")):
+            results = app.tools.go.build_targets(
+                targets=[Target("linux", "amd64")], output="dist/app", try_lock=True, lock_dir=lock_dir
+            )
+
+        assert results[0].error == f"The output directory of linux/amd64 is locked by another build: {Path('dist')}"
+        attach.assert_not_called()
+
+    def test_lock_timeout(self, app, mocker, temp_dir):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+        lock_dir = temp_dir / "locks"
+        lock_dir.ensure_dir()
+
+        with temp_dir.as_cwd(), FileLock(output_lock_file(lock_dir, temp_dir / "dist")):
+            results = app.tools.go.build_targets(
+                targets=[Target("linux", "amd64")], output="dist/app", lock=True, lock_dir=lock_dir, timeout=0.3
+            )
+
+        assert results[0].error == "Build of linux/amd64 timed out after 0.3 seconds"
+        attach.assert_not_called()
+
+    def test_lock_cancel(self, app, mocker, temp_dir):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+        lock_dir = temp_dir / "locks"
+        lock_dir.ensure_dir()
+        cancel = threading.Event()
+        timer = threading.Timer(0.3, cancel.set)
+
+        with temp_dir.as_cwd(), FileLock(output_lock_file(lock_dir, temp_dir / "dist")):
+            timer.start()
+            results = app.tools.go.build_targets(
+                targets=[Target("linux", "amd64")], output="dist/app", lock=True, lock_dir=lock_dir, cancel=cancel
+            )
+
+        assert results[0].error == "Build of linux/amd64 was cancelled"
+        attach.assert_not_called()
+
+
+class TestVerifyReproducible:
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_reproducible(self, app):
+        with (Path(__file__).parent / "fixtures" / "small_go_project").as_cwd():
+            report = app.tools.go.verify_reproducible(".", build_tags={"prod"})
+
+        assert report.reproducible
+        assert report.differences == ()
+
+    def test_nondeterministic(self, app, mocker, temp_dir):
+        def build(*_packages, output, **_kwargs):
+            output.write_bytes(b"build-id:" + output.parent.name.encode())
+            return ""
+
+        _build = mocker.patch("dda.tools.go.Go.build", side_effect=build)
+
+        with temp_dir.as_cwd():
+            report = app.tools.go.verify_reproducible(".", env_vars={"GOFLAGS": "-mod=mod", "FOO": "bar"})
+
+        assert not report.reproducible
+        assert report.differences == ((9, 15),)
+        assert _build.call_count == 2
+        kwargs = _build.call_args.kwargs
+        assert kwargs["trimpath"] is True
+        assert kwargs["force_rebuild"] is True
+        assert kwargs["env_vars"] == {"GOFLAGS": "", "FOO": "bar"}
+        assert kwargs["toolchain"] == "local"
+
+    def test_output(self, app):
+        with pytest.raises(SystemExit):
+            app.tools.go.verify_reproducible(".", output="out")
+
+        assert app.last_error == "The `output` argument cannot be set when verifying reproducibility"
+
+
+@pytest.mark.usefixtures("go_env")
+class TestBuildMatrix:
+    @pytest.fixture(autouse=True)
+    def _toolchain(self, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.supported_targets",
+            new_callable=mocker.PropertyMock,
+            return_value=frozenset({Target("linux", "amd64"), Target("windows", "amd64")}),
+        )
+        mocker.patch(
+            "dda.tools.go.Go.host_target", new_callable=mocker.PropertyMock, return_value=Target("linux", "amd64")
+        )
+
+    def test_report(self, app, mocker, temp_dir):
+        outputs = []
+
+        def build(command, **kwargs):
+            output = Path(next(part for part in command if part.startswith("-o=")).removeprefix("-o="))
+            outputs.append(output)
+            assert output.is_dir()
+            assert Path.cwd() == temp_dir
+            if kwargs["env"]["GOOS"] == "windows":
+                return CompletedProcess([], returncode=1, stdout="", stderr="lib_windows.go:3:23: undefined: foo")
+            return CompletedProcess([], returncode=0, stdout="", stderr="")
+
+        mocker.patch("dda.tools.go.Go.attach", side_effect=build)
+
+        report = app.tools.go.build_matrix(
+            "./...", targets=[Target("linux", "amd64"), Target("windows", "amd64")], root=temp_dir, parallelism=2
+        )
+
+        assert not report.all_passed
+        assert [result.target for result in report.failures] == [Target("windows", "amd64")]
+        assert report.failures[0].stderr == "lib_windows.go:3:23: undefined: foo"
+        assert report.results[0].succeeded
+        # The binaries are discarded
+        assert not any(output.exists() for output in outputs)
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_sizes(self, app):
+        report = app.tools.go.build_matrix(
+            ".",
+            targets=[Target("linux", "amd64"), Target("windows", "amd64")],
+            root=Path(__file__).parent / "fixtures" / "small_go_project",
+            build_tags={"prod"},
+            size_report=True,
+        )
+
+        assert report.all_passed
+        assert set(report.sizes) == {"linux/amd64", "windows/amd64"}
+        for result in report.results:
+            assert result.size == report.sizes[str(result.target)] > 0
+            assert {"text", "rodata", "data"} <= set(result.sections)
+            assert sum(result.sections.values()) < result.size
+
+    def test_all_passed(self, app, mocker):
+        mocker.patch("dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr=""))
+
+        report = app.tools.go.build_matrix(targets=[Target("linux", "amd64")])
+
+        assert report.all_passed
+        assert report.failures == []
+
+
+@pytest.mark.usefixtures("go_env")
+class TestDryRun:
+    @pytest.fixture(autouse=True)
+    def _toolchain(self, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.supported_targets",
+            new_callable=mocker.PropertyMock,
+            return_value=frozenset({Target("linux", "amd64"), Target("windows", "amd64")}),
+        )
+        mocker.patch(
+            "dda.tools.go.Go.host_target", new_callable=mocker.PropertyMock, return_value=Target("linux", "amd64")
+        )
+
+    @pytest.fixture(autouse=True)
+    def _no_commands(self, mocker):
+        for method in ("attach", "capture", "run", "_popen"):
+            mocker.patch(f"dda.tools.go.Go.{method}", side_effect=AssertionError(f"Unexpected call to {method}"))
+
+    def test_build(self, app, temp_dir):
+        with EnvVars({"GOFLAGS": "-mod=mod"}, exclude=["GOWORK", "GOTOOLCHAIN"]):
+            plan = app.tools.go.build(
+                "./cmd/agent",
+                output="bin/my agent",
+                race=False,
+                ldflags_vars={"main.Version": "1.0"},
+                env_vars={"CGO_ENABLED": "0"},
+                cwd=temp_dir,
+                dry_run=True,
+            )
+
+        assert len(plan.commands) == 1
+        command = plan.commands[0]
+        assert command.command == [
+            app.tools.go.path,
+            "build",
+            "-trimpath",
+            "-mod=readonly",
+            "-o=bin/my agent",
+            "-ldflags=-X=main.Version=1.0",
+            "./cmd/agent",
+        ]
+        assert command.env_vars == {"CGO_ENABLED": "0"}
+        assert command.cwd == str(temp_dir)
+        assert str(command).startswith(f"cd {temp_dir} && CGO_ENABLED=0 ")
+        assert plan.warnings == (
+            "GOFLAGS sets `-mod=mod` but the command sets `-mod=readonly`, which takes precedence",
+        )
+
+    def test_overlay(self, app, temp_dir):
+        with pytest.raises(SystemExit):
+            app.tools.go.build(".", output="out", overlay={"main.go": "package main\n"}, dry_run=True)
+
+        assert app.last_error == (
+            "Dry runs cannot be combined with overlays, which only exist for the duration of a build"
+        )
+
+        app.last_error = ""
+        with pytest.raises(SystemExit):
+            app.tools.go.build_targets(
+                targets=[Target("linux", "amd64")], output=str(temp_dir), overlay={"main.go": None}, dry_run=True
+            )
+
+        assert app.last_error == (
+            "Dry runs cannot be combined with overlays, which only exist for the duration of a build"
+        )
+
+    def test_build_toolchain(self, app, mocker):
+        mocker.patch("dda.tools.go.Go._validate_toolchain", side_effect=lambda toolchain: toolchain)
+
+        with EnvVars(exclude=["GOTOOLCHAIN"]):
+            plan = app.tools.go.build(".", output="out", race=False, toolchain="go1.22.3", dry_run=True)
+
+        assert plan.commands[0].env_vars["GOTOOLCHAIN"] == "go1.22.3"
+
+    def test_build_targets(self, app, temp_dir):
+        with EnvVars(exclude=["GOOS", "GOARCH", "CGO_ENABLED", "GOFLAGS"]):
+            plan = app.tools.go.build_targets(
+                ".",
+                targets=[Target("linux", "amd64"), Target("solaris", "mips"), Target("windows", "amd64")],
+                output=str(temp_dir / "{goos}_{goarch}" / "agent"),
+                env_vars={"GOFLAGS": "-mod=mod"},
+                lock=True,
+                timeout=60,
+                dry_run=True,
+            )
+
+        assert [command.env_vars for command in plan.commands] == [
+            {"GOFLAGS": "-mod=mod", "GOOS": "linux", "GOARCH": "amd64"},
+            {"GOFLAGS": "-mod=mod", "GOOS": "windows", "GOARCH": "amd64", "CGO_ENABLED": "0"},
+        ]
+        assert f"-o={temp_dir / 'windows_amd64' / 'agent'}" in plan.commands[1].command
+        # Warnings that apply to every target are reported once
+        assert plan.warnings == (
+            "GOFLAGS sets `-mod=mod` but the command sets `-mod=readonly`, which takes precedence",
+            "Unsupported target: solaris/mips",
+        )
+        assert not list(temp_dir.iterdir())
+
+    def test_build_targets_incremental(self, app, mocker, temp_dir):
+        mocker.patch("dda.tools.go.Go._input_digest", return_value="abc")
+        (temp_dir / "linux_amd64").write_text("binary")
+        (temp_dir / "linux_amd64.inputs").write_text("abc")
+
+        plan = app.tools.go.build_targets(
+            targets=[Target("linux", "amd64"), Target("windows", "amd64")],
+            output=str(temp_dir / "{goos}_{goarch}"),
+            incremental=True,
+            dry_run=True,
+        )
+
+        assert len(plan.commands) == 1
+        assert plan.commands[0].env_vars["GOOS"] == "windows"
+
+    def test_build_matrix(self, app):
+        plan = app.tools.go.build_matrix(
+            "./...", targets=[Target("linux", "amd64"), Target("windows", "amd64")], dry_run=True
+        )
+
+        assert len(plan.commands) == 2
+        assert all(command.command[-1] == "./..." for command in plan.commands)
+
+    def test_run_tests(self, app, temp_dir):
+        with EnvVars(exclude=["GOWORK"]):
+            plan = app.tools.go.run_tests(
+                "./pkg/...",
+                tests=["TestFoo"],
+                timeout=30,
+                env_vars={"GOEXPERIMENT": "rangefunc", "GOFLAGS": ""},
+                cwd=temp_dir,
+                dry_run=True,
+            )
+
+        assert len(plan.commands) == 1
+        command = plan.commands[0]
+        assert command.command == [app.tools.go.path, "test", "-json", "-run", "^TestFoo$", "-timeout=30s", "./pkg/..."]
+        assert command.env_vars["GOEXPERIMENT"] == "rangefunc"
+        assert str(command).startswith(f"cd {temp_dir} && GOEXPERIMENT=rangefunc ")
+        assert str(command).endswith(f"{app.tools.go.path} test -json -run '^TestFoo$' -timeout=30s ./pkg/...")
+        assert plan.warnings == ()
+
+
+@pytest.mark.usefixtures("go_env")
+class TestTestStream:
+    def test_command_formation(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with app.tools.go.test_stream("./pkg/...", run="TestFoo", build_tags={"b", "a"}, cwd="root") as stream:
+            assert stream.process is popen.return_value.__enter__.return_value
+
+        assert popen.call_args.args[0] == ["test", "-json", "-tags", "a,b", "-run", "TestFoo", "./pkg/..."]
+        assert popen.call_args.kwargs["cwd"] == "root"
+
+    def test_timeout(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with app.tools.go.test_stream(timeout=90):
+            pass
+
+        assert popen.call_args.args[0] == ["test", "-json", "-timeout=90s"]
+
+    def test_exact_tests(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with app.tools.go.test_stream("./...", tests=["TestA"], subtests={"TestB": ["Sub"]}):
+            pass
+
+        assert popen.call_args.args[0] == ["test", "-json", "-run", "^TestA$|^TestB$/^Sub$", "./..."]
+
+    def test_exact_tests_with_run(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with pytest.raises(SystemExit), app.tools.go.test_stream(run="TestA", tests=["TestB"]):
+            pass
+
+        assert app.last_error == "The `run` argument cannot be combined with `tests` or `subtests`"
+        popen.assert_not_called()
+
+    def test_extra_args(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with app.tools.go.test_stream("./...", run="TestFoo", extra_args=["-count=1", "-shuffle", "on"]):
+            pass
+
+        assert popen.call_args.args[0] == ["test", "-json", "-run", "TestFoo", "-count=1", "-shuffle", "on", "./..."]
+
+    def test_extra_args_test_binary(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with pytest.raises(SystemExit), app.tools.go.test_stream("./...", extra_args=["-args", "-update"]):
+            pass
+
+        assert app.last_error == "Extra argument `-args` would change how the package patterns are interpreted"
+        popen.assert_not_called()
+
+    def test_race(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", side_effect={"GOOS": "linux", "GOARCH": "amd64"}.get)
+
+        with app.tools.go.test_stream(race=True):
+            pass
+
+        assert popen.call_args.args[0] == ["test", "-json", "-race"]
+        assert popen.call_args.kwargs["env_vars"]["CGO_ENABLED"] == "1"
+
+    def test_race_unsupported(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", side_effect={"GOOS": "linux", "GOARCH": "mips"}.get)
+
+        with pytest.raises(RaceUnsupportedError), app.tools.go.test_stream(race=True):
+            pass
+
+        popen.assert_not_called()
+
+
+@pytest.mark.usefixtures("go_env")
+class TestRunTests:
+    @staticmethod
+    def events(mocker, *events, exit_code=0):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        process = popen.return_value.__enter__.return_value
+        process.stdout = iter(f"{json.dumps(event)}\n" for event in events)
+        process.stderr = iter([])
+        process.wait.return_value = exit_code
+        return popen
+
+    def test_passed(self, app, mocker):
+        popen = self.events(
+            mocker,
+            {"Action": "run", "Package": "p", "Test": "TestA"},
+            {"Action": "pass", "Package": "p", "Test": "TestA"},
+            {"Action": "pass", "Package": "p"},
+        )
+
+        run = app.tools.go.run_tests("./...", run="TestA", timeout=0.5)
+
+        assert popen.call_args.args[0] == ["test", "-json", "-run", "TestA", "-timeout=0.5s", "./..."]
+        assert run.passed
+        assert len(run.events) == 3
+        assert run.failures == []
+
+    def test_goflags_conflict(self, app, mocker):
+        self.events(mocker, {"Action": "pass", "Package": "p"})
+
+        run = app.tools.go.run_tests(timeout=30, env_vars={"GOFLAGS": "-timeout=1m -count=1"})
+
+        assert run.warnings == ("GOFLAGS sets `-timeout=1m` but the command sets `-timeout=30s`, which takes precedence",)
+
+    def test_failed(self, app, mocker):
+        self.events(
+            mocker,
+            {"Action": "run", "Package": "p", "Test": "TestA"},
+            {"Action": "fail", "Package": "p", "Test": "TestA"},
+            {"Action": "fail", "Package": "p"},
+            exit_code=1,
+        )
+
+        run = app.tools.go.run_tests()
+
+        assert not run.passed
+        assert run.failures == ["p.TestA"]
+
+    def test_timeout(self, app, mocker):
+        self.events(
+            mocker,
+            {"Action": "run", "Package": "p", "Test": "TestOk"},
+            {"Action": "pass", "Package": "p", "Test": "TestOk"},
+            {"Action": "run", "Package": "p", "Test": "TestHang"},
+            {"Action": "run", "Package": "p", "Test": "TestHang/sub"},
+            {"Action": "output", "Package": "p", "Test": "TestHang/sub", "Output": "panic: test timed out after 1s\n"},
+            {"Action": "output", "Package": "p", "Test": "TestHang/sub", "Output": "\trunning tests:\n"},
+            {"Action": "fail", "Package": "p"},
+            {"Action": "run", "Package": "q", "Test": "TestOther"},
+            {"Action": "pass", "Package": "q", "Test": "TestOther"},
+            exit_code=1,
+        )
+
+        with pytest.raises(TestTimeoutError) as exc_info:
+            app.tools.go.run_tests("./...", timeout=1)
+
+        error = exc_info.value
+        assert error.running_at_timeout == ["TestHang", "TestHang/sub"]
+        assert error.packages == ["p"]
+        assert error.run.exit_code == 1
+        assert str(error) == "Tests timed out in: p\nStill running: TestHang, TestHang/sub"
+
+
+class TestBenchmark:
+    def test_run(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=0,
+                stdout="BenchmarkFoo/bar-8 \t 1000 \t 12.5 ns/op \t 16 B/op \t 1 allocs/op\nPASS\n",
+                stderr="",
+            ),
+        )
+
+        results = app.tools.go.benchmark("./...", bench="Foo", count=5, benchtime="1000x", benchmem=True)
+
+        assert attach.call_args.args[0] == [
+            "test",
+            "-run",
+            "^$",
+            "-bench",
+            "Foo",
+            "-count=5",
+            "-benchtime=1000x",
+            "-benchmem",
+            "./...",
+        ]
+        assert [(r.name, r.procs, r.ns_per_op, r.bytes_per_op, r.allocs_per_op) for r in results] == [
+            ("BenchmarkFoo/bar", 8, 12.5, 16, 1),
+        ]
+
+    def test_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="--- FAIL: BenchmarkFoo\n", stderr=""),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.benchmark()
+
+        assert app.last_error == "Command failed with exit code 1: go test\n--- FAIL: BenchmarkFoo\n"
+
+
+class TestVet:
+    def test_diagnostics(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=0,
+                stdout='{"example.com/foo": {"printf": [{"posn": "main.go:6:14", "message": "bad format"}]}}',
+                stderr="",
+            ),
+        )
+
+        diagnostics = app.tools.go.vet("./...", analyzers=["printf"], build_tags={"debug"}, cwd="root")
+
+        assert attach.call_args.args[0] == ["vet", "-json", "-printf", "-tags", "debug", "./..."]
+        assert attach.call_args.kwargs["cwd"] == "root"
+        assert [(d.file, d.line, d.analyzer, d.message) for d in diagnostics] == [
+            ("main.go", 6, "printf", "bad format")
+        ]
+
+    def test_no_diagnostics(self, app, mocker):
+        mocker.patch("dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr=""))
+
+        assert app.tools.go.vet() == []
+
+    def test_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="vet: main.go:3:12: undefined: foo"),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.vet()
+
+        assert app.last_error == "Command failed with exit code 1: go vet\nvet: main.go:3:12: undefined: foo"
+
+
+class TestGenerate:
+    @pytest.fixture(name="package")
+    def fixt_package(self, temp_dir):
+        (temp_dir / "gen.go").touch()
+        return temp_dir
+
+    def test_run(self, app, mocker, package):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=0, stdout="", stderr="gen.go\nmockgen -source=gen.go\n"),
+        )
+        run = mocker.patch("dda.tools.go.Go.run", return_value=0)
+
+        generators = app.tools.go.generate("./...", run="mockgen", cwd=package)
+
+        assert attach.call_args.args[0] == ["generate", "-run", "mockgen", "-n", "-v", "./..."]
+        assert run.call_args.args[0] == ["generate", "-run", "mockgen", "./..."]
+        assert run.call_args.kwargs["cwd"] == package
+        assert [(g.file, g.command) for g in generators] == [("gen.go", "mockgen -source=gen.go")]
+
+    def test_dry_run(self, app, mocker, package):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=0, stdout="", stderr="gen.go\nmockgen -source=gen.go\n"),
+        )
+        run = mocker.patch("dda.tools.go.Go.run")
+
+        generators = app.tools.go.generate(cwd=package, dry_run=True)
+
+        assert len(generators) == 1
+        run.assert_not_called()
+
+    def test_nothing_selected(self, app, mocker, package):
+        mocker.patch("dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr=""))
+        run = mocker.patch("dda.tools.go.Go.run")
+
+        assert app.tools.go.generate(run="nothing", cwd=package) == []
+        run.assert_not_called()
+
+
+class TestCheckGenerated:
+    def test_stale(self, app, mocker, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n")
+        (temp_dir / "pkg").mkdir()
+        (temp_dir / "pkg" / "mock.go").write_text("package pkg\n")
+        (temp_dir / "pkg" / "current.go").write_text("package pkg\n")
+
+        def generate(*_packages, cwd, **_kwargs):
+            (cwd / "mock.go").write_text("package pkg\n\nvar Mock = 1\n")
+            (cwd / "current.go").write_text("package pkg\n")
+            return []
+
+        generate_mock = mocker.patch("dda.tools.go.Go.generate", side_effect=generate)
+
+        stale = app.tools.go.check_generated(".", run="mockgen", cwd=temp_dir / "pkg")
+
+        assert stale == ["pkg/mock.go"]
+        assert generate_mock.call_args.kwargs["run"] == "mockgen"
+        assert (temp_dir / "pkg" / "mock.go").read_text() == "package pkg\n"
+
+    def test_no_module(self, app, temp_dir):
+        with pytest.raises(SystemExit):
+            app.tools.go.check_generated(cwd=temp_dir)
+
+
+class TestVerifyModules:
+    def test_verified(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=0, stdout="all modules verified\n", stderr=""),
+        )
+
+        app.tools.go.verify_modules("root")
+
+        assert attach.call_count == 1
+        assert attach.call_args.args[0] == ["mod", "verify"]
+        assert attach.call_args.kwargs["cwd"] == "root"
+
+    def test_modified(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [], returncode=1, stdout="", stderr="example.com/dep v1.0.0: dir has been modified (/mod/dep)\n"
+            ),
+        )
+
+        with pytest.raises(VerificationError) as exc_info:
+            app.tools.go.verify_modules()
+
+        assert exc_info.value.failures == {"example.com/dep@v1.0.0": "dir has been modified (/mod/dep)"}
+
+    def test_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="go: go.mod file not found\n"),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.verify_modules()
+
+        assert app.last_error == "Command failed with exit code 1: go mod verify\ngo: go.mod file not found\n"
+
+    def test_check_sum(self, app, mocker):
+        diff = (
+            "diff current/go.sum tidy/go.sum\n--- current/go.sum\n+++ tidy/go.sum\n@@ -1,1 +1,0 @@\n"
+            "-example.com/old v0.1.0 h1:AAAA=\n"
+        )
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            side_effect=[
+                CompletedProcess([], returncode=0, stdout="all modules verified\n", stderr=""),
+                CompletedProcess([], returncode=1, stdout=diff, stderr=""),
+            ],
+        )
+        mocker.patch("dda.tools.go.Go.version", new_callable=mocker.PropertyMock, return_value="1.23.0")
+
+        with pytest.raises(VerificationError) as exc_info:
+            app.tools.go.verify_modules(check_sum=True)
+
+        assert attach.call_args.args[0] == ["mod", "tidy", "-diff"]
+        assert exc_info.value.failures == {}
+        assert exc_info.value.missing == []
+        assert exc_info.value.extra == ["example.com/old v0.1.0 h1:AAAA="]
+
+    def test_check_sum_unsupported(self, app, mocker):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+        mocker.patch("dda.tools.go.Go.version", new_callable=mocker.PropertyMock, return_value="1.22.5")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.verify_modules(check_sum=True)
+
+        assert app.last_error == "Checking go.sum requires Go 1.23 or later, found go1.22.5"
+        attach.assert_not_called()
+
+
+class TestVerifyVendor:
+    @pytest.fixture(name="module")
+    def fixt_module(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n")
+        (temp_dir / "vendor" / "example.com" / "dep").mkdir(parents=True)
+        (temp_dir / "vendor" / "example.com" / "dep" / "dep.go").write_text("package dep\n")
+        (temp_dir / "vendor" / "modules.txt").write_text("# example.com/dep v1.0.0\nexample.com/dep\n")
+        return temp_dir
+
+    @staticmethod
+    def vendor(files):
+        def attach(command, **_kwargs):
+            output = Path(command[command.index("-o") + 1])
+            for name, contents in files.items():
+                path = output / name
+                path.parent.ensure_dir()
+                path.write_text(contents)
+            return CompletedProcess([], returncode=0, stdout="", stderr="")
+
+        return attach
+
+    def test_consistent(self, app, mocker, module):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            side_effect=self.vendor({
+                "example.com/dep/dep.go": "package dep\n",
+                "modules.txt": "# example.com/dep v1.0.0\nexample.com/dep\n",
+            }),
+        )
+
+        assert app.tools.go.verify_vendor(module) == []
+        assert attach.call_args.args[0][:3] == ["mod", "vendor", "-o"]
+        assert attach.call_args.kwargs["cwd"] == module
+
+    def test_drift(self, app, mocker, module):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            side_effect=self.vendor({
+                "example.com/dep/dep.go": "package dep\n\nconst X = 1\n",
+                "example.com/dep/new.go": "package dep\n",
+                "modules.txt": "# example.com/dep v1.1.0\nexample.com/dep\n",
+            }),
+        )
+
+        with module.as_cwd():
+            assert app.tools.go.verify_vendor() == [
+                "vendor/example.com/dep/dep.go",
+                "vendor/example.com/dep/new.go",
+                "vendor/modules.txt",
+            ]
+
+    def test_failure(self, app, mocker, module):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="go: inconsistent vendoring"),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.verify_vendor(module)
+
+        assert app.last_error == "Command failed with exit code 1: go mod vendor\ngo: inconsistent vendoring"
+
+
+class TestDownload:
+    def test_all(self, app, mocker, temp_dir):
+        archive = temp_dir / "v1.0.0.zip"
+        archive.write_bytes(b"\0" * 10)
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=0,
+                stdout=json.dumps({"Path": "example.com/dep", "Version": "v1.0.0", "Zip": str(archive)}),
+                stderr="",
+            ),
+        )
+
+        report = app.tools.go.download(root=temp_dir)
+
+        assert attach.call_args.args[0] == ["mod", "download", "-json"]
+        assert attach.call_args.kwargs["cwd"] == temp_dir
+        assert [str(module) for module in report.modules] == ["example.com/dep@v1.0.0"]
+        assert report.total_size == 10
+
+    def test_modules(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+
+        app.tools.go.download("example.com/a@v1.0.0", "example.com/b@latest", verbose=True)
+
+        assert attach.call_args.args[0] == [
+            "mod",
+            "download",
+            "-json",
+            "-x",
+            "example.com/a@v1.0.0",
+            "example.com/b@latest",
+        ]
+
+    def test_context(self, app, mocker):
+        packages = "".join(
+            f"{json.dumps(package)}\n"
+            for package in (
+                {"ImportPath": "example.com/main", "Module": {"Path": "example.com/main", "Main": True}},
+                {"ImportPath": "example.com/b/pkg", "Module": {"Path": "example.com/b", "Version": "v2.0.0"}},
+                {"ImportPath": "example.com/a", "Module": {"Path": "example.com/a", "Version": "v1.0.0"}},
+                {"ImportPath": "example.com/b", "Module": {"Path": "example.com/b", "Version": "v2.0.0"}},
+                {"ImportPath": "fmt", "Standard": True},
+            )
+        )
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            side_effect=[
+                CompletedProcess([], returncode=0, stdout=packages, stderr=""),
+                CompletedProcess([], returncode=0, stdout="", stderr=""),
+            ],
+        )
+
+        app.tools.go.download(context=BuildContext(goos="windows", goarch="amd64", tags=frozenset({"prod"})))
+
+        list_call, download_call = attach.call_args_list
+        assert list_call.args[0] == ["list", "-json=ImportPath,Module", "-deps", "-tags", "prod", "./..."]
+        assert list_call.kwargs["env"]["GOOS"] == "windows"
+        assert download_call.args[0] == ["mod", "download", "-json", "example.com/a@v1.0.0", "example.com/b@v2.0.0"]
+
+    def test_context_no_dependencies(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=0, stdout='{"ImportPath": "fmt", "Standard": true}', stderr=""),
+        )
+
+        report = app.tools.go.download(context=BuildContext(goos="linux", goarch="amd64"))
+
+        assert attach.call_count == 1
+        assert report.modules == ()
+
+    def test_checksum_mismatch(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=1,
+                stdout="",
+                stderr="verifying example.com/dep@v1.0.0: checksum mismatch\n\nSECURITY ERROR\n",
+            ),
+        )
+
+        with pytest.raises(ChecksumError) as exc_info:
+            app.tools.go.download()
+
+        assert exc_info.value.module == "example.com/dep@v1.0.0"
+
+    def test_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=1,
+                stdout='{"Path": "example.com/nope", "Version": "v1.0.0", "Error": "example.com/nope@v1.0.0: not found"}',
+                stderr="",
+            ),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.download("example.com/nope@v1.0.0")
+
+        assert app.last_error == "Command failed with exit code 1: go mod download\nexample.com/nope@v1.0.0: not found"
+
+
+class TestOutdated:
+    def test_updates(self, app, mocker, temp_dir):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=0,
+                stdout=(
+                    '{"Path": "example.com/main", "Main": true}\n'
+                    '{"Path": "example.com/a", "Version": "v1.0.0", "Update": {"Version": "v1.1.0"}}\n'
+                    '{"Path": "example.com/b", "Version": "v1.0.0", "Update": {"Version": "v1.1.0"}, "Indirect": true}\n'
+                ),
+                stderr="",
+            ),
+        )
+
+        updates = app.tools.go.outdated(temp_dir, indirect=True)
+
+        assert attach.call_args.args[0] == ["list", "-m", "-u", "-json", "-mod=readonly", "all"]
+        assert attach.call_args.kwargs["cwd"] == temp_dir
+        assert [(update.path, update.latest, update.indirect) for update in updates] == [
+            ("example.com/a", "v1.1.0", False),
+            ("example.com/b", "v1.1.0", True),
+        ]
+
+    def test_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="go: cannot find main module"),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.outdated()
+
+        assert app.last_error == "Command failed with exit code 1: go list\ngo: cannot find main module"
+
+
+class TestCache:
+    def test_stats(self, app, mocker, temp_dir):
+        (temp_dir / "00").mkdir()
+        (temp_dir / "00" / "aa-d").write_bytes(b"x" * 10)
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value=str(temp_dir))
+
+        assert app.tools.go.cache_stats() == CacheStats(directory=str(temp_dir), size=10, entries=1)
+
+    def test_stats_toolchain(self, app, mocker, temp_dir):
+        mocker.patch("dda.tools.go.Go._validate_toolchain", side_effect=lambda toolchain: toolchain)
+        capture = mocker.patch("dda.tools.go.Go.capture", return_value=f"{temp_dir}\n")
+
+        assert app.tools.go.cache_stats("go1.22.3") == CacheStats(directory=str(temp_dir))
+        assert capture.call_args.args[0] == ["env", "GOCACHE"]
+        assert capture.call_args.kwargs["env"]["GOTOOLCHAIN"] == "go1.22.3"
+
+    def test_disabled(self, app, mocker):
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="off")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.cache_stats()
+
+        assert app.last_error == "The build cache is disabled"
+
+    def test_clean(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+
+        assert app.tools.go.clean_cache(modcache=True, testcache=True) is None
+        assert attach.call_args.args[0] == ["clean", "-modcache", "-testcache"]
+
+    def test_clean_nothing(self, app, mocker):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+
+        assert app.tools.go.clean_cache() is None
+        assert not attach.called
+
+    def test_clean_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="permission denied"),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.clean_cache(cache=True)
+
+        assert app.last_error == "Command failed with exit code 1: go clean\npermission denied"
+
+    def test_trim(self, app, mocker, temp_dir):
+        (temp_dir / "00").mkdir()
+        (temp_dir / "00" / "old-d").write_bytes(b"x" * 100)
+        (temp_dir / "00" / "new-d").write_bytes(b"x" * 100)
+        os.utime(temp_dir / "00" / "old-d", (1000, 1000))
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value=str(temp_dir))
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach", return_value=CompletedProcess([], returncode=0, stdout="", stderr="")
+        )
+
+        removed = app.tools.go.clean_cache(testcache=True, max_size=150)
+
+        assert removed == CacheStats(directory=str(temp_dir), size=100, entries=1)
+        assert attach.call_args.args[0] == ["clean", "-testcache"]
+        assert not (temp_dir / "00" / "old-d").exists()
+        assert (temp_dir / "00" / "new-d").is_file()
+
+
+class TestEffectiveFlags:
+    def test_installed(self, app, mocker):
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="-mod=mod '-ldflags=-s -w'")
+
+        assert app.tools.go.effective_flags() == ["-mod=mod", "-ldflags=-s -w"]
+
+    def test_toolchain(self, app, mocker):
+        mocker.patch("dda.tools.go.Go._validate_toolchain", side_effect=lambda toolchain: toolchain)
+        capture = mocker.patch("dda.tools.go.Go.capture", return_value="-trimpath\n")
+
+        assert app.tools.go.effective_flags("go1.22.3") == ["-trimpath"]
+        assert capture.call_args.args[0] == ["env", "GOFLAGS"]
+        assert capture.call_args.kwargs["env"]["GOTOOLCHAIN"] == "go1.22.3"
+
+    def test_malformed(self, app, mocker):
+        mocker.patch("dda.utils.go.toolchain.Toolchain.env", return_value="'-ldflags=-s")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.effective_flags()
+
+        assert app.last_error == "Unterminated ' string in GOFLAGS: '-ldflags=-s"
+
+
+class TestFormat:
+    @pytest.fixture(name="sources")
+    def fixture_sources(self, temp_dir):
+        (temp_dir / "pkg").ensure_dir()
+        (temp_dir / "vendor").ensure_dir()
+        (temp_dir / "a.go").write_text("package a\nfunc  A() {\n}\n")
+        (temp_dir / "b.go").write_text("package a\n\nfunc B() {}\n")
+        (temp_dir / "pkg" / "c.go").write_text("package pkg\nvar x=1\n")
+        (temp_dir / "vendor" / "d.go").write_text("package d\nvar x=1\n")
+        return temp_dir
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_check(self, app, sources):
+        result = app.tools.go.format(root=sources)
+
+        assert result == FormatResult(files=("a.go", "pkg/c.go"))
+        assert (sources / "a.go").read_text() == "package a\nfunc  A() {\n}\n"
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_diff(self, app, sources):
+        result = app.tools.go.format("pkg", root=sources, diff=True, exclude=())
+
+        assert result.files == ("pkg/c.go",)
+        assert result.diffs["pkg/c.go"].endswith("-var x=1\n+\n+var x = 1\n")
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_write(self, app, sources):
+        result = app.tools.go.format(root=sources, write=True)
+
+        assert result.files == ("a.go", "pkg/c.go")
+        assert (sources / "a.go").read_text() == "package a\n\nfunc A() {\n}\n"
+        assert (sources / "vendor" / "d.go").read_text() == "package d\nvar x=1\n"
+        assert app.tools.go.format(root=sources).formatted
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_syntax_error(self, app, temp_dir):
+        (temp_dir / "a.go").write_text("package a\nfunc (\n")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.format(root=temp_dir)
+
+        assert app.last_error.startswith("Command failed with exit code 2: gofmt\n")
+
+    def test_gofumpt(self, app, mocker, temp_dir):
+        (temp_dir / "a.go").touch()
+        mocker.patch("dda.utils.platform.which", return_value=None)
+        install_tool = mocker.patch("dda.tools.go.Go.install_tool", return_value=Path("/bin/gofumpt"))
+        attach = mocker.patch(
+            "dda.utils.process.SubprocessRunner.attach",
+            return_value=CompletedProcess([], returncode=0, stdout="a.go\n", stderr=""),
+        )
+
+        result = app.tools.go.format(root=temp_dir, formatter="gofumpt")
+
+        assert result.files == ("a.go",)
+        install_tool.assert_called_once_with(f"mvdan.cc/gofumpt@{GOFUMPT_VERSION}", require_version=True)
+        assert attach.call_args.args[0] == [str(Path("/bin/gofumpt")), "-l", "a.go"]
+
+    def test_invalid_formatter(self, app):
+        with pytest.raises(SystemExit):
+            app.tools.go.format(formatter="goimports")
+
+        assert app.last_error == "Invalid formatter `goimports`, expected one of: gofmt, gofumpt"
+
+
+class TestObserve:
+    class Recorder(Observer):
+        def __init__(self):
+            self.started_invocations = []
+            self.finished_invocations = []
+
+        def started(self, invocation):
+            self.started_invocations.append(invocation)
+
+        def finished(self, invocation):
+            self.finished_invocations.append(invocation)
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_capture(self, app, temp_dir):
+        recorder = self.Recorder()
+
+        with app.tools.go.observe(recorder):
+            output = app.tools.go.capture(["env", "GOOS"], env=EnvVars({"GOOS": "plan9"}), cwd=temp_dir)
+
+        assert output.strip() == "plan9"
+        assert len(recorder.started_invocations) == 1
+        invocation = recorder.finished_invocations[0]
+        assert invocation.command == [app.tools.go.path, "env", "GOOS"]
+        assert invocation.env_vars["GOOS"] == "plan9"
+        assert invocation.cwd == str(temp_dir.absolute())
+        assert invocation.exit_code == 0
+        assert invocation.duration > 0
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_failure(self, app, temp_dir):
+        recorder = self.Recorder()
+
+        with app.tools.go.observe(recorder):
+            process = app.tools.go.attach(["nonexistent"], check=False, capture_output=True, cwd=temp_dir)
+
+        assert recorder.finished_invocations[0].exit_code == process.returncode != 0
+
+    def test_stream(self, app, mocker):
+        popen = mocker.patch("subprocess.Popen")
+        popen.return_value.wait.return_value = 0
+        recorder = self.Recorder()
+
+        with app.tools.go.observe(recorder), app.tools.go.test_stream("./...", env_vars={"GOFLAGS": "-count=1"}):
+            assert len(recorder.started_invocations) == 1
+            assert not recorder.finished_invocations
+
+        invocation = recorder.finished_invocations[0]
+        assert invocation.command == [app.tools.go.path, "test", "-json", "./..."]
+        assert invocation.env_vars["GOFLAGS"] == "-count=1"
+        assert invocation.exit_code == 0
+
+    def test_unregistered(self, app, mocker):
+        recorder = self.Recorder()
+        capture = mocker.patch("dda.utils.process.SubprocessRunner.capture", return_value="")
+
+        with app.tools.go.observe(recorder):
+            app.tools.go.capture(["version"])
+        app.tools.go.capture(["version"])
+
+        assert capture.call_count == 2
+        assert len(recorder.finished_invocations) == 1
+
+
+class TestInstallTool:
+    @pytest.fixture(autouse=True)
+    def _host(self, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.host_target", new_callable=mocker.PropertyMock, return_value=Target("linux", "amd64")
+        )
+
+    def test_install(self, app, mocker, temp_dir):
+        def install(command, **kwargs):
+            (Path(kwargs["env"]["GOBIN"]) / "mockgen").touch()
+            return 0
+
+        run = mocker.patch("dda.tools.go.Go.run", side_effect=install)
+
+        binary = app.tools.go.install_tool("go.uber.org/mock/mockgen@v0.5.0", bin_dir=temp_dir)
+
+        assert binary == temp_dir / "mockgen"
+        assert run.call_args.args[0] == ["install", "go.uber.org/mock/mockgen@v0.5.0"]
+        assert run.call_args.kwargs["env"]["GOBIN"] == str(temp_dir)
+
+        # The same version is already installed
+        assert app.tools.go.install_tool("go.uber.org/mock/mockgen@v0.5.0", bin_dir=temp_dir) == binary
+        assert run.call_count == 1
+
+        app.tools.go.install_tool("go.uber.org/mock/mockgen@v0.6.0", bin_dir=temp_dir)
+        assert run.call_count == 2
+
+    def test_default_directory(self, app, mocker):
+        run = mocker.patch("dda.tools.go.Go.run", return_value=0)
+
+        binary = app.tools.go.install_tool("github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.1.6")
+
+        directory = app.config.storage.data / "go" / "bin"
+        assert binary == directory / "golangci-lint"
+        assert run.call_args.kwargs["env"]["GOBIN"] == str(directory)
+
+    def test_query_always_installed(self, app, mocker, temp_dir):
+        (temp_dir / "mockgen").touch()
+        run = mocker.patch("dda.tools.go.Go.run", return_value=0)
+
+        app.tools.go.install_tool("go.uber.org/mock/mockgen@latest", bin_dir=temp_dir)
+        app.tools.go.install_tool("go.uber.org/mock/mockgen@latest", bin_dir=temp_dir)
+
+        assert run.call_count == 2
+
+    def test_require_version(self, app, mocker, temp_dir):
+        run = mocker.patch("dda.tools.go.Go.run")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.install_tool("go.uber.org/mock/mockgen@latest", bin_dir=temp_dir, require_version=True)
+
+        assert app.last_error == "Tool `go.uber.org/mock/mockgen` must be pinned to a version, found `latest`"
+        run.assert_not_called()
+
+    def test_invalid_spec(self, app, mocker, temp_dir):
+        mocker.patch("dda.tools.go.Go.run")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.install_tool("go.uber.org/mock/mockgen", bin_dir=temp_dir)
+
+        assert app.last_error == "Invalid tool `go.uber.org/mock/mockgen`, expected the format `package@version`"
+
+
+class TestTool:
+    @staticmethod
+    def _fake_tool(mocker, script):
+        commands = []
+
+        @contextmanager
+        def execution_context(_self, command):
+            commands.append(command)
+            yield ExecutionContext(command=[sys.executable, "-c", script, *command], env_vars={})
+
+        mocker.patch("dda.tools.go.Go.execution_context", execution_context)
+        return commands
+
+    def test_output(self, app, mocker):
+        script = "import json, sys; print(json.dumps(sys.argv[1:])); print('warning', file=sys.stderr)"
+        commands = self._fake_tool(mocker, script)
+
+        result = app.tools.go.tool("objdump", "-s", "main.main", "bin/agent")
+
+        assert commands == [["tool", "objdump", "-s", "main.main", "bin/agent"]]
+        assert json.loads(result.stdout) == ["tool", "objdump", "-s", "main.main", "bin/agent"]
+        assert result.stderr.decode().strip() == "warning"
+        assert result.exit_code == 0
+        assert result.succeeded
+
+    def test_env_vars(self, app, mocker):
+        self._fake_tool(mocker, "import os; print(os.environ['GOTOOLCHAIN'], os.environ['GODEBUG'])")
+        mocker.patch("dda.tools.go.Go._validate_toolchain", side_effect=lambda toolchain: toolchain)
+
+        result = app.tools.go.tool("pprof", toolchain="go1.22.3", env_vars={"GODEBUG": "gctrace=1"})
+
+        assert result.stdout.decode().split() == ["go1.22.3", "gctrace=1"]
+
+    def test_cwd(self, app, mocker, temp_dir):
+        self._fake_tool(mocker, "import os; print(os.getcwd())")
+
+        result = app.tools.go.tool("compile", cwd=temp_dir)
+
+        assert Path(result.stdout.decode().strip()) == temp_dir.resolve()
+
+    def test_failure(self, app, mocker):
+        self._fake_tool(mocker, "import sys; print('out'); print('no such tool', file=sys.stderr); sys.exit(2)")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.tool("missing")
+
+        assert app.last_error == "Command failed with exit code 2: go tool missing\nno such tool\n"
+
+        result = app.tools.go.tool("missing", check=False)
+
+        assert result.stdout.decode().strip() == "out"
+        assert result.exit_code == 2
+        assert result.error is None
+        assert not result.succeeded
+
+    def test_invalid_name(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.tool("-n")
+
+        assert app.last_error == "Invalid tool name `-n`"
+        popen.assert_not_called()
+
+    @pytest.mark.skip_windows
+    def test_timeout(self, app, mocker, temp_dir):
+        marker = temp_dir / "alive"
+        # Simulate a tool that spawns a child process that outlives its parent
+        child = f"import time; time.sleep(2); open({str(marker)!r}, 'w').close()"
+        script = (
+            f"import subprocess, sys, time; subprocess.Popen([sys.executable, '-c', {child!r}]); "
+            "print('started', flush=True); time.sleep(60)"
+        )
+        self._fake_tool(mocker, script)
+
+        start = time.monotonic()
+        result = app.tools.go.tool("pprof", timeout=0.5, check=False)
+
+        assert time.monotonic() - start < 10
+        assert result.error == "Command timed out after 0.5 seconds: go tool pprof"
+        assert result.stdout.decode().strip() == "started"
+        assert not result.succeeded
+
+        with pytest.raises(SystemExit):
+            app.tools.go.tool("pprof", timeout=0.5)
+
+        assert app.last_error.startswith("Command timed out after 0.5 seconds: go tool pprof")
+
+        time.sleep(3)
+        assert not marker.exists()
+
+    def test_cancel(self, app, mocker):
+        self._fake_tool(mocker, "import time; time.sleep(60)")
+        cancel = threading.Event()
+        threading.Timer(0.2, cancel.set).start()
+
+        start = time.monotonic()
+        result = app.tools.go.tool("trace", cancel=cancel, check=False)
+
+        assert time.monotonic() - start < 10
+        assert result.error == "Command was cancelled: go tool trace"
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_real(self, app):
+        with EnvVars({"GOFLAGS": "", "GOTOOLCHAIN": "local"}):
+            result = app.tools.go.tool("dist", "list")
+
+        assert "linux/amd64" in result.stdout.decode().splitlines()
+
+
+class TestImports:
+    def test_context(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=0,
+                stdout='{"ImportPath": "example.com/foo", "Imports": ["fmt"], "XTestImports": ["strings"]}\n',
+                stderr="",
+            ),
+        )
+
+        packages = app.tools.go.imports(
+            "./...", context=BuildContext(goos="windows", goarch="arm64", tags=frozenset({"b", "a"}), cgo=True)
+        )
+
+        assert attach.call_args.args[0] == [
+            "list",
+            "-json=ImportPath,Imports,TestImports,XTestImports,Deps",
+            "-tags",
+            "a,b",
+            "./...",
+        ]
+        assert attach.call_args.kwargs["env"]["GOOS"] == "windows"
+        assert attach.call_args.kwargs["env"]["GOARCH"] == "arm64"
+        assert attach.call_args.kwargs["env"]["CGO_ENABLED"] == "1"
+        assert list(packages) == ["example.com/foo"]
+        assert packages["example.com/foo"].imports == ("fmt",)
+        assert packages["example.com/foo"].xtest_imports == ("strings",)
+
+    def test_failure(self, app, mocker):
+        mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess([], returncode=1, stdout="", stderr="no Go files in /foo"),
+        )
+
+        with pytest.raises(SystemExit):
+            app.tools.go.imports()
+
+        assert app.last_error == "Command failed with exit code 1: go list\nno Go files in /foo"
+
+
+class TestAffectedPackages:
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_affected(self, app, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/m\n\ngo 1.21\n")
+        (temp_dir / "main.go").write_text('package main\n\nimport _ "example.com/m/lib"\n\nfunc main() {}\n')
+        (temp_dir / "lib").mkdir()
+        (temp_dir / "lib" / "lib.go").write_text('package lib\n\nimport _ "example.com/m/lib/internal"\n')
+        (temp_dir / "lib" / "internal").mkdir()
+        (temp_dir / "lib" / "internal" / "internal.go").write_text("package internal\n")
+        (temp_dir / "other").mkdir()
+        (temp_dir / "other" / "other.go").write_text("package other\n")
+
+        with EnvVars({"GOTOOLCHAIN": "local", "GOFLAGS": ""}):
+            affected = app.tools.go.affected_packages(["lib/internal/internal.go"], temp_dir)
+            direct = app.tools.go.affected_packages(["lib/internal/internal.go"], temp_dir, depth=1)
+
+        assert affected == ["example.com/m", "example.com/m/lib", "example.com/m/lib/internal"]
+        assert direct == ["example.com/m/lib", "example.com/m/lib/internal"]
+
+    def test_negative_depth(self, app, temp_dir):
+        with pytest.raises(SystemExit):
+            app.tools.go.affected_packages(["main.go"], temp_dir, depth=-1)
+
+        assert app.last_error == "The depth must not be negative: -1"
+
+
+class TestCgoPackages:
+    def test_transitive(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [],
+                returncode=0,
+                stdout=(
+                    '{"ImportPath": "runtime/cgo", "CgoFiles": ["cgo.go"], "Standard": true}\n'
+                    '{"ImportPath": "example.com/foo/sqlite", "CgoFiles": ["sqlite.go"]}\n'
+                    '{"ImportPath": "example.com/foo/cmd"}\n'
+                ),
+                stderr="",
+            ),
+        )
+
+        packages = app.tools.go.cgo_packages("./cmd", context=BuildContext(goos="linux", goarch="arm64"))
+
+        assert packages == ["example.com/foo/sqlite"]
+        assert attach.call_args.args[0] == ["list", "-json=ImportPath,CgoFiles,Standard", "-deps", "./cmd"]
+        # Files that import `C` are only considered when cgo is enabled
+        assert attach.call_args.kwargs["env"]["CGO_ENABLED"] == "1"
+        assert attach.call_args.kwargs["env"]["GOARCH"] == "arm64"
+
+    def test_options(self, app, mocker):
+        attach = mocker.patch(
+            "dda.tools.go.Go.attach",
+            return_value=CompletedProcess(
+                [], returncode=0, stdout='{"ImportPath": "net", "CgoFiles": ["cgo.go"], "Standard": true}', stderr=""
+            ),
+        )
+
+        assert app.tools.go.cgo_packages("net", transitive=False, include_std=True) == ["net"]
+        assert "-deps" not in attach.call_args.args[0]
+
+
+class TestModuleGraph:
+    def test_parse(self, app, mocker):
+        popen = mocker.patch("dda.tools.go.Go._popen")
+        process = popen.return_value.__enter__.return_value
+        process.stdout = iter(["example.com/main example.com/a@v1.0.0\n"])
+        process.wait.return_value = 0
+
+        graph = app.tools.go.module_graph("root")
+
+        assert popen.call_args.args[0] == ["mod", "graph"]
+        assert popen.call_args.kwargs["cwd"] == "root"
+        assert graph.selected == {"example.com/a": "v1.0.0"}
+
+
+class TestSetToolchain:
+    def test_command(self, app, mocker, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22\n")
+        attach = mocker.patch("dda.tools.go.Go.attach", return_value=CompletedProcess([], 0, stdout="", stderr=""))
+
+        app.tools.go.set_toolchain("1.22.3", root=temp_dir)
+        app.tools.go.set_toolchain(None, root=temp_dir)
+
+        first, second = attach.call_args_list
+        assert first.args[0] == ["mod", "edit", "-toolchain=go1.22.3"]
+        assert first.kwargs["cwd"] == temp_dir
+        assert first.kwargs["env"]["GOTOOLCHAIN"] == "local"
+        assert second.args[0] == ["mod", "edit", "-toolchain=none"]
+
+    def test_older_than_go_directive(self, app, mocker, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22.1\n")
+        attach = mocker.patch("dda.tools.go.Go.attach")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.set_toolchain("go1.22.0", root=temp_dir)
+
+        assert app.last_error == (
+            "Toolchain `go1.22.0` is older than the version required by the `go` directive: 1.22.1"
+        )
+        attach.assert_not_called()
+
+    def test_no_module(self, app, mocker, temp_dir):
+        attach = mocker.patch("dda.tools.go.Go.attach")
+
+        with pytest.raises(SystemExit):
+            app.tools.go.set_toolchain(None, root=temp_dir)
+
+        assert app.last_error == f"No `go.mod` file found in `{temp_dir}` or any parent directory"
+        attach.assert_not_called()
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_layouts(self, app, temp_dir):
+        mod_file = temp_dir / "go.mod"
+        mod_file.write_text("module example.com/foo // the module\n\ngo 1.22 // minimum\n\nrequire foo.com/a v1.0.0\n")
+
+        with EnvVars({"GOFLAGS": ""}):
+            app.tools.go.set_toolchain("go1.23rc1", root=temp_dir)
+            inserted = mod_file.read_text()
+            app.tools.go.set_toolchain("go1.23.1", root=temp_dir)
+            replaced = mod_file.read_text()
+            app.tools.go.set_toolchain(None, root=temp_dir)
+
+        assert inserted == (
+            "module example.com/foo // the module\n\ngo 1.22 // minimum\n\ntoolchain go1.23rc1\n\n"
+            "require foo.com/a v1.0.0\n"
+        )
+        assert replaced == inserted.replace("go1.23rc1", "go1.23.1")
+        assert mod_file.read_text() == (
+            "module example.com/foo // the module\n\ngo 1.22 // minimum\n\nrequire foo.com/a v1.0.0\n"
+        )
+
+
+class TestModuleDiff:
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_diff(self, app):
+        old = """\
+module example.com/main
+
+go 1.99
+
+require (
+\texample.com/a v1.0.0
+\tgopkg.in/yaml.v2 v2.4.0 // indirect
+)
+
+require example.com/b v1.0.0
+
+replace example.com/b v1.0.0 => ../b
+"""
+        new = b"""\
+module example.com/main
+
+go 1.99
+
+require (
+\texample.com/a v1.1.0
+\tgopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+require example.com/b v1.0.0
+"""
+
+        report = app.tools.go.module_diff(old, new)
+
+        assert report.changes == (
+            ModuleChange("example.com/a", old_version="v1.0.0", new_version="v1.1.0"),
+            ModuleChange("example.com/b", old_version="v1.0.0", new_version="v1.0.0", old_replacement="../b"),
+            ModuleChange(
+                "gopkg.in/yaml.v3",
+                old_version="v2.4.0",
+                new_version="v3.0.1",
+                indirect=True,
+                previous_path="gopkg.in/yaml.v2",
+            ),
+        )
+
+    @pytest.mark.requires_ci
+    @pytest.mark.skip_macos  # Go binary is not installed on macOS CI runners
+    def test_invalid(self, app):
+        with pytest.raises(SystemExit):
+            app.tools.go.module_diff("module example.com/main\n", "require (\n")
+
+        assert app.last_error.startswith("Command failed with exit code 1: go mod edit\n")
@@ -0,0 +1,72 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from dda.utils.go.bench import BenchmarkResult, format_benchstat, parse_benchmark_output
+
+OUTPUT = """\
+goos: linux
+goarch: amd64
+pkg: example.com/bench
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkSprint-4   	 1000	       116.4 ns/op	      12 B/op	       1 allocs/op
+BenchmarkSprint-4   	 1000	       104.3 ns/op	      12 B/op	       1 allocs/op
+BenchmarkSizes/n=1-4         	    1000	        33.30 ns/op	  30.03 MB/s	         3.500 widgets/op
+    bench_test.go:12: some log output
+BenchmarkBroken-4 	 1000	 not a number ns/op
+PASS
+ok  	example.com/bench	0.006s
+pkg: example.com/other
+BenchmarkOther 	 200	 5000 ns/op
+"""
+
+
+def test_parse():
+    results = parse_benchmark_output(OUTPUT)
+
+    assert results == [
+        BenchmarkResult(
+            name="BenchmarkSprint",
+            iterations=1000,
+            ns_per_op=116.4,
+            bytes_per_op=12,
+            allocs_per_op=1,
+            procs=4,
+            package="example.com/bench",
+        ),
+        BenchmarkResult(
+            name="BenchmarkSprint",
+            iterations=1000,
+            ns_per_op=104.3,
+            bytes_per_op=12,
+            allocs_per_op=1,
+            procs=4,
+            package="example.com/bench",
+        ),
+        BenchmarkResult(
+            name="BenchmarkSizes/n=1",
+            iterations=1000,
+            ns_per_op=33.3,
+            procs=4,
+            package="example.com/bench",
+            metrics={"MB/s": 30.03, "widgets/op": 3.5},
+        ),
+        BenchmarkResult(name="BenchmarkOther", iterations=200, ns_per_op=5000, package="example.com/other"),
+    ]
+    assert results[0].full_name == "BenchmarkSprint-4"
+    assert results[3].full_name == "BenchmarkOther"
+
+
+def test_format_benchstat():
+    results = parse_benchmark_output(OUTPUT)
+
+    assert format_benchstat(results) == (
+        "pkg: example.com/bench\n"
+        "BenchmarkSprint-4\t1000\t116.4 ns/op\t12 B/op\t1 allocs/op\n"
+        "BenchmarkSprint-4\t1000\t104.3 ns/op\t12 B/op\t1 allocs/op\n"
+        "BenchmarkSizes/n=1-4\t1000\t33.3 ns/op\t30.03 MB/s\t3.5 widgets/op\n"
+        "pkg: example.com/other\n"
+        "BenchmarkOther\t200\t5000 ns/op\n"
+    )
+    assert parse_benchmark_output(format_benchstat(results)) == results
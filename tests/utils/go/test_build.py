@@ -0,0 +1,443 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import json
+import shutil
+
+import pytest
+
+from dda.utils.fs import Path
+from dda.utils.go.build import (
+    SizeChange,
+    Target,
+    diff_binaries,
+    goflags_conflicts,
+    input_digest,
+    is_transient_error,
+    output_size,
+    output_lock_file,
+    overlay_config,
+    parse_symbol_sizes,
+    render_ldflags,
+    resolve_build_files,
+    resolve_pgo_profile,
+    size_regressions,
+    validate_extra_args,
+    version_stamp,
+    write_overlay,
+)
+from dda.utils.go.constraints import BuildContext
+
+
+class TestTarget:
+    def test_from_string(self):
+        assert Target.from_string("linux/amd64") == Target(goos="linux", goarch="amd64")
+
+    @pytest.mark.parametrize("text", ["linux", "linux/", "/amd64"])
+    def test_invalid(self, text):
+        with pytest.raises(ValueError, match="Invalid target"):
+            Target.from_string(text)
+
+    def test_str(self):
+        assert str(Target(goos="windows", goarch="arm64")) == "windows/arm64"
+
+
+class TestRenderLdflags:
+    def test_raw(self):
+        assert render_ldflags(["-s", "-w"]) == "-s -w"
+
+    def test_vars(self):
+        assert render_ldflags(["-s"], {"main.Version": "1.0.0", "main.Commit": "abc"}) == (
+            "-s -X=main.Commit=abc -X=main.Version=1.0.0"
+        )
+
+    def test_quoting(self):
+        assert render_ldflags(ldflags_vars={"main.Date": "Jan 1"}) == "'-X=main.Date=Jan 1'"
+        assert render_ldflags(ldflags_vars={"main.Name": "it's"}) == '"-X=main.Name=it\'s"'
+
+    def test_conflicting_quotes(self):
+        with pytest.raises(ValueError, match="both single and double quotes"):
+            render_ldflags(ldflags_vars={"main.Name": "it's \"quoted\""})
+
+    def test_version_stamp(self):
+        assert render_ldflags(ldflags_vars=version_stamp("abc", "2024-01-01")) == (
+            "-X=main.BuildDate=2024-01-01 -X=main.Commit=abc"
+        )
+
+
+class TestResolveBuildFiles:
+    def test_files(self, temp_dir):
+        (temp_dir / "cmd").ensure_dir()
+        (temp_dir / "cmd" / "main.go").touch()
+        (temp_dir / "cmd" / "prod.go").touch()
+
+        with temp_dir.as_cwd():
+            assert resolve_build_files(["cmd/main.go", "./cmd/../cmd/prod.go"]) == [
+                (temp_dir / "cmd" / "main.go").resolve(),
+                (temp_dir / "cmd" / "prod.go").resolve(),
+            ]
+        assert resolve_build_files(["main.go"], temp_dir / "cmd") == [(temp_dir / "cmd" / "main.go").resolve()]
+
+    def test_different_directories(self, temp_dir):
+        (temp_dir / "cmd").ensure_dir()
+        (temp_dir / "cmd" / "main.go").touch()
+        (temp_dir / "prod.go").touch()
+
+        with pytest.raises(ValueError, match="Named files must all be in the same directory, found: "):
+            resolve_build_files(["cmd/main.go", "prod.go"], temp_dir)
+
+    def test_not_go(self, temp_dir):
+        (temp_dir / "go.mod").touch()
+
+        with pytest.raises(ValueError, match="Named files must be Go source files: "):
+            resolve_build_files(["go.mod"], temp_dir)
+
+    def test_missing(self, temp_dir):
+        with pytest.raises(ValueError, match="File does not exist: "):
+            resolve_build_files(["main.go"], temp_dir)
+
+    def test_empty(self):
+        with pytest.raises(ValueError, match="No files to build"):
+            resolve_build_files([])
+
+
+class TestResolvePgoProfile:
+    @pytest.mark.parametrize("pgo", ["", "auto", "off"])
+    def test_modes(self, pgo):
+        assert resolve_pgo_profile(pgo) == pgo
+
+    def test_path(self, temp_dir):
+        (temp_dir / "profiles").ensure_dir()
+        (temp_dir / "profiles" / "cpu.pprof").touch()
+
+        with temp_dir.as_cwd():
+            assert resolve_pgo_profile("profiles/cpu.pprof") == str((temp_dir / "profiles" / "cpu.pprof").resolve())
+        assert resolve_pgo_profile("cpu.pprof", temp_dir / "profiles") == str(
+            (temp_dir / "profiles" / "cpu.pprof").resolve()
+        )
+
+    def test_missing(self, temp_dir):
+        with pytest.raises(ValueError, match="PGO profile does not exist: "):
+            resolve_pgo_profile("cpu.pprof", temp_dir)
+
+
+class TestGoflagsConflicts:
+    def test_conflicts(self):
+        assert goflags_conflicts(
+            ["-mod=mod", "--trimpath=false", "-tags=x", "-v"], ["-trimpath", "-mod=readonly", "-tags", "a,b", "."]
+        ) == [
+            "GOFLAGS sets `-mod=mod` but the command sets `-mod=readonly`, which takes precedence",
+            "GOFLAGS sets `-trimpath=false` but the command sets `-trimpath=true`, which takes precedence",
+            "GOFLAGS sets `-tags=x` but the command sets `-tags=a,b`, which takes precedence",
+        ]
+
+    def test_compatible(self):
+        assert goflags_conflicts(["-mod=readonly", "-trimpath", "-buildvcs=false"], ["-trimpath", "-mod=readonly"]) == []
+
+
+class TestValidateExtraArgs:
+    def test_flags(self):
+        args = ["-gcflags", "all=-N -l", "-trimpath", "-buildvcs=false", "-a"]
+
+        assert validate_extra_args(iter(args)) == args
+
+    @pytest.mark.parametrize(
+        ("args", "message"),
+        [
+            (["test"], "Extra argument `test` is not a flag"),
+            (["-buildvcs=false", "./other"], "Extra argument `./other` is not a flag"),
+            (["-gcflags", "all=-N", "./other"], "Extra argument `./other` is not a flag"),
+            (["-v", "--", "-x"], "Extra argument `--` would change"),
+            (["-args"], "Extra argument `-args` would change"),
+        ],
+    )
+    def test_rejected(self, args, message):
+        with pytest.raises(ValueError, match=message):
+            validate_extra_args(args)
+
+
+class TestInputDigest:
+    @pytest.fixture(name="module")
+    def fixt_module(self, temp_dir):
+        root = temp_dir / "module"
+        (root / "cmd").mkdir(parents=True)
+        (root / "testdata").mkdir()
+        (root / "go.mod").write_text("module example.com/foo\n")
+        (root / "main.go").write_text("package main\n")
+        (root / "cmd" / "cmd_windows.go").write_text("package cmd\n")
+        (root / "testdata" / "data.go").write_text("package data\n")
+        return root
+
+    def test_deterministic(self, module, temp_dir):
+        context = BuildContext(goos="linux", goarch="amd64")
+        digest = input_digest(module, context, ["-trimpath"])
+
+        copy = temp_dir / "copy"
+        shutil.copytree(module, copy)
+
+        assert input_digest(copy, context, ["-trimpath"]) == digest
+        assert input_digest(module, context, ["-trimpath"]) == digest
+
+    def test_config(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+
+        assert input_digest(module, context, ["-tags", "a"]) != input_digest(module, context, ["-tags", "b"])
+
+    def test_source_change(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+        digest = input_digest(module, context, [])
+
+        (module / "main.go").write_text("package main\n\nfunc main() {}\n")
+
+        assert input_digest(module, context, []) != digest
+
+    def test_excluded_files(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+        digest = input_digest(module, context, [])
+
+        (module / "cmd" / "cmd_windows.go").write_text("package cmd\n\nfunc main() {}\n")
+        (module / "testdata" / "data.go").write_text("package data\n\nfunc main() {}\n")
+        (module / "main_test.go").write_text("package main\n")
+
+        assert input_digest(module, context, []) == digest
+        assert input_digest(module, BuildContext(goos="windows", goarch="amd64"), []) != digest
+
+    def test_embedded_files(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+        (module / "testdata" / "index.html").write_text("<html></html>")
+        digest = input_digest(module, context, [])
+
+        (module / "main.go").write_text(
+            'package main\n\nimport _ "embed"\n\n//go:embed testdata/*.html\nvar index string\n'
+        )
+        embed_digest = input_digest(module, context, [])
+        assert embed_digest != digest
+
+        (module / "testdata" / "index.html").write_text("<html><body></body></html>")
+        changed_digest = input_digest(module, context, [])
+        assert changed_digest != embed_digest
+
+        # Files that start matching the pattern are embedded without changes to the source files
+        (module / "testdata" / "about.html").write_text("<html></html>")
+        added_digest = input_digest(module, context, [])
+        assert added_digest != changed_digest
+
+        (module / "testdata" / "about.html").unlink()
+        assert input_digest(module, context, []) == changed_digest
+
+    def test_other_sources(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+        (module / "hello.c").write_text("void hello(void) {}\n")
+        (module / "asm_amd64.s").write_text("TEXT ·f(SB),$0\n")
+        (module / "resources_windows.syso").write_bytes(b"\x00")
+        digest = input_digest(module, context, [])
+
+        for name in ("hello.c", "asm_amd64.s", "hello.h", "plugin.syso"):
+            (module / name).write_text("// changed\n")
+            assert input_digest(module, context, []) != digest
+            digest = input_digest(module, context, [])
+
+        (module / "resources_windows.syso").write_bytes(b"\x01")
+        (module / "asm_arm64.s").write_text("TEXT ·f(SB),$0\n")
+        assert input_digest(module, context, []) == digest
+
+    def test_vendored_modules(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+        (module / "vendor").mkdir()
+        (module / "vendor" / "modules.txt").write_text("# example.com/bar v1.0.0\n")
+        digest = input_digest(module, context, [])
+
+        (module / "vendor" / "modules.txt").write_text("# example.com/bar v1.1.0\n")
+
+        assert input_digest(module, context, []) != digest
+
+    def test_local_replacement(self, module, temp_dir):
+        context = BuildContext(goos="linux", goarch="amd64")
+        (temp_dir / "bar").mkdir()
+        (temp_dir / "bar" / "go.mod").write_text("module example.com/bar\n")
+        (temp_dir / "bar" / "bar.go").write_text("package bar\n")
+        (module / "go.mod").write_text("module example.com/foo\n\nreplace example.com/bar => ../bar\n")
+        digest = input_digest(module, context, [])
+
+        (temp_dir / "bar" / "bar.go").write_text("package bar\n\nconst X = 1\n")
+        changed_digest = input_digest(module, context, [])
+        assert changed_digest != digest
+
+        (temp_dir / "bar" / "bar_windows.go").write_text("package bar\n")
+        assert input_digest(module, context, []) == changed_digest
+
+    def test_default_profile(self, module):
+        context = BuildContext(goos="linux", goarch="amd64")
+        digest = input_digest(module, context, [])
+
+        (module / "default.pgo").write_bytes(b"profile")
+        profile_digest = input_digest(module, context, [])
+        (module / "default.pgo").write_bytes(b"other profile")
+
+        assert len({digest, profile_digest, input_digest(module, context, [])}) == 3
+
+
+@pytest.mark.parametrize(
+    ("output", "expected"),
+    [
+        (
+            'go: example.com/foo@v1.0.0: Get "https://proxy.golang.org/example.com/foo/@v/v1.0.0.mod": '
+            "dial tcp 142.250.74.177:443: connect: connection refused",
+            True,
+        ),
+        ("read tcp 10.0.0.1:5000->10.0.0.2:443: read: connection reset by peer", True),
+        ("go: reading https://proxy.golang.org/example.com/foo/@v/v1.0.0.zip: 502 Bad Gateway", True),
+        (
+            "verifying example.com/foo@v1.0.0: example.com/foo@v1.0.0: "
+            'Get "https://sum.golang.org/lookup/example.com/foo@v1.0.0": net/http: TLS handshake timeout',
+            True,
+        ),
+        ("go: reading https://proxy.golang.org/example.com/foo/@v/v1.0.0.mod: 404 Not Found", False),
+        ("./main.go:3:12: undefined: foo", False),
+        ("./main.go:500:2: syntax error: unexpected newline", False),
+        ("", False),
+    ],
+)
+def test_is_transient_error(output, expected):
+    assert is_transient_error(output) is expected
+
+
+def test_write_overlay(temp_dir):
+    replacement = temp_dir / "replacement.go"
+    replacement.write_text("package main\n")
+    with temp_dir.as_cwd():
+        overlay_file = write_overlay(
+            {
+                "gen.go": "package main\n\nconst X = 1\n",
+                "data.bin": b"\x00\x01",
+                "main.go": replacement,
+                "deleted.go": None,
+            },
+            temp_dir / "overlay",
+        )
+
+    assert overlay_file == temp_dir / "overlay" / "overlay.json"
+    replace = json.loads(overlay_file.read_text())["Replace"]
+    assert Path(replace[str(temp_dir / "gen.go")]).read_text() == "package main\n\nconst X = 1\n"
+    assert Path(replace[str(temp_dir / "gen.go")]).name == "gen.go"
+    assert Path(replace[str(temp_dir / "data.bin")]).read_bytes() == b"\x00\x01"
+    assert replace[str(temp_dir / "main.go")] == str(replacement)
+    assert not replace[str(temp_dir / "deleted.go")]
+
+
+def test_write_overlay_cwd(temp_dir):
+    (temp_dir / "cmd").mkdir()
+    (temp_dir / "overlay").mkdir()
+    (temp_dir / "cmd" / "replacement.go").write_text("package main\n")
+    with temp_dir.as_cwd():
+        overlay_file = write_overlay({"main.go": Path("replacement.go"), "old.go": None}, temp_dir / "overlay", "cmd")
+
+    assert json.loads(overlay_file.read_text())["Replace"] == {
+        str(temp_dir / "cmd" / "main.go"): str(temp_dir / "cmd" / "replacement.go"),
+        str(temp_dir / "cmd" / "old.go"): "",
+    }
+
+
+def test_overlay_config(temp_dir):
+    def config(contents, directory):
+        return overlay_config(write_overlay({temp_dir / "gen.go": contents, temp_dir / "old.go": None}, directory))
+
+    first = config("package main\n", temp_dir / "first")
+
+    assert first == config("package main\n", temp_dir / "second")
+    assert first != config("package other\n", temp_dir / "third")
+    assert first[1] == f"overlay:{temp_dir / 'old.go'}="
+
+
+def test_output_lock_file(temp_dir):
+    (temp_dir / "dist").ensure_dir()
+    lock_file = output_lock_file(temp_dir / "locks", temp_dir / "dist")
+
+    assert lock_file.parent == temp_dir / "locks"
+    assert lock_file.name.endswith(".lock")
+    with temp_dir.as_cwd():
+        assert output_lock_file(temp_dir / "locks", "dist") == lock_file
+        assert output_lock_file(temp_dir / "locks", "dist/../dist") == lock_file
+        assert output_lock_file(temp_dir / "locks", "other") != lock_file
+
+
+class TestDiffBinaries:
+    def test_identical(self, temp_dir):
+        (temp_dir / "a").write_bytes(b"x" * 100_000)
+        (temp_dir / "b").write_bytes(b"x" * 100_000)
+
+        assert diff_binaries(temp_dir / "a", temp_dir / "b") == []
+
+    def test_ranges(self, temp_dir):
+        data = bytearray(b"x" * 100_000)
+        (temp_dir / "a").write_bytes(data)
+        data[10:12] = b"yy"
+        data[70_000] = ord("y")
+        (temp_dir / "b").write_bytes(data)
+
+        assert diff_binaries(temp_dir / "a", temp_dir / "b") == [(10, 12), (70_000, 70_001)]
+        assert diff_binaries(temp_dir / "a", temp_dir / "b", limit=1) == [(10, 12)]
+
+    def test_range_across_chunks(self, temp_dir):
+        (temp_dir / "a").write_bytes(b"x" * 200_000)
+        (temp_dir / "b").write_bytes(b"x" * 60_000 + b"y" * 10_000 + b"x" * 130_000)
+
+        assert diff_binaries(temp_dir / "a", temp_dir / "b") == [(60_000, 70_000)]
+
+    def test_different_sizes(self, temp_dir):
+        (temp_dir / "a").write_bytes(b"x" * 100_000)
+        (temp_dir / "b").write_bytes(b"x" * 99_990 + b"y" + b"x" * 9 + b"z" * 100_000)
+
+        assert diff_binaries(temp_dir / "a", temp_dir / "b") == [(99_990, 99_991), (100_000, 200_000)]
+        assert diff_binaries(temp_dir / "b", temp_dir / "a") == [(99_990, 99_991), (100_000, 200_000)]
+
+
+class TestSizes:
+    def test_output_size(self, temp_dir):
+        (temp_dir / "bin").mkdir()
+        (temp_dir / "bin" / "a").write_bytes(b"12345")
+        (temp_dir / "bin" / "b").write_bytes(b"123")
+        (temp_dir / "bin" / "nested").mkdir()
+        (temp_dir / "bin" / "nested" / "c").write_bytes(b"1")
+
+        assert output_size(temp_dir / "bin" / "a") == 5
+        assert output_size(temp_dir / "bin") == 8
+        assert output_size(temp_dir / "missing") == 0
+
+    def test_parse_symbol_sizes(self):
+        output = """\
+  4d2fa0          4 r $f32.3f2cc4c7
+  56ff40     143208 r go:func.*
+  401000       1052 T main.main
+  401500         12 t runtime.text
+  5d0620      93464 B runtime.mheap_
+  5c0000         64 D runtime.buildVersion
+  5c0040          8 d main.counter
+  5c0048         16 C runtime.notused
+                  0 U _cgo_panic
+  5c0058        128 b {},interface
+"""
+
+        assert parse_symbol_sizes(output) == {
+            "rodata": 143212,
+            "text": 1064,
+            "bss": 93592,
+            "data": 72,
+            "other": 16,
+        }
+
+    def test_size_regressions(self):
+        baseline = {"linux/amd64": 1000, "linux/arm64": 1000, "windows/amd64": 1000}
+        sizes = {"linux/amd64": 1050, "linux/arm64": 1051, "windows/amd64": 900, "darwin/arm64": 5000}
+
+        assert size_regressions(baseline, sizes, threshold=0.05) == [
+            SizeChange(target="linux/arm64", baseline=1000, size=1051)
+        ]
+        assert [change.target for change in size_regressions(baseline, sizes)] == ["linux/amd64", "linux/arm64"]
+
+    def test_size_change(self):
+        assert SizeChange(target="linux/amd64", baseline=1000, size=1100).growth == 100
+        assert SizeChange(target="linux/amd64", baseline=1000, size=900).ratio == -0.1
+        assert SizeChange(target="linux/amd64", baseline=0, size=10).ratio == float("inf")
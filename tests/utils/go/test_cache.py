@@ -0,0 +1,51 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import os
+
+from dda.utils.go.cache import CacheStats, scan_cache, trim_cache
+
+
+def populate(directory, entries):
+    for index, (name, size) in enumerate(entries.items()):
+        path = directory / name
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_bytes(b"x" * size)
+        # Entries are listed from least to most recently used
+        os.utime(path, (1000 + index, 1000 + index))
+
+
+class TestScanCache:
+    def test_missing(self, temp_dir):
+        assert scan_cache(temp_dir / "missing") == CacheStats(directory=str(temp_dir / "missing"))
+
+    def test_entries(self, temp_dir):
+        populate(temp_dir, {"00/aa-a": 10, "00/aa-d": 100, "ff/bb-d": 1000, "README": 5, "trim.txt": 5})
+        (temp_dir / "testexpand").mkdir()
+
+        assert scan_cache(temp_dir) == CacheStats(directory=str(temp_dir), size=1110, entries=3)
+
+
+class TestTrimCache:
+    def test_least_recently_used(self, temp_dir):
+        populate(temp_dir, {"01/old-d": 100, "00/new-a": 10, "00/new-d": 100, "ff/newest-d": 50})
+
+        removed = trim_cache(temp_dir, 200)
+
+        assert removed == CacheStats(directory=str(temp_dir), size=100, entries=1)
+        assert not (temp_dir / "01" / "old-d").exists()
+        assert scan_cache(temp_dir).size == 160
+
+    def test_within_budget(self, temp_dir):
+        populate(temp_dir, {"00/aa-d": 100})
+
+        assert trim_cache(temp_dir, 100) == CacheStats(directory=str(temp_dir))
+        assert (temp_dir / "00" / "aa-d").is_file()
+
+    def test_empty_budget(self, temp_dir):
+        populate(temp_dir, {"00/aa-a": 10, "00/aa-d": 100, "README": 5})
+
+        assert trim_cache(temp_dir, 0) == CacheStats(directory=str(temp_dir), size=110, entries=2)
+        assert (temp_dir / "README").is_file()
@@ -0,0 +1,149 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.fs import Path
+from dda.utils.go.constraints import (
+    BuildConstraintError,
+    BuildContext,
+    matches_filename,
+    parse_build_constraints,
+    parse_build_constraints_from_source,
+)
+from dda.utils.go.version import Version
+
+FIXTURES = Path(__file__).parent.parent.parent / "tools" / "go" / "fixtures" / "small_go_project"
+
+
+class TestParse:
+    def test_fixture(self):
+        constraint = parse_build_constraints(FIXTURES / "debug.go")
+
+        assert str(constraint.go_build) == "debug"
+        assert str(constraint.plus_build) == "debug"
+        assert constraint.tags == {"debug"}
+
+    def test_unconstrained(self):
+        constraint = parse_build_constraints(FIXTURES / "main.go")
+
+        assert constraint.expr is None
+        assert constraint.evaluate(BuildContext(goos="linux", goarch="amd64"))
+
+    @pytest.mark.parametrize(
+        ("line", "expected"),
+        [
+            ("linux && amd64", "linux && amd64"),
+            ("linux || darwin && !cgo", "linux || (darwin && !cgo)"),
+            ("(linux || darwin) && !cgo", "(linux || darwin) && !cgo"),
+            ("!(a && b)", "!(a && b)"),
+        ],
+    )
+    def test_go_build(self, line, expected):
+        constraint = parse_build_constraints_from_source(f"//go:build {line}\n\npackage main\n")
+
+        assert str(constraint.expr) == expected
+        assert constraint.plus_build is None
+
+    def test_plus_build(self):
+        source = "// +build linux,amd64 darwin\n// +build !cgo\n\npackage main\n"
+        constraint = parse_build_constraints_from_source(source)
+
+        assert str(constraint.expr) == "((linux && amd64) || darwin) && !cgo"
+        assert constraint.go_build is None
+
+    def test_plus_build_without_blank_line(self):
+        constraint = parse_build_constraints_from_source("// +build linux\npackage main\n")
+
+        assert constraint.expr is None
+
+    def test_after_package_clause(self):
+        constraint = parse_build_constraints_from_source("package main\n\n//go:build linux\n")
+
+        assert constraint.expr is None
+
+    def test_equivalent_forms(self):
+        source = "//go:build (linux || darwin) && !cgo\n// +build linux darwin\n// +build !cgo\n\npackage main\n"
+        constraint = parse_build_constraints_from_source(source)
+
+        assert constraint.expr == constraint.go_build
+
+    @pytest.mark.parametrize(
+        ("source", "error"),
+        [
+            ("//go:build linux\n// +build darwin\n\npackage main\n", "disagree"),
+            ("//go:build linux\n//go:build darwin\n\npackage main\n", "multiple //go:build comments"),
+            ("//go:build !!linux\n\npackage main\n", "double negation"),
+            ("//go:build linux &&\n\npackage main\n", "unexpected end"),
+            ("//go:build (linux\n\npackage main\n", "missing close paren"),
+            ("//go:build linux & amd64\n\npackage main\n", "unexpected token"),
+            ("// +build linux,!\n\npackage main\n", "invalid // \\+build term"),
+        ],
+    )
+    def test_errors(self, source, error):
+        with pytest.raises(BuildConstraintError, match=error):
+            parse_build_constraints_from_source(source)
+
+    def test_error_includes_path(self, temp_dir):
+        path = temp_dir / "foo.go"
+        path.write_text("//go:build linux\n// +build darwin\n\npackage main\n")
+
+        with pytest.raises(BuildConstraintError, match="foo.go: //go:build and // \\+build lines disagree"):
+            parse_build_constraints(path)
+
+
+class TestEvaluate:
+    @pytest.mark.parametrize(
+        ("line", "context", "expected"),
+        [
+            ("linux && amd64", BuildContext(goos="linux", goarch="amd64"), True),
+            ("linux && amd64", BuildContext(goos="linux", goarch="arm64"), False),
+            ("debug", BuildContext(goos="linux", goarch="amd64", tags=frozenset({"debug"})), True),
+            ("!debug", BuildContext(goos="linux", goarch="amd64", tags=frozenset({"debug"})), False),
+            ("unix", BuildContext(goos="darwin", goarch="arm64"), True),
+            ("unix", BuildContext(goos="windows", goarch="amd64"), False),
+            ("linux", BuildContext(goos="android", goarch="arm64"), True),
+            ("cgo", BuildContext(goos="linux", goarch="amd64", cgo=True), True),
+            ("gc && !gccgo", BuildContext(goos="linux", goarch="amd64"), True),
+            ("go1.21", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, 3)), True),
+            ("go1.22", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, 3)), True),
+            ("go1.23", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, 3)), False),
+            ("!go1.23", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, 3)), True),
+            ("!go1.21", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, 3)), False),
+            ("go1.22", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, prerelease="rc1")), True),
+            ("go1.21", BuildContext(goos="linux", goarch="amd64"), False),
+            ("go1.21.3", BuildContext(goos="linux", goarch="amd64", go_version=Version(1, 22, 3)), False),
+        ],
+    )
+    def test_context(self, line, context, expected):
+        constraint = parse_build_constraints_from_source(f"//go:build {line}\n\npackage main\n")
+
+        assert constraint.evaluate(context) is expected
+
+
+class TestMatchesFilename:
+    @pytest.mark.parametrize(
+        ("filename", "expected"),
+        [
+            ("main.go", True),
+            ("linux.go", True),
+            ("foo_linux.go", True),
+            ("foo_windows.go", False),
+            ("foo_amd64.go", True),
+            ("foo_arm64.go", False),
+            ("foo_linux_amd64.go", True),
+            ("foo_linux_arm64.go", False),
+            ("foo_darwin_amd64.go", False),
+            ("foo_windows_test.go", False),
+            ("foo_linux_test.go", True),
+            ("foo_plan10.go", True),
+            ("foo_bar_amd64.go", True),
+        ],
+    )
+    def test_context(self, filename, expected):
+        assert matches_filename(filename, BuildContext(goos="linux", goarch="amd64")) is expected
+
+    def test_implied_os(self):
+        assert matches_filename("foo_linux.go", BuildContext(goos="android", goarch="arm64"))
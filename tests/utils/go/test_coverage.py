@@ -0,0 +1,98 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.go.coverage import CoverageError, coverage_percent, merge_coverage
+
+
+def _write(path, text):
+    path.write_text(text)
+    return path
+
+
+class TestMergeCoverage:
+    def test_count(self, temp_dir):
+        first = _write(
+            temp_dir / "a.out",
+            "mode: count\nexample.com/foo/b.go:3.10,5.2 2 1\nexample.com/foo/a.go:10.2,12.16 2 0\n",
+        )
+        second = _write(
+            temp_dir / "b.out",
+            "mode: count\nexample.com/foo/a.go:10.2,12.16 2 3\nexample.com/foo/a.go:2.10,4.2 1 1\n",
+        )
+        output = temp_dir / "merged.out"
+
+        merge_coverage([first, second], output)
+
+        assert output.read_text() == (
+            "mode: count\n"
+            "example.com/foo/a.go:2.10,4.2 1 1\n"
+            "example.com/foo/a.go:10.2,12.16 2 3\n"
+            "example.com/foo/b.go:3.10,5.2 2 1\n"
+        )
+
+    def test_set(self, temp_dir):
+        first = _write(temp_dir / "a.out", "mode: set\nexample.com/foo/a.go:10.2,12.16 2 1\n")
+        second = _write(temp_dir / "b.out", "mode: set\nexample.com/foo/a.go:10.2,12.16 2 1\n")
+        output = temp_dir / "merged.out"
+
+        merge_coverage([first, second], output)
+
+        assert output.read_text() == "mode: set\nexample.com/foo/a.go:10.2,12.16 2 1\n"
+
+    def test_mode_mismatch(self, temp_dir):
+        first = _write(temp_dir / "a.out", "mode: set\n")
+        second = _write(temp_dir / "b.out", "mode: atomic\n")
+
+        with pytest.raises(CoverageError, match="cannot merge coverage mode `atomic` with `set`"):
+            merge_coverage([first, second], temp_dir / "merged.out")
+
+    def test_invalid_mode(self, temp_dir):
+        profile = _write(temp_dir / "a.out", "mode: foo\n")
+
+        with pytest.raises(CoverageError, match="invalid coverage mode line: mode: foo"):
+            merge_coverage([profile], temp_dir / "merged.out")
+
+    def test_invalid_block(self, temp_dir):
+        profile = _write(temp_dir / "a.out", "mode: set\nexample.com/foo/a.go:10.2,12.16 x 1\n")
+
+        with pytest.raises(CoverageError, match="a.out:2: invalid coverage block"):
+            merge_coverage([profile], temp_dir / "merged.out")
+
+    def test_inconsistent_statements(self, temp_dir):
+        first = _write(temp_dir / "a.out", "mode: count\nexample.com/foo/a.go:10.2,12.16 2 1\n")
+        second = _write(temp_dir / "b.out", "mode: count\nexample.com/foo/a.go:10.2,12.16 3 1\n")
+
+        with pytest.raises(CoverageError, match="inconsistent number of statements"):
+            merge_coverage([first, second], temp_dir / "merged.out")
+
+    def test_no_profiles(self, temp_dir):
+        with pytest.raises(CoverageError, match="No coverage profiles to merge"):
+            merge_coverage([], temp_dir / "merged.out")
+
+
+class TestCoveragePercent:
+    def test_total(self, temp_dir):
+        profile = _write(
+            temp_dir / "a.out",
+            "mode: atomic\n"
+            "example.com/foo/a.go:2.10,4.2 1 5\n"
+            "example.com/foo/a.go:10.2,12.16 2 0\n"
+            "example.com/foo/b.go:3.10,5.2 1 1\n",
+        )
+
+        assert coverage_percent(profile) == 50
+
+    def test_duplicate_blocks(self, temp_dir):
+        profile = _write(
+            temp_dir / "a.out",
+            "mode: set\nexample.com/foo/a.go:2.10,4.2 1 0\nexample.com/foo/a.go:2.10,4.2 1 1\n",
+        )
+
+        assert coverage_percent(profile) == 100
+
+    def test_empty(self, temp_dir):
+        assert coverage_percent(_write(temp_dir / "a.out", "mode: set\n")) == 0
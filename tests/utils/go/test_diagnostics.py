@@ -0,0 +1,150 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.fs import Path
+from dda.utils.go.diagnostics import Diagnostic, parse_compiler_output, parse_vet_output
+
+VET_OUTPUT = """\
+# example.com/vet
+{
+	"example.com/vet": {
+		"assign": [
+			{
+				"posn": "/src/main.go:8:2",
+				"end": "/src/main.go:8:2",
+				"message": "self-assignment of x",
+				"suggested_fixes": [
+					{
+						"message": "Remove self-assignment",
+						"edits": [{"filename": "/src/main.go", "start": 78, "end": 85, "new": ""}]
+					}
+				]
+			}
+		],
+		"printf": [
+			{
+				"posn": "/src/main.go:6:14",
+				"end": "/src/main.go:6:16",
+				"message": "fmt.Printf format %d has arg \\"x\\" of wrong type string"
+			}
+		]
+	}
+}
+# example.com/vet/sub
+{
+	"example.com/vet/sub": {
+		"printf": [
+			{
+				"posn": "/src/sub/sub.go:5:24",
+				"end": "/src/sub/sub.go:5:26",
+				"message": "fmt.Printf format %s has arg 1 of wrong type int"
+			}
+		]
+	}
+}
+"""
+
+COMPILER_OUTPUT = """\
+# example.com/ce/sub
+sub/sub.go:4:9: cannot use "a" (untyped string constant) as int value in return statement
+# example.com/ce
+./main.go:3:20: too many arguments in call to f
+\thave (number, number)
+\twant (int)
+go: some unrelated message
+"""
+
+
+class TestDiagnostic:
+    @pytest.mark.parametrize(
+        ("position", "expected"),
+        [
+            ("main.go:8:2", Diagnostic(file="main.go", line=8, column=2)),
+            ("main.go:8", Diagnostic(file="main.go", line=8)),
+            ("main.go", Diagnostic(file="main.go")),
+            ("C:\\src\\main.go:8:2", Diagnostic(file="C:\\src\\main.go", line=8, column=2)),
+        ],
+    )
+    def test_from_position(self, position, expected):
+        assert Diagnostic.from_position(position) == expected
+
+    def test_str(self):
+        assert str(Diagnostic(file="main.go", line=8, column=2, message="oops")) == "main.go:8:2: oops"
+
+
+class TestParseVetOutput:
+    def test_flatten(self):
+        assert parse_vet_output(VET_OUTPUT) == [
+            Diagnostic(
+                file="/src/main.go",
+                line=8,
+                column=2,
+                message="self-assignment of x",
+                analyzer="assign",
+                package="example.com/vet",
+            ),
+            Diagnostic(
+                file="/src/main.go",
+                line=6,
+                column=14,
+                message='fmt.Printf format %d has arg "x" of wrong type string',
+                analyzer="printf",
+                package="example.com/vet",
+            ),
+            Diagnostic(
+                file="/src/sub/sub.go",
+                line=5,
+                column=24,
+                message="fmt.Printf format %s has arg 1 of wrong type int",
+                analyzer="printf",
+                package="example.com/vet/sub",
+            ),
+        ]
+
+    def test_empty(self):
+        assert parse_vet_output("") == []
+
+    def test_analyzer_error(self):
+        with pytest.raises(ValueError, match="Analyzer `printf` failed for package `example.com/vet`: boom"):
+            parse_vet_output('{"example.com/vet": {"printf": {"error": "boom"}}}')
+
+
+class TestParseCompilerOutput:
+    def test_parse(self):
+        assert parse_compiler_output(COMPILER_OUTPUT) == [
+            Diagnostic(
+                file="sub/sub.go",
+                line=4,
+                column=9,
+                message='cannot use "a" (untyped string constant) as int value in return statement',
+                package="example.com/ce/sub",
+            ),
+            Diagnostic(
+                file="./main.go",
+                line=3,
+                column=20,
+                message="too many arguments in call to f\nhave (number, number)\nwant (int)",
+                package="example.com/ce",
+            ),
+        ]
+
+    def test_directory(self, temp_dir):
+        diagnostics = parse_compiler_output(COMPILER_OUTPUT, temp_dir)
+
+        assert [diagnostic.file for diagnostic in diagnostics] == [
+            str(temp_dir / "sub" / "sub.go"),
+            str(temp_dir / "main.go"),
+        ]
+
+    def test_absolute_path(self, temp_dir):
+        path = Path("/src/main.go").resolve()
+        diagnostics = parse_compiler_output(f"{path}:1:1: expected 'package', found foo\n", temp_dir)
+
+        assert diagnostics == [Diagnostic(file=str(path), line=1, column=1, message="expected 'package', found foo")]
+
+    def test_empty(self):
+        assert parse_compiler_output("") == []
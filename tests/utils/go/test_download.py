@@ -0,0 +1,151 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import json
+
+from dda.utils.go.download import (
+    DownloadReport,
+    ModuleDownload,
+    VerificationError,
+    find_checksum_error,
+    parse_download_output,
+    parse_sum_diff,
+    parse_verify_output,
+)
+
+MISMATCH = """\
+verifying example.com/dep@v1.0.0: checksum mismatch
+	downloaded: h1:m+ISnZzz6rk2E22kP8UucXr74/knqn/oXwuKsMddcik=
+	go.sum:     h1:AAAAnZzz6rk2E22kP8UucXr74/knqn/oXwuKsMddcik=
+
+SECURITY ERROR
+This download does NOT match an earlier download recorded in go.sum.
+"""
+
+
+def test_parse_download_output(temp_dir):
+    archive = temp_dir / "v1.0.0.zip"
+    archive.write_bytes(b"\0" * 42)
+    output = json.dumps(
+        {
+            "Path": "example.com/dep",
+            "Version": "v1.0.0",
+            "Zip": str(archive),
+            "Dir": str(temp_dir / "example.com" / "dep@v1.0.0"),
+            "Sum": "h1:m+ISnZzz6rk2E22kP8UucXr74/knqn/oXwuKsMddcik=",
+        },
+        indent="\t",
+    )
+    output += '\n{"Path": "example.com/nope", "Version": "v1.0.0", "Error": "not found"}\n'
+
+    modules = parse_download_output(output)
+
+    assert modules == [
+        ModuleDownload(
+            path="example.com/dep",
+            version="v1.0.0",
+            zip=str(archive),
+            dir=str(temp_dir / "example.com" / "dep@v1.0.0"),
+            sum="h1:m+ISnZzz6rk2E22kP8UucXr74/knqn/oXwuKsMddcik=",
+            size=42,
+        ),
+        ModuleDownload(path="example.com/nope", version="v1.0.0", error="not found"),
+    ]
+    assert str(modules[0]) == "example.com/dep@v1.0.0"
+    assert DownloadReport(modules=tuple(modules)).total_size == 42
+
+
+def test_parse_download_output_missing_archive(temp_dir):
+    output = json.dumps({"Path": "example.com/dep", "Version": "v1.0.0", "Zip": str(temp_dir / "missing.zip")})
+
+    assert parse_download_output(output)[0].size == 0
+
+
+class TestFindChecksumError:
+    def test_mismatch(self):
+        error = find_checksum_error(f"go: downloading example.com/dep v1.0.0\n{MISMATCH}")
+
+        assert error is not None
+        assert error.module == "example.com/dep@v1.0.0"
+        assert error.message == MISMATCH.strip()
+        assert str(error).startswith("Checksum verification failed for `example.com/dep@v1.0.0`\nverifying ")
+
+    def test_go_mod(self):
+        error = find_checksum_error("verifying example.com/dep@v1.0.0/go.mod: checksum mismatch\n")
+
+        assert error is not None
+        assert error.module == "example.com/dep@v1.0.0"
+
+    def test_checksum_database(self):
+        error = find_checksum_error(
+            "example.com/dep@v1.0.0: verifying module: example.com/dep@v1.0.0: reading "
+            "https://sum.golang.org/lookup/example.com/dep@v1.0.0: 404 Not Found"
+        )
+
+        assert error is not None
+        assert error.module == "example.com/dep@v1.0.0"
+
+    def test_none(self):
+        assert find_checksum_error("go: example.com/nope@v1.0.0: reading file:///proxy: no such file") is None
+
+
+def test_parse_verify_output():
+    output = """\
+example.com/dep v1.0.0: dir has been modified (/root/go/pkg/mod/example.com/dep@v1.0.0)
+example.com/other/v2 v2.1.0: missing ziphash: open /root/go/pkg/mod/cache/download/example.com/other/v2/@v/v2.1.0.ziphash: no such file or directory
+go: some other error
+"""
+
+    assert parse_verify_output(output) == {
+        "example.com/dep@v1.0.0": "dir has been modified (/root/go/pkg/mod/example.com/dep@v1.0.0)",
+        "example.com/other/v2@v2.1.0": (
+            "missing ziphash: open /root/go/pkg/mod/cache/download/example.com/other/v2/@v/v2.1.0.ziphash: "
+            "no such file or directory"
+        ),
+    }
+    assert parse_verify_output("all modules verified\n") == {}
+
+
+def test_parse_sum_diff():
+    output = """\
+diff current/go.mod tidy/go.mod
+--- current/go.mod
++++ tidy/go.mod
+@@ -1,3 +1,5 @@
+ module example.com/foo
++
++require example.com/dep v1.0.0
+
+diff current/go.sum tidy/go.sum
+--- current/go.sum
++++ tidy/go.sum
+@@ -1,3 +1,3 @@
++example.com/dep v1.0.0 h1:AAAA=
+ example.com/dep v1.0.0/go.mod h1:BBBB=
+-example.com/old v0.1.0 h1:CCCC=
+-example.com/old v0.1.0/go.mod h1:DDDD=
+
+"""
+
+    assert parse_sum_diff(output) == (
+        ["example.com/dep v1.0.0 h1:AAAA="],
+        ["example.com/old v0.1.0 h1:CCCC=", "example.com/old v0.1.0/go.mod h1:DDDD="],
+    )
+    assert parse_sum_diff("") == ([], [])
+
+
+def test_verification_error():
+    error = VerificationError(
+        {"example.com/dep@v1.0.0": "dir has been modified"},
+        missing=["example.com/new v1.0.0 h1:AAAA="],
+    )
+
+    assert error.extra == []
+    assert str(error) == (
+        "Modules failed verification:\n"
+        "  example.com/dep@v1.0.0: dir has been modified\n"
+        "Missing from go.sum:\n"
+        "  example.com/new v1.0.0 h1:AAAA="
+    )
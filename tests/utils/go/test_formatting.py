@@ -0,0 +1,68 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from dda.utils.go.formatting import FormatResult, find_go_files, parse_format_diff
+
+
+def test_find_go_files(temp_dir):
+    for name in (
+        "main.go",
+        "main_test.go",
+        "README.md",
+        ".hidden.go",
+        "pkg/a.go",
+        "pkg/generated/b.go",
+        "other/generated/c.go",
+        "vendor/d.go",
+        "pkg/vendor/e.go",
+        ".git/f.go",
+    ):
+        (temp_dir / name).parent.ensure_dir()
+        (temp_dir / name).touch()
+
+    assert find_go_files(temp_dir, exclude=["vendor", "pkg/generated/"]) == [
+        "main.go",
+        "main_test.go",
+        "other/generated/c.go",
+        "pkg/a.go",
+    ]
+    assert find_go_files(temp_dir, ["pkg", "main.go"], exclude=["vendor"]) == [
+        "main.go",
+        "pkg/a.go",
+        "pkg/generated/b.go",
+    ]
+
+
+def test_parse_format_diff():
+    output = """\
+diff a.go.orig a.go
+--- a.go.orig
++++ a.go
+@@ -1,3 +1,4 @@
+ package a
+-func  A() {
++
++func A() {
+ }
+diff sub dir/c.go.orig sub dir/c.go
+--- sub dir/c.go.orig
++++ sub dir/c.go
+@@ -1,2 +1,3 @@
+ package a
+-var x=1
++
++var x = 1
+"""
+
+    diffs = parse_format_diff(output)
+
+    assert list(diffs) == ["a.go", "sub dir/c.go"]
+    assert diffs["a.go"].startswith("--- a.go.orig\n+++ a.go\n")
+    assert diffs["sub dir/c.go"].endswith("+var x = 1\n")
+
+
+def test_formatted():
+    assert FormatResult(files=()).formatted
+    assert not FormatResult(files=("a.go",)).formatted
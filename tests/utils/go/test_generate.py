@@ -0,0 +1,80 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import shutil
+
+from dda.utils.go.generate import Generator, changed_files, diff_directories, parse_generate_plan, snapshot_files
+
+
+def test_parse(temp_dir):
+    (temp_dir / "sub").mkdir()
+    (temp_dir / "gen.go").touch()
+    (temp_dir / "sub" / "sub.go").touch()
+    output = (
+        "gen.go\n"
+        "mockgen -source=gen.go -destination=mock_gen.go\n"
+        "protoc foo.proto\n"
+        "sub/sub.go\n"
+        "sh -c echo mockgen sub\n"
+    )
+
+    assert parse_generate_plan(output, temp_dir) == [
+        Generator(file="gen.go", command="mockgen -source=gen.go -destination=mock_gen.go"),
+        Generator(file="gen.go", command="protoc foo.proto"),
+        Generator(file="sub/sub.go", command="sh -c echo mockgen sub"),
+    ]
+
+
+def test_empty(temp_dir):
+    assert parse_generate_plan("", temp_dir) == []
+
+
+def test_str():
+    assert str(Generator(file="gen.go", command="protoc foo.proto")) == "gen.go: protoc foo.proto"
+
+
+def test_changed_files(temp_dir):
+    original = temp_dir / "original"
+    (original / ".git").mkdir(parents=True)
+    (original / "pkg").mkdir()
+    (original / "unchanged.go").write_bytes(b"package main\n")
+    (original / "crlf.go").write_bytes(b"package main\r\n\r\nfunc main() {}\r\n")
+    (original / "newline.go").write_bytes(b"package main\n")
+    (original / "pkg" / "stale.go").write_bytes(b"package pkg\n")
+    (original / "removed.go").write_bytes(b"package main\n")
+    copy = temp_dir / "copy"
+    shutil.copytree(original, copy)
+    snapshot = snapshot_files(copy)
+
+    (copy / ".git" / "index").write_bytes(b"")
+    (copy / "unchanged.go").write_bytes(b"package main\n")
+    (copy / "crlf.go").write_bytes(b"package main\n\nfunc main() {}\n")
+    (copy / "newline.go").write_bytes(b"package main\n\n")
+    (copy / "pkg" / "stale.go").write_bytes(b"package pkg\n\nvar x = 1\n")
+    (copy / "pkg" / "added.go").write_bytes(b"package pkg\n")
+    (copy / "removed.go").unlink()
+
+    assert changed_files(original, copy, snapshot) == ["pkg/added.go", "pkg/stale.go", "removed.go"]
+
+
+def test_diff_directories(temp_dir):
+    original = temp_dir / "original"
+    other = temp_dir / "other"
+    for root in (original, other):
+        (root / "sub").mkdir(parents=True)
+        (root / "same.go").write_bytes(b"package foo\n")
+        (root / "sub" / "changed.go").write_text("package sub\n")
+    (other / "same.go").write_bytes(b"package foo\r\n\n")
+    (other / "sub" / "changed.go").write_text("package sub\n\nconst X = 1\n")
+    (original / "removed.go").touch()
+    (other / "added.go").touch()
+
+    assert diff_directories(original, other) == ["added.go", "removed.go", "sub/changed.go"]
+
+
+def test_diff_missing_directory(temp_dir):
+    (temp_dir / "foo.go").touch()
+
+    assert diff_directories(temp_dir / "missing", temp_dir) == ["foo.go"]
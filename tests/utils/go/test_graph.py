@@ -0,0 +1,76 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.go.graph import ModuleGraph
+
+GRAPH_OUTPUT = """\
+example.com/main go@1.22
+example.com/main example.com/a@v1.1.0
+example.com/main example.com/b@v1.0.0
+example.com/a@v1.1.0 example.com/c@v1.2.0
+example.com/b@v1.0.0 example.com/c@v1.3.0-rc.1
+example.com/b@v1.0.0 example.com/d@v0.1.0
+example.com/c@v1.2.0 example.com/d@v0.2.0
+go@1.22 toolchain@go1.22.0
+"""
+
+
+@pytest.fixture(name="graph")
+def fixt_graph() -> ModuleGraph:
+    return ModuleGraph.from_lines(GRAPH_OUTPUT.splitlines())
+
+
+def test_edges(graph):
+    assert graph.edges["example.com/main"] == ("go@1.22", "example.com/a@v1.1.0", "example.com/b@v1.0.0")
+    assert graph.edges["example.com/d@v0.2.0"] == ()
+
+
+def test_selected(graph):
+    assert dict(graph.selected) == {
+        "example.com/a": "v1.1.0",
+        "example.com/b": "v1.0.0",
+        "example.com/c": "v1.3.0-rc.1",
+        "example.com/d": "v0.2.0",
+    }
+
+
+def test_requirements(graph):
+    assert graph.requirements("example.com/b") == ["example.com/c@v1.3.0-rc.1", "example.com/d@v0.1.0"]
+
+
+class TestDependents:
+    def test_exact(self, graph):
+        assert graph.dependents("example.com/d@v0.1.0") == ["example.com/b@v1.0.0"]
+
+    def test_any_version(self, graph):
+        assert graph.dependents("example.com/d") == ["example.com/b@v1.0.0", "example.com/c@v1.2.0"]
+
+    def test_unknown(self, graph):
+        assert graph.dependents("example.com/unknown") == []
+
+
+class TestPath:
+    def test_shortest(self, graph):
+        assert graph.path("example.com/main", "example.com/d") == [
+            "example.com/main",
+            "example.com/b@v1.0.0",
+            "example.com/d@v0.1.0",
+        ]
+
+    def test_exact_version(self, graph):
+        assert graph.path("example.com/main", "example.com/d@v0.2.0") == [
+            "example.com/main",
+            "example.com/a@v1.1.0",
+            "example.com/c@v1.2.0",
+            "example.com/d@v0.2.0",
+        ]
+
+    def test_self(self, graph):
+        assert graph.path("example.com/a", "example.com/a@v1.1.0") == ["example.com/a@v1.1.0"]
+
+    def test_unreachable(self, graph):
+        assert graph.path("example.com/d", "example.com/main") == []
@@ -0,0 +1,64 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.go.install import MANIFEST_NAME, ToolSpec, read_manifest, write_manifest
+
+
+class TestToolSpec:
+    def test_from_string(self):
+        tool = ToolSpec.from_string("go.uber.org/mock/mockgen@v0.5.0")
+
+        assert tool == ToolSpec(package="go.uber.org/mock/mockgen", version="v0.5.0")
+        assert str(tool) == "go.uber.org/mock/mockgen@v0.5.0"
+
+    @pytest.mark.parametrize("spec", ["go.uber.org/mock/mockgen", "@v1.0.0", "go.uber.org/mock/mockgen@"])
+    def test_invalid(self, spec):
+        with pytest.raises(ValueError, match="expected the format `package@version`"):
+            ToolSpec.from_string(spec)
+
+    @pytest.mark.parametrize(
+        ("version", "pinned"),
+        [
+            ("v1.2.3", True),
+            ("v0.0.0-20240101000000-abcdefabcdef", True),
+            ("v2.0.0+incompatible", True),
+            ("latest", False),
+            ("master", False),
+            ("upgrade", False),
+        ],
+    )
+    def test_pinned(self, version, pinned):
+        assert ToolSpec(package="example.com/tool", version=version).pinned is pinned
+
+    @pytest.mark.parametrize(
+        ("package", "goos", "expected"),
+        [
+            ("go.uber.org/mock/mockgen", "linux", "mockgen"),
+            ("go.uber.org/mock/mockgen", "windows", "mockgen.exe"),
+            ("github.com/golangci/golangci-lint/v2/cmd/golangci-lint", "linux", "golangci-lint"),
+            ("github.com/example/tool/v2", "linux", "tool"),
+            ("github.com/example/tool/v1", "linux", "v1"),
+            ("golang.org/x/tools/cmd/goimports", "darwin", "goimports"),
+        ],
+    )
+    def test_binary_name(self, package, goos, expected):
+        assert ToolSpec(package=package, version="v1.0.0").binary_name(goos) == expected
+
+
+class TestManifest:
+    def test_round_trip(self, temp_dir):
+        write_manifest(temp_dir, {"mockgen": "go.uber.org/mock/mockgen@v0.5.0"})
+
+        assert read_manifest(temp_dir) == {"mockgen": "go.uber.org/mock/mockgen@v0.5.0"}
+
+    def test_missing(self, temp_dir):
+        assert read_manifest(temp_dir) == {}
+
+    def test_corrupt(self, temp_dir):
+        (temp_dir / MANIFEST_NAME).write_text("{", encoding="utf-8")
+
+        assert read_manifest(temp_dir) == {}
@@ -0,0 +1,147 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import io
+from xml.etree.ElementTree import fromstring
+
+from dda.utils.go.junit import write_junit
+from dda.utils.go.testing import TestAction, TestEvent
+
+
+def event(action, package="example.com/pkg", test="", **kwargs):
+    return TestEvent(action=action, package=package, test=test, **kwargs)
+
+
+def report(events):
+    stream = io.StringIO()
+    write_junit(events, stream)
+    return fromstring(stream.getvalue())
+
+
+def test_results():
+    root = report([
+        event(TestAction.START, time="2026-01-02T03:04:05.123456Z"),
+        event(TestAction.RUN, test="TestPass"),
+        event(TestAction.OUTPUT, test="TestPass", output="=== RUN   TestPass\n"),
+        event(TestAction.PASS, test="TestPass", elapsed=0.25),
+        event(TestAction.RUN, test="TestFail"),
+        event(TestAction.RUN, test="TestFail/sub/case"),
+        event(TestAction.OUTPUT, test="TestFail/sub/case", output="=== RUN   TestFail/sub/case\n"),
+        event(TestAction.OUTPUT, test="TestFail/sub/case", output="    foo_test.go:12: got <nil>\n"),
+        event(TestAction.OUTPUT, test="TestFail/sub/case", output="--- FAIL: TestFail/sub/case (0.10s)\n"),
+        event(TestAction.FAIL, test="TestFail/sub/case", elapsed=0.1),
+        event(TestAction.FAIL, test="TestFail", elapsed=0.1),
+        event(TestAction.RUN, test="TestSkip"),
+        event(TestAction.OUTPUT, test="TestSkip", output="    foo_test.go:20: not supported\n"),
+        event(TestAction.SKIP, test="TestSkip"),
+        event(TestAction.OUTPUT, output="FAIL\n"),
+        event(TestAction.FAIL, elapsed=1.5),
+    ])
+
+    assert dict(root.attrib) == {"tests": "4", "failures": "2", "errors": "0", "skipped": "1", "time": "1.500"}
+    suite = root.find("testsuite")
+    assert dict(suite.attrib) == {
+        "name": "example.com/pkg",
+        "tests": "4",
+        "failures": "2",
+        "errors": "0",
+        "skipped": "1",
+        "time": "1.500",
+        "timestamp": "2026-01-02T03:04:05",
+    }
+    cases = {case.get("name"): case for case in suite.iter("testcase")}
+    assert list(cases) == ["TestPass", "TestFail", "TestFail/sub/case", "TestSkip"]
+    assert cases["TestPass"].get("time") == "0.250"
+    assert cases["TestPass"].find("failure") is None
+
+    failure = cases["TestFail/sub/case"].find("failure")
+    assert failure.get("message") == "foo_test.go:12: got <nil>"
+    assert "    foo_test.go:12: got <nil>\n" in failure.text
+    assert cases["TestFail"].find("failure").get("message") == "Failed"
+
+    skipped = cases["TestSkip"].find("skipped")
+    assert skipped.get("message") == "foo_test.go:20: not supported"
+
+
+def test_build_failure():
+    root = report([
+        TestEvent(action=TestAction.BUILD_OUTPUT, import_path="example.com/pkg [example.com/pkg.test]", output="# x\n"),
+        TestEvent(
+            action=TestAction.BUILD_OUTPUT,
+            import_path="example.com/pkg [example.com/pkg.test]",
+            output="./foo.go:3:12: undefined: bar\n",
+        ),
+        TestEvent(action=TestAction.BUILD_FAIL, import_path="example.com/pkg [example.com/pkg.test]"),
+        event(TestAction.START),
+        event(TestAction.OUTPUT, output="FAIL\texample.com/pkg [build failed]\n"),
+        event(TestAction.FAIL, elapsed=0, failed_build="example.com/pkg [example.com/pkg.test]"),
+    ])
+
+    suite = root.find("testsuite")
+    assert suite.get("errors") == "1"
+    case = suite.find("testcase")
+    assert case.get("name") == "[build failed]"
+    error = case.find("error")
+    assert error.get("message") == "Failed to build example.com/pkg"
+    assert error.text == "# x\n./foo.go:3:12: undefined: bar\n"
+
+
+def test_build_failure_stderr():
+    root = report([
+        TestEvent(action=TestAction.STDERR, output="# example.com/other\n"),
+        TestEvent(action=TestAction.STDERR, output="./other.go:1:1: expected 'package'\n"),
+        TestEvent(action=TestAction.STDERR, output="# example.com/pkg [example.com/pkg.test]\n"),
+        TestEvent(action=TestAction.STDERR, output="./foo.go:3:12: undefined: bar\n"),
+        event(TestAction.OUTPUT, output="FAIL\texample.com/pkg [build failed]\n"),
+        event(TestAction.FAIL, elapsed=0),
+    ])
+
+    error = root.find("testsuite/testcase/error")
+    assert error.text == "# example.com/pkg [example.com/pkg.test]\n./foo.go:3:12: undefined: bar\n"
+
+
+def test_package_failure():
+    root = report([
+        event(TestAction.RUN, test="TestPass"),
+        event(TestAction.PASS, test="TestPass"),
+        event(TestAction.OUTPUT, output="FAIL\n"),
+        event(TestAction.OUTPUT, output="TestMain: setup failed\n"),
+        event(TestAction.FAIL, elapsed=0.5),
+    ])
+
+    suite = root.find("testsuite")
+    assert (suite.get("tests"), suite.get("errors"), suite.get("failures")) == ("2", "1", "0")
+    assert suite.find("testcase[@name='[package failed]']/error").get("message") == "TestMain: setup failed"
+
+
+def test_incomplete_test():
+    root = report([
+        event(TestAction.RUN, test="TestHang"),
+        event(TestAction.OUTPUT, output="panic: test timed out after 1s\n"),
+        event(TestAction.FAIL, elapsed=1),
+    ])
+
+    suite = root.find("testsuite")
+    assert suite.get("errors") == "0"
+    assert suite.find("testcase[@name='TestHang']/failure").get("message") == "Test did not complete"
+
+
+def test_invalid_characters():
+    root = report([
+        event(TestAction.RUN, test="TestColor"),
+        event(TestAction.OUTPUT, test="TestColor", output="\x1b[31mred\x1b[0m\n"),
+        event(TestAction.FAIL, test="TestColor"),
+    ])
+
+    failure = root.find("testsuite/testcase/failure")
+    assert failure.get("message") == "\ufffd[31mred\ufffd[0m"
+    assert failure.text == "\ufffd[31mred\ufffd[0m\n"
+
+
+def test_empty():
+    root = report([])
+
+    assert root.tag == "testsuites"
+    assert root.get("tests") == "0"
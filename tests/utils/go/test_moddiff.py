@@ -0,0 +1,180 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import json
+
+import pytest
+
+from dda.utils.go.moddiff import ModuleChange, Requirement, diff_requirements, parse_requirements
+
+
+def edit_output(require=(), replace=()):
+    return json.dumps({"Module": {"Path": "example.com/main"}, "Require": list(require), "Replace": list(replace)})
+
+
+def requirements(*items):
+    return {item.path: item for item in items}
+
+
+class TestParseRequirements:
+    def test_basic(self):
+        output = edit_output([
+            {"Path": "example.com/a", "Version": "v1.0.0"},
+            {"Path": "example.com/b", "Version": "v0.1.0", "Indirect": True},
+        ])
+
+        assert parse_requirements(output) == {
+            "example.com/a": Requirement("example.com/a", "v1.0.0"),
+            "example.com/b": Requirement("example.com/b", "v0.1.0", indirect=True),
+        }
+
+    def test_no_requirements(self):
+        assert parse_requirements(json.dumps({"Module": {"Path": "example.com/main"}, "Require": None})) == {}
+
+    def test_replacements(self):
+        output = edit_output(
+            [
+                {"Path": "example.com/a", "Version": "v1.0.0"},
+                {"Path": "example.com/b", "Version": "v1.0.0"},
+                {"Path": "example.com/c", "Version": "v1.0.0"},
+            ],
+            [
+                {"Old": {"Path": "example.com/a"}, "New": {"Path": "../a"}},
+                {"Old": {"Path": "example.com/b", "Version": "v1.0.0"}, "New": {"Path": "fork/b", "Version": "v1.0.1"}},
+                {"Old": {"Path": "example.com/c", "Version": "v0.9.0"}, "New": {"Path": "../c"}},
+            ],
+        )
+
+        parsed = parse_requirements(output)
+
+        assert parsed["example.com/a"].replacement == "../a"
+        assert parsed["example.com/b"].replacement == "fork/b@v1.0.1"
+        assert not parsed["example.com/c"].replacement
+
+    def test_version_replacement_precedence(self):
+        output = edit_output(
+            [{"Path": "example.com/a", "Version": "v1.0.0"}],
+            [
+                {"Old": {"Path": "example.com/a"}, "New": {"Path": "../a"}},
+                {"Old": {"Path": "example.com/a", "Version": "v1.0.0"}, "New": {"Path": "../a-v1"}},
+            ],
+        )
+
+        assert parse_requirements(output)["example.com/a"].replacement == "../a-v1"
+
+
+class TestDiffRequirements:
+    def test_no_changes(self):
+        old = requirements(Requirement("example.com/a", "v1.0.0"))
+
+        assert diff_requirements(old, old).changes == ()
+
+    def test_added_removed_changed(self):
+        old = requirements(
+            Requirement("example.com/a", "v1.0.0"),
+            Requirement("example.com/b", "v1.0.0", indirect=True),
+        )
+        new = requirements(
+            Requirement("example.com/a", "v1.1.0"),
+            Requirement("example.com/c", "v0.1.0", indirect=True),
+        )
+
+        report = diff_requirements(old, new)
+
+        assert report.changes == (
+            ModuleChange("example.com/a", old_version="v1.0.0", new_version="v1.1.0"),
+            ModuleChange("example.com/b", old_version="v1.0.0", indirect=True),
+            ModuleChange("example.com/c", new_version="v0.1.0", indirect=True),
+        )
+        assert [change.path for change in report.added] == ["example.com/c"]
+        assert [change.path for change in report.removed] == ["example.com/b"]
+        assert [change.path for change in report.changed] == ["example.com/a"]
+        assert [change.path for change in report.direct] == ["example.com/a"]
+        assert [change.path for change in report.indirect] == ["example.com/b", "example.com/c"]
+        assert not report.major_bumps
+
+    def test_replacement_changed(self):
+        old = requirements(Requirement("example.com/a", "v1.0.0"))
+        new = requirements(Requirement("example.com/a", "v1.0.0", replacement="../a"))
+
+        assert diff_requirements(old, new).changes == (
+            ModuleChange("example.com/a", old_version="v1.0.0", new_version="v1.0.0", new_replacement="../a"),
+        )
+
+    def test_indirect_only_changed(self):
+        old = requirements(Requirement("example.com/a", "v1.0.0"))
+        new = requirements(Requirement("example.com/a", "v1.0.0", indirect=True))
+
+        assert diff_requirements(old, new).changes == ()
+
+    @pytest.mark.parametrize(
+        ("old_path", "old_version", "new_path", "new_version"),
+        [
+            pytest.param("example.com/a", "v1.5.0", "example.com/a/v2", "v2.0.0", id="path element"),
+            pytest.param("example.com/a/v2", "v2.5.0", "example.com/a/v3", "v3.0.0", id="between suffixes"),
+            pytest.param("gopkg.in/yaml.v2", "v2.4.0", "gopkg.in/yaml.v3", "v3.0.1", id="gopkg.in"),
+            pytest.param(
+                "gopkg.in/DataDog/dd-trace-go.v1",
+                "v1.60.0",
+                "gopkg.in/DataDog/dd-trace-go.v2",
+                "v2.0.0",
+                id="gopkg.in with user",
+            ),
+        ],
+    )
+    def test_major_path_change(self, old_path, old_version, new_path, new_version):
+        old = requirements(Requirement(old_path, old_version))
+        new = requirements(Requirement(new_path, new_version))
+
+        report = diff_requirements(old, new)
+
+        assert report.changes == (
+            ModuleChange(new_path, old_version=old_version, new_version=new_version, previous_path=old_path),
+        )
+        assert report.changed == report.major_bumps == list(report.changes)
+
+    def test_major_path_change_both_kept(self):
+        old = requirements(Requirement("example.com/a", "v1.5.0"))
+        new = requirements(Requirement("example.com/a", "v1.5.0"), Requirement("example.com/a/v2", "v2.0.0"))
+
+        report = diff_requirements(old, new)
+
+        assert report.changes == (ModuleChange("example.com/a/v2", new_version="v2.0.0"),)
+        assert not report.major_bumps
+
+    def test_different_gopkg_in_modules(self):
+        old = requirements(Requirement("gopkg.in/check.v1", "v1.0.0-20201130134442-10cb98267c6c"))
+        new = requirements(Requirement("gopkg.in/yaml.v3", "v3.0.1"))
+
+        report = diff_requirements(old, new)
+
+        assert report.changes == (
+            ModuleChange("gopkg.in/check.v1", old_version="v1.0.0-20201130134442-10cb98267c6c"),
+            ModuleChange("gopkg.in/yaml.v3", new_version="v3.0.1"),
+        )
+        assert not report.major_bumps
+
+    def test_major_path_downgrade(self):
+        old = requirements(Requirement("example.com/a/v2", "v2.0.0"))
+        new = requirements(Requirement("example.com/a", "v1.5.0"))
+
+        assert diff_requirements(old, new).changes == (
+            ModuleChange("example.com/a", new_version="v1.5.0"),
+            ModuleChange("example.com/a/v2", old_version="v2.0.0"),
+        )
+
+    def test_incompatible_major_bump(self):
+        old = requirements(Requirement("example.com/a", "v1.5.0"))
+        new = requirements(Requirement("example.com/a", "v2.0.0+incompatible"))
+
+        assert diff_requirements(old, new).major_bumps == [
+            ModuleChange("example.com/a", old_version="v1.5.0", new_version="v2.0.0+incompatible")
+        ]
+
+    def test_pseudo_version_not_major_bump(self):
+        old = requirements(Requirement("example.com/a", "v0.0.0-20240101000000-abcdefabcdef"))
+        new = requirements(Requirement("example.com/a", "v0.1.0"))
+
+        assert not diff_requirements(old, new).major_bumps
@@ -0,0 +1,257 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.fs import Path
+from dda.utils.go.modules import (
+    Module,
+    NoModuleError,
+    Workspace,
+    check_toolchain,
+    detect_project_root,
+    detect_workspace,
+    find_modules,
+    find_workspace_file,
+    local_replacements,
+    required_version,
+)
+from dda.utils.go.version import Version
+from dda.utils.process import EnvVars
+
+
+@pytest.fixture(name="nested_modules")
+def fixt_nested_modules(temp_dir):
+    (temp_dir / "go.work").write_text("go 1.22\n\nuse ./outer\n")
+    outer = temp_dir / "outer"
+    inner = outer / "pkg" / "inner"
+    (inner / "sub").mkdir(parents=True)
+    (outer / "go.mod").write_text("module example.com/outer\n")
+    (inner / "go.mod").write_text("module example.com/inner\n")
+    return temp_dir
+
+
+class TestDetectProjectRoot:
+    def test_current_directory(self, nested_modules):
+        outer = nested_modules / "outer"
+        with outer.as_cwd():
+            assert detect_project_root() == outer.resolve()
+
+    def test_nearest_module(self, nested_modules):
+        inner = nested_modules / "outer" / "pkg" / "inner"
+
+        assert detect_project_root(inner / "sub") == inner.resolve()
+        assert detect_project_root(nested_modules / "outer" / "pkg") == (nested_modules / "outer").resolve()
+
+    def test_file(self, nested_modules):
+        inner = nested_modules / "outer" / "pkg" / "inner"
+
+        assert detect_project_root(inner / "go.mod") == inner.resolve()
+
+    @pytest.mark.skip_windows  # Symbolic links require elevated privileges
+    def test_symlink(self, nested_modules, temp_dir):
+        link = temp_dir / "link"
+        link.symlink_to(nested_modules / "outer" / "pkg" / "inner" / "sub", target_is_directory=True)
+
+        assert detect_project_root(link) == detect_project_root(nested_modules / "outer" / "pkg" / "inner")
+
+    def test_no_module(self, temp_dir):
+        with pytest.raises(NoModuleError) as exc_info:
+            detect_project_root(temp_dir)
+
+        assert exc_info.value.start == temp_dir.resolve()
+
+
+class TestFindWorkspaceFile:
+    def test_found(self, nested_modules):
+        with EnvVars(exclude=["GOWORK"]):
+            assert find_workspace_file(nested_modules / "outer" / "pkg") == (nested_modules / "go.work").resolve()
+
+    def test_not_found(self, temp_dir):
+        with EnvVars(exclude=["GOWORK"]):
+            assert find_workspace_file(temp_dir) is None
+
+    def test_disabled(self, nested_modules):
+        with EnvVars({"GOWORK": "off"}):
+            assert find_workspace_file(nested_modules / "outer") is None
+
+    def test_override(self, nested_modules):
+        custom = nested_modules / "custom.work"
+        with EnvVars({"GOWORK": str(custom)}):
+            assert find_workspace_file(nested_modules / "outer") == custom.resolve()
+
+
+class TestDetectWorkspace:
+    def test_use_directives(self, temp_dir):
+        (temp_dir / "go.work").write_text(
+            "go 1.22\n\nuse ./tools // single\n\nuse (\n\t./a\n\t\"./b\" // quoted\n\n\t../outside\n)\n"
+        )
+        (temp_dir / "a" / "pkg").mkdir(parents=True)
+        root = temp_dir.resolve()
+
+        with EnvVars(exclude=["GOWORK"]):
+            workspace = detect_workspace(temp_dir / "a" / "pkg")
+
+        assert workspace == Workspace(
+            file=root / "go.work",
+            modules=(root / "tools", root / "a", root / "b", root.parent / "outside"),
+        )
+        assert workspace.directory == root
+
+    def test_not_found(self, temp_dir):
+        with EnvVars(exclude=["GOWORK"]):
+            assert detect_workspace(temp_dir) is None
+
+    def test_disabled(self, nested_modules):
+        with EnvVars({"GOWORK": "off"}):
+            assert detect_workspace(nested_modules / "outer") is None
+
+
+class TestRequiredVersion:
+    def test_go_directive(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22 // comment\n")
+
+        assert required_version(temp_dir) == Version(major=1, minor=22)
+
+    def test_toolchain_directive(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22\n\ntoolchain go1.22.3\n")
+
+        assert required_version(temp_dir) == Version(major=1, minor=22, patch=3)
+
+    def test_older_toolchain_directive(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22.1\n\ntoolchain go1.21.3\n")
+
+        assert required_version(temp_dir) == Version(major=1, minor=22, patch=1)
+
+    def test_default_toolchain(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22\n\ntoolchain default\n")
+
+        assert required_version(temp_dir) == Version(major=1, minor=22)
+
+    def test_no_directive(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n")
+
+        assert required_version(temp_dir) == Version(major=1, minor=16)
+
+    def test_current_module(self, nested_modules):
+        (nested_modules / "outer" / "go.mod").write_text("module example.com/outer\n\ngo 1.21\n")
+
+        with (nested_modules / "outer" / "pkg").as_cwd():
+            assert required_version() == Version(major=1, minor=21)
+
+    def test_invalid(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo foo\n")
+
+        with pytest.raises(ValueError, match="Invalid Go version: foo"):
+            required_version(temp_dir)
+
+    def test_no_module(self, temp_dir):
+        with pytest.raises(NoModuleError):
+            required_version(temp_dir)
+
+
+class TestCheckToolchain:
+    @pytest.mark.parametrize(("toolchain", "expected"), [("go1.22.3", "go1.22.3"), ("1.23rc1", "go1.23rc1")])
+    def test_valid(self, temp_dir, toolchain, expected):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22 // comment\n")
+
+        assert check_toolchain(temp_dir, toolchain) == expected
+
+    def test_no_go_directive(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo")
+
+        assert check_toolchain(temp_dir, "go1.22.3") == "go1.22.3"
+
+    @pytest.mark.parametrize("toolchain", ["foo", "go1.22", "local"])
+    def test_invalid(self, temp_dir, toolchain):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n")
+
+        with pytest.raises(ValueError, match="Invalid"):
+            check_toolchain(temp_dir, toolchain)
+
+    def test_older_than_go_directive(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\ngo 1.22.1\n")
+
+        with pytest.raises(
+            ValueError, match="Toolchain `go1.22.0` is older than the version required by the `go` directive: 1.22.1"
+        ):
+            check_toolchain(temp_dir, "go1.22.0")
+
+    def test_no_module(self, temp_dir):
+        with pytest.raises(NoModuleError):
+            check_toolchain(temp_dir, "go1.22.3")
+
+
+class TestLocalReplacements:
+    def test_directives(self, temp_dir):
+        (temp_dir / "go.mod").write_text(
+            "module example.com/foo\n\n"
+            "replace example.com/bar => ../bar // comment\n\n"
+            "replace (\n"
+            "\texample.com/baz v1.0.0 => ./third_party/baz\n"
+            "\texample.com/qux => example.com/fork/qux v1.2.0\n"
+            '\t"example.com/quux" => "/opt/quux"\n'
+            ")\n"
+        )
+
+        assert local_replacements(temp_dir) == [
+            (temp_dir.parent / "bar").resolve(),
+            (temp_dir / "third_party" / "baz").resolve(),
+            Path("/opt/quux").resolve(),
+        ]
+
+    def test_none(self, temp_dir):
+        (temp_dir / "go.mod").write_text("module example.com/foo\n\nrequire example.com/bar v1.0.0\n")
+
+        assert local_replacements(temp_dir) == []
+
+    def test_no_module(self, temp_dir):
+        with pytest.raises(NoModuleError):
+            local_replacements(temp_dir)
+
+
+class TestFindModules:
+    @pytest.fixture(name="monorepo")
+    def fixt_monorepo(self, temp_dir):
+        modules = {
+            "": "module example.com/root\n",
+            "pkg/api": "// API types\nmodule example.com/root/pkg/api // comment\n\ngo 1.22\n",
+            "pkg/api/nested": 'module "example.com/root/pkg/api/nested"\n',
+            "tools": "module example.com/root/tools\n",
+            "test/e2e/suite": "module example.com/root/test/e2e/suite\n",
+            "vendor/example.com/dep": "module example.com/dep\n",
+            "pkg/testdata/fixture": "module example.com/fixture\n",
+            ".git/modules/sub": "module example.com/sub\n",
+        }
+        root = temp_dir / "repo"
+        for directory, contents in modules.items():
+            (root / directory).mkdir(parents=True, exist_ok=True)
+            (root / directory / "go.mod").write_text(contents)
+
+        return root.resolve()
+
+    def test_all(self, monorepo):
+        assert find_modules(monorepo) == [
+            Module(path="example.com/root", directory=monorepo),
+            Module(path="example.com/root/pkg/api", directory=monorepo / "pkg" / "api"),
+            Module(path="example.com/root/pkg/api/nested", directory=monorepo / "pkg" / "api" / "nested"),
+            Module(path="example.com/root/test/e2e/suite", directory=monorepo / "test" / "e2e" / "suite"),
+            Module(path="example.com/root/tools", directory=monorepo / "tools"),
+        ]
+
+    def test_ignore(self, monorepo):
+        modules = find_modules(monorepo, ignore=["tools", "test/*"])
+
+        assert [module.path for module in modules] == [
+            "example.com/root",
+            "example.com/root/pkg/api",
+            "example.com/root/pkg/api/nested",
+        ]
+
+    def test_top_level_only(self, monorepo):
+        assert [module.path for module in find_modules(monorepo / "pkg", top_level_only=True)] == [
+            "example.com/root/pkg/api"
+        ]
+        assert [module.path for module in find_modules(monorepo, top_level_only=True)] == ["example.com/root"]
@@ -0,0 +1,66 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import io
+import json
+import os
+
+from dda.utils.go.observer import Invocation, JSONObserver, Observer, env_var_delta
+from dda.utils.process import EnvVars
+
+
+class RedactingObserver(Observer):
+    def filter_env_var(self, name, value):
+        if name == "DROPPED":
+            return None
+
+        return "***" if name.endswith("_TOKEN") else value
+
+
+def test_shell_command():
+    invocation = Invocation(
+        command=["/usr/bin/go", "build", "-ldflags=-s -w", "./..."],
+        env_vars={"GOOS": "linux", "CGO_ENABLED": "0"},
+        cwd="/src/my project",
+    )
+
+    assert invocation.shell_command == (
+        "cd '/src/my project' && CGO_ENABLED=0 GOOS=linux /usr/bin/go build '-ldflags=-s -w' ./..."
+    )
+
+
+class TestEnvVarDelta:
+    def test_inherited(self):
+        with EnvVars({"GOOS": "linux"}, exclude=["GOTOOLCHAIN"]):
+            assert env_var_delta(None, {"GOTOOLCHAIN": "go1.22.3"}, ()) == {"GOTOOLCHAIN": "go1.22.3"}
+
+    def test_overrides(self):
+        with EnvVars({"GOOS": "linux"}, exclude=["GOTOOLCHAIN"]):
+            env = {**os.environ, "GOOS": "darwin", "GOTOOLCHAIN": "local"}
+
+            assert env_var_delta(env, {"GOTOOLCHAIN": "go1.22.3"}, ()) == {"GOOS": "darwin", "GOTOOLCHAIN": "local"}
+
+    def test_filter(self):
+        env = {**os.environ, "API_TOKEN": "secret", "DROPPED": "1", "GOOS": "plan9"}
+
+        assert env_var_delta(env, {}, (Observer(), RedactingObserver())) == {"API_TOKEN": "***", "GOOS": "plan9"}
+
+
+def test_json_observer():
+    stream = io.StringIO()
+    observer = JSONObserver(stream)
+    invocation = Invocation(command=["go", "version"], env_vars={}, cwd="/src")
+
+    observer.started(invocation)
+    assert not stream.getvalue()
+
+    observer.finished(Invocation(command=["go", "version"], env_vars={}, cwd="/src", duration=0.5, exit_code=0))
+    assert json.loads(stream.getvalue()) == {
+        "command": ["go", "version"],
+        "env_vars": {},
+        "cwd": "/src",
+        "duration": 0.5,
+        "exit_code": 0,
+    }
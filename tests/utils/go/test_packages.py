@@ -0,0 +1,308 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.fs import Path
+from dda.utils.go.constraints import BuildConstraintError, BuildContext
+from dda.utils.go.packages import (
+    PackageImports,
+    affected_packages,
+    embed_files,
+    package_files,
+    parse_embed_patterns,
+    parse_package_list,
+    source_files,
+)
+
+FIXTURES = Path(__file__).parent.parent.parent / "tools" / "go" / "fixtures" / "small_go_project"
+
+
+@pytest.fixture(name="package")
+def fixt_package(temp_dir):
+    files = {
+        "main.go": "package main\n",
+        "main_test.go": "package main\n",
+        "os_linux.go": "package main\n",
+        "os_windows.go": "package main\n",
+        "arch_linux_arm64.go": "package main\n",
+        "cgo_linux.go": "//go:build cgo\n\npackage main\n",
+        "foo_plan10.go": "package main\n",
+        "_ignored.go": "package main\n",
+        ".hidden.go": "package main\n",
+        "README.md": "//go:build ignore\n",
+    }
+    for name, contents in files.items():
+        (temp_dir / name).write_text(contents)
+
+    return temp_dir
+
+
+def test_suffixes(package):
+    files = package_files(package, BuildContext(goos="linux", goarch="amd64"))
+
+    assert [f.name for f in files] == ["foo_plan10.go", "main.go", "os_linux.go"]
+
+
+def test_suffix_and_constraint(package):
+    files = package_files(package, BuildContext(goos="linux", goarch="arm64", cgo=True))
+
+    assert [f.name for f in files] == [
+        "arch_linux_arm64.go",
+        "cgo_linux.go",
+        "foo_plan10.go",
+        "main.go",
+        "os_linux.go",
+    ]
+
+
+@pytest.mark.parametrize(("tags", "expected"), [(frozenset(), "prod.go"), (frozenset({"debug"}), "debug.go")])
+def test_tags(tags, expected):
+    files = package_files(FIXTURES, BuildContext(goos="linux", goarch="amd64", tags=tags))
+
+    assert [f.name for f in files] == sorted([expected, "main.go"])
+
+
+def test_invalid_constraint(temp_dir):
+    (temp_dir / "main.go").write_text("//go:build linux &&\n\npackage main\n")
+
+    with pytest.raises(BuildConstraintError, match="main.go"):
+        package_files(temp_dir, BuildContext(goos="linux", goarch="amd64"))
+
+
+def test_source_files(package):
+    (package / "hello.c").write_text("void hello(void) {}\n")
+    (package / "hello.h").write_text("void hello(void);\n")
+    (package / "asm_arm64.s").write_text("TEXT ·f(SB),$0\n")
+    (package / "debug.s").write_text("//go:build debug\n\nTEXT ·g(SB),$0\n")
+    (package / "rsrc_linux.syso").write_bytes(b"\x00\xff")
+    (package / "rsrc_windows.syso").write_bytes(b"\x00\xff")
+
+    files = source_files(package, BuildContext(goos="linux", goarch="amd64"))
+
+    assert [f.name for f in files] == [
+        "foo_plan10.go",
+        "hello.c",
+        "hello.h",
+        "main.go",
+        "os_linux.go",
+        "rsrc_linux.syso",
+    ]
+
+
+def test_parse_package_list():
+    output = """{
+	"ImportPath": "example.com/imp",
+	"Imports": [
+		"fmt",
+		"os"
+	],
+	"TestImports": [
+		"testing"
+	],
+	"Deps": [
+		"errors",
+		"fmt",
+		"os"
+	]
+}
+{
+	"ImportPath": "runtime/cgo",
+	"CgoFiles": [
+		"cgo.go"
+	],
+	"Standard": true
+}
+"""
+
+    assert parse_package_list(output) == [
+        PackageImports(
+            import_path="example.com/imp",
+            imports=("fmt", "os"),
+            test_imports=("testing",),
+            deps=("errors", "fmt", "os"),
+        ),
+        PackageImports(import_path="runtime/cgo", cgo_files=("cgo.go",), standard=True),
+    ]
+
+
+def test_parse_package_list_modules():
+    output = """\
+{"ImportPath": "example.com/main", "Module": {"Path": "example.com/main", "Main": true}}
+{"ImportPath": "example.com/dep", "Module": {"Path": "example.com/dep", "Version": "v1.0.0"}}
+{"ImportPath": "example.com/local", "Module": {"Path": "example.com/local", "Version": "v1.0.0", "Replace": {"Path": "../local"}}}
+{"ImportPath": "example.com/fork", "Module": {"Path": "example.com/fork", "Version": "v1.0.0", "Replace": {"Path": "example.com/other", "Version": "v1.1.0"}}}
+{"ImportPath": "fmt"}
+"""
+
+    assert [(package.module, package.module_version) for package in parse_package_list(output)] == [
+        ("example.com/main", ""),
+        ("example.com/dep", "v1.0.0"),
+        ("example.com/local", ""),
+        ("example.com/fork", "v1.0.0"),
+        ("", ""),
+    ]
+
+
+@pytest.mark.parametrize(
+    ("source", "expected"),
+    [
+        ('package main\n\nimport _ "embed"\n\n//go:embed version.txt\nvar version string\n', ["version.txt"]),
+        ("//go:embed static/*.html  templates\n", ["static/*.html", "templates"]),
+        ("\t//go:embed all:assets\n", ["all:assets"]),
+        (
+            '//go:embed "with space.txt" `raw name.txt` "esc\\u0041.txt"\n',
+            ["with space.txt", "raw name.txt", "escA.txt"],
+        ),
+        ("//go:embed a.txt\r\n//go:embed b.txt\n", ["a.txt", "b.txt"]),
+        ('//go:embed a.txt "unterminated\n', ["a.txt"]),
+        ('//go:embed "a.txt"b.txt\n', []),
+        ("//go:embedded a.txt\n// go:embed b.txt\nvar s = `//go:embed` // c.txt\n", []),
+    ],
+)
+def test_parse_embed_patterns(source, expected):
+    assert parse_embed_patterns(source) == expected
+
+
+class TestEmbedFiles:
+    @pytest.fixture(name="package")
+    def fixt_package(self, temp_dir):
+        for name in (
+            "version.txt",
+            ".env",
+            "static/index.html",
+            "static/app.js",
+            "static/.hidden.html",
+            "static/_draft.html",
+            "static/css/main.css",
+            "static/.git/HEAD",
+            "static/nested/go.mod",
+            "static/nested/file.txt",
+            "star[1].txt",
+        ):
+            (temp_dir / name).parent.mkdir(parents=True, exist_ok=True)
+            (temp_dir / name).write_text(name)
+
+        return temp_dir
+
+    def test_file(self, package):
+        assert embed_files(package, ["version.txt"]) == [package / "version.txt"]
+
+    def test_directory(self, package):
+        assert embed_files(package, ["static"]) == [
+            package / "static" / "app.js",
+            package / "static" / "css" / "main.css",
+            package / "static" / "index.html",
+        ]
+
+    def test_all_prefix(self, package):
+        assert embed_files(package, ["all:static"]) == [
+            package / "static" / ".hidden.html",
+            package / "static" / "_draft.html",
+            package / "static" / "app.js",
+            package / "static" / "css" / "main.css",
+            package / "static" / "index.html",
+        ]
+
+    def test_glob(self, package):
+        # Files that are matched directly are embedded even if they are hidden
+        assert embed_files(package, ["static/*.html"]) == [
+            package / "static" / ".hidden.html",
+            package / "static" / "_draft.html",
+            package / "static" / "index.html",
+        ]
+        # Only `^` negates a character class
+        assert embed_files(package, ["*/css", ".env", "star\\[1].txt", "version.tx[!t]", "version.tx[^t]"]) == [
+            package / ".env",
+            package / "star[1].txt",
+            package / "static" / "css" / "main.css",
+            package / "version.txt",
+        ]
+
+    def test_deduplicated(self, package):
+        assert embed_files(package, ["static/app.js", "static/*.js", "static"]) == [
+            package / "static" / "app.js",
+            package / "static" / "css" / "main.css",
+            package / "static" / "index.html",
+        ]
+
+    def test_other_module(self, package):
+        assert embed_files(package, ["static/nested", "static/nested/file.txt", "static/*/file.txt"]) == []
+
+    @pytest.mark.parametrize(
+        "pattern", ["", ".", "/version.txt", "../version.txt", "static/../version.txt", "[", "missing"]
+    )
+    def test_ignored(self, package, pattern):
+        assert embed_files(package, [pattern]) == []
+
+
+class TestAffectedPackages:
+    @pytest.fixture(name="module")
+    def fixt_module(self, temp_dir):
+        # app -> api -> store, cli imports app, and the tests of tools import api
+        for directory in ("api", "store", "store/testdata", "cli", "tools", "docs"):
+            (temp_dir / directory).mkdir()
+
+        root = temp_dir.resolve()
+        packages = [
+            PackageImports("example.com/m", directory=str(root), imports=("example.com/m/api",)),
+            PackageImports("example.com/m/api", directory=str(root / "api"), imports=("example.com/m/store",)),
+            PackageImports("example.com/m/store", directory=str(root / "store"), imports=("fmt",)),
+            PackageImports("example.com/m/cli", directory=str(root / "cli"), imports=("example.com/m",)),
+            PackageImports("example.com/m/tools", directory=str(root / "tools"), xtest_imports=("example.com/m/api",)),
+        ]
+        return root, packages
+
+    def test_transitive(self, module):
+        root, packages = module
+
+        assert affected_packages(packages, ["store/store.go"], root) == [
+            "example.com/m",
+            "example.com/m/api",
+            "example.com/m/cli",
+            "example.com/m/store",
+            "example.com/m/tools",
+        ]
+
+    @pytest.mark.parametrize(
+        ("depth", "expected"),
+        [
+            (0, ["example.com/m/store"]),
+            (1, ["example.com/m/api", "example.com/m/store"]),
+            (2, ["example.com/m", "example.com/m/api", "example.com/m/store", "example.com/m/tools"]),
+        ],
+    )
+    def test_depth(self, module, depth, expected):
+        root, packages = module
+
+        assert affected_packages(packages, ["store/store.go"], root, depth=depth) == expected
+
+    def test_test_imports(self, module):
+        root, packages = module
+
+        assert affected_packages(packages, ["api/api.go"], root) == [
+            "example.com/m",
+            "example.com/m/api",
+            "example.com/m/cli",
+            "example.com/m/tools",
+        ]
+        assert "example.com/m/tools" not in affected_packages(packages, ["api/api.go"], root, tests=False)
+
+    def test_closest_package(self, module):
+        root, packages = module
+
+        assert affected_packages(packages, ["store/testdata/fixture.json"], root, depth=0) == ["example.com/m/store"]
+        assert affected_packages(packages, ["docs/index.md", root / "README.md"], root, depth=0) == ["example.com/m"]
+
+    def test_outside_root(self, module, temp_dir):
+        root, packages = module
+
+        assert affected_packages(packages, [temp_dir.parent / "other.go", "../other.go"], root) == []
+
+    @pytest.mark.parametrize("changed_file", ["go.mod", "go.sum", "vendor/modules.txt"])
+    def test_module_files(self, module, changed_file):
+        root, packages = module
+
+        assert len(affected_packages(packages, [changed_file], root, depth=0)) == len(packages)
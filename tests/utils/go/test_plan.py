@@ -0,0 +1,31 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from dda.utils.go.plan import CommandSpec, Plan
+
+
+def test_command_string():
+    command = CommandSpec(
+        command=["/usr/bin/go", "build", "-ldflags=-X 'main.Version=1.0'", "-o=bin/my agent", "./..."],
+        env_vars={"GOOS": "linux", "GOFLAGS": "-mod=mod -tags=a,b", "EMPTY": ""},
+        cwd="/src/it's here",
+    )
+
+    assert str(command) == (
+        "cd '/src/it'\"'\"'s here' && EMPTY='' GOFLAGS='-mod=mod -tags=a,b' GOOS=linux /usr/bin/go build "
+        "'-ldflags=-X '\"'\"'main.Version=1.0'\"'\"'' '-o=bin/my agent' ./..."
+    )
+
+
+def test_plan_string():
+    plan = Plan(
+        commands=(
+            CommandSpec(command=["go", "build", "."], env_vars={"GOOS": "linux"}, cwd="/src"),
+            CommandSpec(command=["go", "build", "."], env_vars={"GOOS": "windows"}, cwd="/src"),
+        )
+    )
+
+    assert str(plan) == "cd /src && GOOS=linux go build .\ncd /src && GOOS=windows go build ."
+    assert not str(Plan())
@@ -0,0 +1,64 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.go.semver import compare_semver, is_prerelease, semver_key, semver_major
+
+
+def test_ordering():
+    versions = [
+        "invalid",
+        "v0.0.0-20240101000000-abcdefabcdef",
+        "v0.1.0",
+        "v1.0.0-alpha",
+        "v1.0.0-alpha.1",
+        "v1.0.0-alpha.beta",
+        "v1.0.0-beta.2",
+        "v1.0.0-beta.11",
+        "v1.0.0-rc.1",
+        "v1.0.0",
+        "v1.2.0",
+        "v1.10.0",
+    ]
+
+    assert sorted(reversed(versions), key=semver_key) == versions
+
+
+def test_build_metadata_ignored():
+    assert compare_semver("v2.0.0+incompatible", "v2.0.0") == 0
+
+
+def test_compare():
+    assert compare_semver("v1.2.3", "v1.2.4") < 0
+    assert compare_semver("v1.3", "v1.2.9") > 0
+
+
+@pytest.mark.parametrize(
+    ("version", "expected"),
+    [
+        ("v1.2.0", False),
+        ("v2.0.0+incompatible", False),
+        ("v1.2.0-rc.1", True),
+        ("v0.0.0-20240101000000-abcdefabcdef", True),
+        ("invalid", False),
+    ],
+)
+def test_is_prerelease(version, expected):
+    assert is_prerelease(version) is expected
+
+
+@pytest.mark.parametrize(
+    ("version", "expected"),
+    [
+        ("v0.1.0", 0),
+        ("v1.2.0", 1),
+        ("v2.0.0+incompatible", 2),
+        ("v10.0.0-rc.1", 10),
+        ("invalid", None),
+    ],
+)
+def test_semver_major(version, expected):
+    assert semver_major(version) == expected
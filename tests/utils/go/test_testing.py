@@ -0,0 +1,121 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import subprocess
+import sys
+
+from dda.utils.go import testing
+
+
+def _spawn(script: str) -> subprocess.Popen[str]:
+    return subprocess.Popen(
+        [sys.executable, "-c", script],
+        stdout=subprocess.PIPE,
+        stderr=subprocess.PIPE,
+        encoding="utf-8",
+    )
+
+
+class TestDecode:
+    def test_event(self):
+        event = testing.decode_test_event(
+            '{"Time":"2024-01-01T00:00:00Z","Action":"pass","Package":"example.com/foo","Test":"TestFoo","Elapsed":0.5}\n'
+        )
+
+        assert event == testing.TestEvent(
+            action=testing.TestAction.PASS,
+            package="example.com/foo",
+            test="TestFoo",
+            time="2024-01-01T00:00:00Z",
+            elapsed=0.5,
+        )
+
+    def test_build_event(self):
+        event = testing.decode_test_event(
+            '{"ImportPath":"example.com/foo [example.com/foo.test]","Action":"build-output","Output":"error\\n"}\n'
+        )
+
+        assert event.action == testing.TestAction.BUILD_OUTPUT
+        assert event.import_path == "example.com/foo [example.com/foo.test]"
+        assert event.output == "error\n"
+
+    def test_not_json(self):
+        for line in ("ok  \texample.com/foo\n", "{not json\n"):
+            assert testing.decode_test_event(line) == testing.TestEvent(action=testing.TestAction.STDOUT, output=line)
+
+
+class TestStream:
+    def test_events(self):
+        script = """
+import sys, time
+sys.stdout.write('{"Action":"run","Package":"p","Test":"TestA"}\\n')
+sys.stdout.flush()
+# Write a single event in multiple chunks to simulate buffering
+sys.stdout.write('{"Action":"pass","Pack')
+sys.stdout.flush()
+time.sleep(0.1)
+sys.stdout.write('age":"p","Test":"TestA","Elapsed":0.1}\\n')
+sys.stdout.flush()
+sys.stderr.write("# p\\nfoo.go:1:1: syntax error\\n")
+sys.stderr.flush()
+sys.exit(2)
+"""
+        stream = testing.TestStream(_spawn(script))
+
+        assert stream.exit_code is None
+
+        events = list(stream)
+        json_events = [event for event in events if event.action != testing.TestAction.STDERR]
+        stderr_events = [event for event in events if event.action == testing.TestAction.STDERR]
+
+        assert json_events == [
+            testing.TestEvent(action="run", package="p", test="TestA"),
+            testing.TestEvent(action="pass", package="p", test="TestA", elapsed=0.1),
+        ]
+        assert [event.output for event in stderr_events] == ["# p\n", "foo.go:1:1: syntax error\n"]
+        assert stream.exit_code == 2
+        assert not stream.passed
+
+    def test_passed(self):
+        stream = testing.TestStream(_spawn('print(\'{"Action":"pass","Package":"p"}\')'))
+
+        assert list(stream) == [testing.TestEvent(action="pass", package="p")]
+        assert stream.passed
+
+    def test_running(self):
+        script = r"""
+for line in (
+    '{"Action":"run","Package":"p","Test":"TestA"}',
+    '{"Action":"run","Package":"p","Test":"TestB"}',
+    '{"Action":"skip","Package":"p","Test":"TestA"}',
+    '{"Action":"run","Package":"q","Test":"TestC"}',
+    '{"Action":"output","Package":"q","Test":"TestC","Output":"panic: test timed out after 5s\\n"}',
+):
+    print(line)
+"""
+        stream = testing.TestStream(_spawn(script))
+        list(stream)
+
+        assert stream.running() == ["TestB", "TestC"]
+        assert stream.running("q") == ["TestC"]
+        assert stream.timed_out == ["q"]
+
+
+class TestRunPattern:
+    def test_empty(self):
+        assert not testing.run_pattern()
+
+    def test_tests(self):
+        assert testing.run_pattern(["TestA"]) == "^TestA$"
+        assert testing.run_pattern(["TestA", "TestB", "TestA"]) == "^(TestA|TestB)$"
+
+    def test_metacharacters(self):
+        assert testing.run_pattern(["TestA.1", "Test(B)*"]) == r"^(TestA\.1|Test\(B\)\*)$"
+
+    def test_subtests(self):
+        assert testing.run_pattern(["TestA"], {"TestB": ["X.1", "a/b"]}) == r"^TestA$|^TestB$/^X\.1$|^TestB$/^a$/^b$"
+
+    def test_subtests_of_selected_test(self):
+        assert testing.run_pattern(["TestA"], {"TestA": ["X"], "TestB": []}) == "^(TestA|TestB)$"
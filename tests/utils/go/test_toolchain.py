@@ -0,0 +1,206 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import subprocess
+import threading
+
+import pytest
+
+from dda.utils.go.toolchain import GoEnv, Toolchain, ToolchainError, find_binary, resolve_binary, split_goflags
+from dda.utils.go.version import Version
+from dda.utils.process import EnvVars
+
+
+@pytest.fixture(name="go_binary")
+def fixt_go_binary(temp_dir):
+    path = temp_dir / "bin" / "go"
+    path.parent.ensure_dir()
+    path.touch()
+    path.chmod(0o755)
+    toolchain = Toolchain(path)
+    toolchain.invalidate()
+    yield path
+    toolchain.invalidate()
+
+
+def _completed(stdout: str, returncode: int = 0) -> subprocess.CompletedProcess:
+    return subprocess.CompletedProcess([], returncode, stdout=stdout, stderr="boom" if returncode else "")
+
+
+class TestToolchain:
+    def test_version(self, mocker, go_binary):
+        run = mocker.patch("subprocess.run", return_value=_completed("go version go1.22.3 linux/amd64\n"))
+
+        assert Toolchain(go_binary).version() == Version(major=1, minor=22, patch=3)
+        assert Toolchain(go_binary).version() == Version(major=1, minor=22, patch=3)
+        assert run.call_count == 1
+        assert run.call_args.args[0] == [str(go_binary.resolve()), "version"]
+        assert run.call_args.kwargs["env"]["GOTOOLCHAIN"] == "local"
+
+    def test_version_devel(self, mocker, go_binary):
+        mocker.patch(
+            "subprocess.run",
+            return_value=_completed("go version devel go1.23-abcdef Tue Jan 1 00:00:00 2024 +0000 linux/amd64\n"),
+        )
+
+        assert Toolchain(go_binary).version() == Version(major=1, minor=23, devel=True)
+
+    def test_env(self, mocker, go_binary):
+        run = mocker.patch("subprocess.run", return_value=_completed('{"GOOS": "linux", "GOARCH": "arm64"}'))
+        toolchain = Toolchain(go_binary)
+
+        assert toolchain.env("GOOS") == "linux"
+        assert toolchain.env("GOARCH") == "arm64"
+        assert not toolchain.env("GOFOO")
+        assert run.call_count == 1
+        assert run.call_args.args[0] == [str(go_binary.resolve()), "env", "-json"]
+
+    def test_environment(self, mocker, go_binary):
+        run = mocker.patch(
+            "subprocess.run",
+            return_value=_completed(
+                '{"GOPATH": "/go", "GOROOT": "/usr/local/go", "GOCACHE": "/cache", "GOMODCACHE": "/go/pkg/mod", '
+                '"GOFLAGS": "-mod=mod", "GOOS": "linux", "GOARCH": "amd64", "CGO_ENABLED": "1", "GOAMD64": "v1"}'
+            ),
+        )
+        toolchain = Toolchain(go_binary)
+
+        env = toolchain.environment()
+        assert env.gopath == "/go"
+        assert env.goroot == "/usr/local/go"
+        assert env.gocache == "/cache"
+        assert env.gomodcache == "/go/pkg/mod"
+        assert env.goflags == "-mod=mod"
+        assert env.goos == "linux"
+        assert env.goarch == "amd64"
+        assert env.cgo_enabled is True
+        assert env.raw["GOAMD64"] == "v1"
+        assert toolchain.env("GOPATH") == "/go"
+        assert run.call_count == 1
+
+    def test_invalidate(self, mocker, go_binary):
+        run = mocker.patch("subprocess.run", return_value=_completed("go version go1.22.3 linux/amd64\n"))
+        toolchain = Toolchain(go_binary)
+
+        toolchain.version()
+        toolchain.invalidate()
+        toolchain.version()
+
+        assert run.call_count == 2
+
+    def test_concurrent(self, mocker, go_binary):
+        run = mocker.patch("subprocess.run", return_value=_completed("go version go1.22.3 linux/amd64\n"))
+        versions = []
+        threads = [
+            threading.Thread(target=lambda: versions.append(Toolchain(go_binary).version())) for _ in range(10)
+        ]
+        for thread in threads:
+            thread.start()
+        for thread in threads:
+            thread.join()
+
+        assert versions == [Version(major=1, minor=22, patch=3)] * 10
+        assert run.call_count == 1
+
+    def test_error(self, mocker, go_binary):
+        mocker.patch("subprocess.run", return_value=_completed("", returncode=1))
+
+        with pytest.raises(ToolchainError, match="Command failed with exit code 1"):
+            Toolchain(go_binary).version()
+
+
+class TestGoEnv:
+    def test_cgo_disabled(self):
+        assert GoEnv.from_dict({"CGO_ENABLED": "0"}).cgo_enabled is False
+
+    def test_missing(self):
+        env = GoEnv.from_dict({})
+
+        assert not env.gopath
+        assert env.cgo_enabled is False
+
+
+class TestFindBinary:
+    def test_explicit(self, go_binary, temp_dir):
+        with EnvVars({"GOROOT": str(temp_dir)}):
+            assert find_binary("/opt/go/bin/go") == "/opt/go/bin/go"
+
+    @pytest.mark.skip_windows
+    def test_goroot(self, go_binary, temp_dir):
+        with EnvVars({"GOROOT": str(temp_dir)}):
+            assert find_binary() == str(go_binary)
+
+    @pytest.mark.skip_windows
+    def test_goroot_over_path(self, mocker, go_binary, temp_dir):
+        mocker.patch("dda.utils.platform.which", return_value="/usr/bin/go")
+
+        with EnvVars({"GOROOT": str(temp_dir)}):
+            assert find_binary() == str(go_binary)
+
+    def test_path(self, mocker, temp_dir):
+        mocker.patch("dda.utils.platform.which", return_value="/usr/bin/go")
+
+        with EnvVars({"GOROOT": str(temp_dir / "missing")}):
+            assert find_binary() == "/usr/bin/go"
+
+    def test_not_found(self, mocker):
+        mocker.patch("dda.utils.platform.which", return_value=None)
+
+        with EnvVars(exclude=["GOROOT"]):
+            assert find_binary() is None
+
+
+@pytest.mark.skip_windows
+class TestResolveBinary:
+    def test_verified(self, mocker, go_binary):
+        run = mocker.patch("subprocess.run", return_value=_completed("go version go1.22.3 linux/amd64\n"))
+
+        assert resolve_binary(go_binary, min_version=Version(major=1, minor=21)) == str(go_binary)
+        assert run.call_count == 1
+
+    def test_missing(self, temp_dir):
+        with pytest.raises(ToolchainError, match="The `go` binary does not exist"):
+            resolve_binary(temp_dir / "go")
+
+    def test_not_executable(self, go_binary):
+        go_binary.chmod(0o644)
+
+        with pytest.raises(ToolchainError, match="The `go` binary is not executable"):
+            resolve_binary(go_binary)
+
+    def test_broken(self, mocker, go_binary):
+        mocker.patch("subprocess.run", return_value=_completed("", returncode=2))
+
+        with pytest.raises(ToolchainError, match="Command failed with exit code 2"):
+            resolve_binary(go_binary)
+
+    def test_too_old(self, mocker, go_binary):
+        mocker.patch("subprocess.run", return_value=_completed("go version go1.20.14 linux/amd64\n"))
+
+        with pytest.raises(ToolchainError, match="go1.21 or later is required but found go1.20.14"):
+            resolve_binary(go_binary, min_version=Version(major=1, minor=21))
+
+    def test_not_found(self, mocker):
+        mocker.patch("dda.utils.platform.which", return_value=None)
+
+        with EnvVars(exclude=["GOROOT"]), pytest.raises(ToolchainError, match="Unable to find the `go` binary"):
+            resolve_binary()
+
+
+class TestSplitGoflags:
+    def test_split(self):
+        assert split_goflags("  -mod=mod\t-tags=a,b  ") == ["-mod=mod", "-tags=a,b"]
+        assert split_goflags("") == []
+
+    def test_quotes(self):
+        assert split_goflags("""'-ldflags=-s -w' "-gcflags=all=-N -l" -v""") == [
+            "-ldflags=-s -w",
+            "-gcflags=all=-N -l",
+            "-v",
+        ]
+
+    def test_unterminated(self):
+        with pytest.raises(ValueError, match="Unterminated ' string in GOFLAGS"):
+            split_goflags("-v '-ldflags=-s")
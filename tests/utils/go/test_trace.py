@@ -0,0 +1,92 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+from dda.utils.go.trace import Action, actions_by_package, parse_build_trace, pgo_profile
+
+TRACE = [
+    "WORK=/tmp/go-build3784075886",
+    "mkdir -p $WORK/b001/",
+    "cat >/tmp/go-build3784075886/b001/importcfg << 'EOF' # internal",
+    "# import config",
+    "packagefile fmt=/root/.cache/go-build/9e/9e9dac3263e08c1b75c5e32a44f61871e360a6cc18adb10046db2489cf320111-d",
+    "EOF",
+    "cd /src/app",
+    (
+        "/usr/local/go/pkg/tool/linux_amd64/compile -o $WORK/b002/_pkg_.a -trimpath \"$WORK/b002=>\" "
+        "-p example.com/app/lib -importcfg $WORK/b002/importcfg -pack ./lib.go"
+    ),
+    (
+        "/usr/local/go/pkg/tool/linux_amd64/compile -o $WORK/b001/_pkg_.a -trimpath \"$WORK/b001=>\" -p main "
+        "-importcfg $WORK/b001/importcfg -pack ./main.go './with space.go'"
+    ),
+    "go tool buildid -w $WORK/b001/_pkg_.a # internal",
+    "cat >/tmp/go-build3784075886/b001/importcfg.link << 'EOF' # internal",
+    "packagefile example.com/app=/tmp/go-build3784075886/b001/_pkg_.a",
+    "EOF",
+    (
+        "GOROOT='/usr/local/go' /usr/local/go/pkg/tool/linux_amd64/link -o $WORK/b001/exe/a.out "
+        "-importcfg $WORK/b001/importcfg.link -buildmode=exe $WORK/b001/_pkg_.a"
+    ),
+    "mv $WORK/b001/exe/a.out app",
+    "# example.com/app",
+    './main.go:3:12: undefined: "foo',
+]
+
+
+def test_parse():
+    actions = parse_build_trace(TRACE)
+
+    assert [(action.tool, action.package) for action in actions] == [
+        ("mkdir", ""),
+        ("compile", "example.com/app/lib"),
+        ("compile", "main"),
+        ("go", "main"),
+        ("link", "main"),
+        ("mv", "main"),
+    ]
+    assert actions[2] == Action(
+        tool="compile",
+        args=(
+            "-o",
+            "$WORK/b001/_pkg_.a",
+            "-trimpath",
+            "$WORK/b001=>",
+            "-p",
+            "main",
+            "-importcfg",
+            "$WORK/b001/importcfg",
+            "-pack",
+            "./main.go",
+            "./with space.go",
+        ),
+        package="main",
+        directory="/src/app",
+    )
+    assert not actions[0].directory
+
+
+def test_windows_tool():
+    actions = parse_build_trace(["C:/Go/pkg/tool/windows_amd64/compile.exe -p=main ./main.go"])
+
+    assert actions == [Action(tool="compile", args=("-p=main", "./main.go"), package="main")]
+
+
+def test_group():
+    groups = actions_by_package(parse_build_trace(TRACE))
+
+    assert list(groups) == ["", "example.com/app/lib", "main"]
+    assert [action.tool for action in groups["main"]] == ["compile", "go", "link", "mv"]
+
+
+def test_pgo_profile():
+    preprofile = "/usr/local/go/pkg/tool/linux_amd64/preprofile -o $WORK/b007/pgo.preprofile -i /src/app/default.pgo"
+    compile_command = (
+        "/usr/local/go/pkg/tool/linux_amd64/compile -o $WORK/b001/_pkg_.a -p main "
+        "-pgoprofile=$WORK/b007/pgo.preprofile ./main.go"
+    )
+
+    assert pgo_profile(parse_build_trace([preprofile, compile_command])) == "/src/app/default.pgo"
+    assert pgo_profile(parse_build_trace([compile_command])) == "$WORK/b007/pgo.preprofile"
+    assert not pgo_profile(parse_build_trace(TRACE))
@@ -0,0 +1,44 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import json
+
+from dda.utils.go.updates import ModuleUpdate, parse_module_updates
+
+OUTPUT = "".join(
+    json.dumps(module, indent="\t") + "\n"
+    for module in (
+        {"Path": "example.com/main", "Main": True},
+        {"Path": "example.com/a", "Version": "v1.0.0", "Update": {"Path": "example.com/a", "Version": "v1.0.1"}},
+        {"Path": "example.com/current", "Version": "v1.0.0"},
+        {"Path": "example.com/indirect", "Version": "v0.1.0", "Update": {"Version": "v0.2.0"}, "Indirect": True},
+        {"Path": "example.com/rc", "Version": "v1.0.0", "Update": {"Version": "v1.1.0-rc.1"}},
+        {"Path": "example.com/beta", "Version": "v2.0.0-beta.1", "Update": {"Version": "v2.0.0-beta.2"}},
+        {"Path": "example.com/pseudo", "Version": "v0.0.0-20240101000000-abcdefabcdef", "Update": {"Version": "v0.1.0"}},
+        {"Path": "example.com/old", "Version": "v2.0.0+incompatible", "Update": {"Version": "v2.1.0+incompatible"}},
+        {"Path": "example.com/newer", "Version": "v1.2.0", "Update": {"Version": "v1.2.0+incompatible"}},
+    )
+)
+
+
+def test_direct():
+    assert parse_module_updates(OUTPUT) == [
+        ModuleUpdate(path="example.com/a", current="v1.0.0", latest="v1.0.1"),
+        ModuleUpdate(path="example.com/beta", current="v2.0.0-beta.1", latest="v2.0.0-beta.2"),
+        ModuleUpdate(path="example.com/pseudo", current="v0.0.0-20240101000000-abcdefabcdef", latest="v0.1.0"),
+        ModuleUpdate(path="example.com/old", current="v2.0.0+incompatible", latest="v2.1.0+incompatible"),
+    ]
+
+
+def test_indirect():
+    updates = parse_module_updates(OUTPUT, indirect=True)
+
+    assert ModuleUpdate(path="example.com/indirect", current="v0.1.0", latest="v0.2.0", indirect=True) in updates
+
+
+def test_prereleases():
+    updates = parse_module_updates(OUTPUT, prereleases=True)
+
+    assert ModuleUpdate(path="example.com/rc", current="v1.0.0", latest="v1.1.0-rc.1") in updates
@@ -0,0 +1,67 @@
+# SPDX-FileCopyrightText: 2026-present Datadog, Inc. <dev@datadoghq.com>
+#
+# SPDX-License-Identifier: MIT
+from __future__ import annotations
+
+import pytest
+
+from dda.utils.go.version import Version, parse_version
+
+
+class TestParse:
+    @pytest.mark.parametrize(
+        ("text", "expected"),
+        [
+            ("go1.21", Version(major=1, minor=21)),
+            ("go1.21.0", Version(major=1, minor=21, patch=0)),
+            ("1.21.3", Version(major=1, minor=21, patch=3)),
+            ("go1.22rc1", Version(major=1, minor=22, prerelease="rc1")),
+            ("go1.22beta2", Version(major=1, minor=22, prerelease="beta2")),
+            ("go1.22.3-bigcorp", Version(major=1, minor=22, patch=3)),
+            ("go1.22.3 X:boringcrypto", Version(major=1, minor=22, patch=3)),
+            ("devel go1.23-abcdef Tue Jan 1 00:00:00 2024 +0000", Version(major=1, minor=23, devel=True)),
+            ("devel +abcdef Tue Jan 1 00:00:00 2024 +0000", Version(devel=True)),
+        ],
+    )
+    def test_valid(self, text, expected):
+        assert parse_version(text) == expected
+
+    @pytest.mark.parametrize("text", ["", "go", "gox.y", "1.21.x", "go1.21rc"])
+    def test_invalid(self, text):
+        with pytest.raises(ValueError, match="Invalid Go version"):
+            parse_version(text)
+
+    @pytest.mark.parametrize("text", ["go1.21", "go1.21.0", "go1.22rc1", "devel go1.23", "devel"])
+    def test_round_trip(self, text):
+        assert str(parse_version(text)) == text
+
+
+class TestCompare:
+    def test_ordering(self):
+        versions = ["go1.20.14", "go1.21", "go1.21rc1", "go1.21rc2", "go1.21.0", "go1.21.1", "devel go1.21.1", "devel"]
+        parsed = [parse_version(v) for v in versions]
+
+        assert sorted(reversed(parsed)) == parsed
+        for older, newer in zip(parsed, parsed[1:], strict=False):
+            assert older.compare(newer) < 0
+            assert newer.compare(older) > 0
+
+    def test_equal(self):
+        assert parse_version("go1.22.3").compare(parse_version("1.22.3-bigcorp")) == 0
+
+    def test_zero_value(self):
+        assert Version() < parse_version("go1.0")
+        assert not Version().at_least(1, 0)
+
+    @pytest.mark.parametrize(
+        ("text", "major", "minor", "expected"),
+        [
+            ("go1.22.3", 1, 22, True),
+            ("go1.22rc1", 1, 22, True),
+            ("go1.21.13", 1, 22, False),
+            ("go1.23", 1, 22, True),
+            ("go2.0", 1, 22, True),
+        ],
+    )
+    def test_at_least(self, text, major, minor, expected):
+        assert parse_version(text).at_least(major, minor) is expected